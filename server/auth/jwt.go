@@ -8,6 +8,7 @@ import (
     "github.com/auth0/go-jwt-middleware"
     "context"
     "log"
+    "LeetTracker/internal/config"
 )
 
 type Jwks struct {
@@ -26,49 +27,82 @@ type JSONWebKeys struct {
 type contextKey string
 const UserIDKey contextKey = "userID"
 
-func NewJWTMiddleware() func(http.Handler) http.Handler {
-    return jwtmiddleware.New(jwtmiddleware.Options{
-        ValidationKeyGetter: func(token *jwt.Token) (interface{}, error) {
-            return GetPemCert(token)
-        },
-        SigningMethod: jwt.SigningMethodRS256,
-        UserProperty: "user",
-        ErrorHandler: func(w http.ResponseWriter, r *http.Request, err string) {
-            log.Printf("JWT Error: %s", err)
-            http.Error(w, err, http.StatusUnauthorized)
-        },
-    }).Handler
+// ErrorHandler writes an authentication failure to the client so the server
+// can plug in its own error envelope.
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, err string)
+
+// Authenticator identifies the caller of a protected route. It returns the
+// caller's user ID, or an error describing why the request was rejected.
+type Authenticator interface {
+    Authenticate(r *http.Request) (string, error)
 }
 
-func UserIDMiddleware(next http.Handler) http.Handler {
-    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-        user := r.Context().Value("user")
-        if user == nil {
-            http.Error(w, "Unauthorized", http.StatusUnauthorized)
-            return
-        }
+// JWTAuthenticator accepts RS256 access tokens issued by the configured Auth0
+// tenant and identifies the caller by the token's subject.
+type JWTAuthenticator struct {
+    jwt *jwtmiddleware.JWTMiddleware
+}
 
-        token := user.(*jwt.Token)
-        claims, ok := token.Claims.(jwt.MapClaims)
-        if !ok {
-            http.Error(w, "Invalid token claims", http.StatusUnauthorized)
-            return
-        }
+func NewJWTAuthenticator(cfg config.Auth) *JWTAuthenticator {
+    jwksURL := cfg.JWKSURL()
+    return &JWTAuthenticator{
+        jwt: jwtmiddleware.New(jwtmiddleware.Options{
+            ValidationKeyGetter: func(token *jwt.Token) (interface{}, error) {
+                return GetPemCert(jwksURL, token)
+            },
+            SigningMethod: jwt.SigningMethodRS256,
+            UserProperty: "user",
+            // Failures are returned from Authenticate and written by Middleware.
+            ErrorHandler: func(w http.ResponseWriter, r *http.Request, err string) {
+                log.Printf("JWT Error: %s", err)
+            },
+        }),
+    }
+}
 
-        sub, ok := claims["sub"].(string)
-        if !ok {
-            http.Error(w, "User ID not found in token", http.StatusUnauthorized)
-            return
-        }
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (string, error) {
+    // CheckJWT stores the parsed token by overwriting the request it is given,
+    // so hand it a copy.
+    checked := *r
+    if err := a.jwt.CheckJWT(nil, &checked); err != nil {
+        return "", err
+    }
 
-        ctx := context.WithValue(r.Context(), UserIDKey, sub)
-        next.ServeHTTP(w, r.WithContext(ctx))
-    })
+    token, ok := checked.Context().Value("user").(*jwt.Token)
+    if !ok {
+        return "", errors.New("Invalid token")
+    }
+    claims, ok := token.Claims.(jwt.MapClaims)
+    if !ok {
+        return "", errors.New("Invalid token claims")
+    }
+    sub, ok := claims["sub"].(string)
+    if !ok {
+        return "", errors.New("User ID not found in token")
+    }
+    return sub, nil
+}
+
+// Middleware rejects requests the authenticator does not accept and stores
+// the caller's ID under UserIDKey for the rest.
+func Middleware(a Authenticator, onError ErrorHandler) func(http.Handler) http.Handler {
+    return func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            userID, err := a.Authenticate(r)
+            if err != nil {
+                onError(w, r, err.Error())
+                return
+            }
+
+            ctx := context.WithValue(r.Context(), UserIDKey, userID)
+            next.ServeHTTP(w, r.WithContext(ctx))
+        })
+    }
 }
 
-func GetPemCert(token *jwt.Token) (interface{}, error) {
+func GetPemCert(jwksURL string, token *jwt.Token) (interface{}, error) {
     cert := ""
-    resp, err := http.Get("https://dev-k44w50mxzfvvi0x3.us.auth0.com/.well-known/jwks.json")
+    resp, err := http.Get(jwksURL)
 
     if err != nil {
         return cert, err
@@ -96,3 +130,20 @@ func GetPemCert(token *jwt.Token) (interface{}, error) {
     result, _ := jwt.ParseRSAPublicKeyFromPEM([]byte(cert))
     return result, nil
 }
+
+// OptionalMiddleware lets requests without credentials through anonymously,
+// leaving UserIDKey unset. Requests that do send credentials must pass the
+// authenticator, exactly as with Middleware.
+func OptionalMiddleware(a Authenticator, onError ErrorHandler) func(http.Handler) http.Handler {
+    required := Middleware(a, onError)
+    return func(next http.Handler) http.Handler {
+        authenticated := required(next)
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            if r.Header.Get("Authorization") == "" {
+                next.ServeHTTP(w, r)
+                return
+            }
+            authenticated.ServeHTTP(w, r)
+        })
+    }
+}
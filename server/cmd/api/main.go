@@ -1,16 +1,62 @@
 package main
 
 import (
+	"LeetTracker/internal/config"
 	"LeetTracker/internal/server"
-	"fmt"
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
-    
-	server := server.NewServer()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv, err := server.NewServer(cfg)
+	if err != nil {
+		log.Fatalf("cannot create server: %v", err)
+	}
 
-	err := server.ListenAndServe()
+	startupCtx, cancel := context.WithTimeout(ctx, cfg.Server.StartupTimeout)
+	err = srv.CheckDependencies(startupCtx)
+	cancel()
 	if err != nil {
-		panic(fmt.Sprintf("cannot start server: %s", err))
+		_ = srv.Close()
+		log.Fatalf("cannot start server: %v", err)
+	}
+
+	srv.StartBackgroundWorkers()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	exitCode := 0
+	select {
+	case err := <-serveErr:
+		if err != nil {
+			log.Printf("server stopped unexpectedly: %v", err)
+			exitCode = 1
+		}
+	case <-ctx.Done():
+		log.Printf("shutdown signal received, draining for up to %s", cfg.Server.ShutdownTimeout)
+	}
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("shutdown incomplete: %v", err)
+		exitCode = 1
 	}
+	log.Println("server stopped")
+	os.Exit(exitCode)
 }
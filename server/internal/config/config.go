@@ -0,0 +1,343 @@
+// Package config loads LeetTracker's runtime settings. Values come from, in
+// increasing order of precedence: built-in defaults, an optional YAML file,
+// a .env file in the working directory, and the process environment.
+package config
+
+import (
+    "errors"
+    "fmt"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/joho/godotenv"
+    "gopkg.in/yaml.v3"
+)
+
+const defaultConfigFile = "config.yaml"
+
+type Config struct {
+    Server   Server   `yaml:"server"`
+    Database Database `yaml:"database"`
+    Cache    Cache    `yaml:"cache"`
+    Redis    Redis    `yaml:"redis"`
+    Auth     Auth     `yaml:"auth"`
+    LeetCode LeetCode `yaml:"leetcode"`
+    Poller   Poller   `yaml:"poller"`
+}
+
+type Server struct {
+    Port           int      `yaml:"port"`
+    AllowedOrigins []string `yaml:"allowed_origins"`
+    // StartupTimeout bounds the dependency checks run before serving.
+    StartupTimeout time.Duration `yaml:"startup_timeout"`
+    // ShutdownTimeout bounds how long in-flight requests may drain before
+    // the database and cache are closed.
+    ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+    // WebURL is where the web app is served; links sent to users, such as
+    // group invites, point there.
+    WebURL string `yaml:"web_url"`
+}
+
+// Database drivers accepted in Database.Driver.
+const (
+    DriverPostgres = "postgres"
+    DriverSQLite   = "sqlite"
+)
+
+type Database struct {
+    // Driver selects the storage backend: "postgres" or "sqlite".
+    Driver   string `yaml:"driver"`
+    Host     string `yaml:"host"`
+    Port     string `yaml:"port"`
+    Name     string `yaml:"name"`
+    Username string `yaml:"username"`
+    Password string `yaml:"password"`
+    Schema   string `yaml:"schema"`
+    // Path is the database file used by the sqlite driver.
+    Path string `yaml:"path"`
+    // QueryTimeout is the default deadline for a single database call.
+    QueryTimeout time.Duration `yaml:"query_timeout"`
+}
+
+// DSN returns the pgx connection string for the configured database.
+func (d Database) DSN() string {
+    return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable&search_path=%s", d.Username, d.Password, d.Host, d.Port, d.Name, d.Schema)
+}
+
+// Cache drivers accepted in Cache.Driver.
+const (
+    CacheRedis  = "redis"
+    CacheMemory = "memory"
+)
+
+type Cache struct {
+    // Driver selects "redis", or "memory" for a per-process cache that needs
+    // no Redis server.
+    Driver string `yaml:"driver"`
+}
+
+type Redis struct {
+    Addr string `yaml:"addr"`
+}
+
+type Auth struct {
+    // Domain is the Auth0 tenant domain, e.g. "example.us.auth0.com".
+    Domain string `yaml:"domain"`
+    // AdminUserIDs are the Auth0 subjects allowed to call /admin endpoints.
+    AdminUserIDs []string `yaml:"admin_user_ids"`
+}
+
+// JWKSURL is where the tenant publishes the keys used to sign access tokens.
+func (a Auth) JWKSURL() string {
+    return fmt.Sprintf("https://%s/.well-known/jwks.json", a.Domain)
+}
+
+type LeetCode struct {
+    ProblemsURL string `yaml:"problems_url"`
+    GraphQLURL  string `yaml:"graphql_url"`
+}
+
+// Poller controls the background job that records progress snapshots for
+// every linked LeetCode username.
+type Poller struct {
+    Enabled bool `yaml:"enabled"`
+    // Interval is the time between polls of the same user.
+    Interval time.Duration `yaml:"interval"`
+    // Jitter is the upper bound of a random delay added to each scheduled run
+    // so that many deployments do not hit LeetCode at the same moment.
+    Jitter time.Duration `yaml:"jitter"`
+    // RequestInterval is the minimum gap between two requests to LeetCode.
+    RequestInterval time.Duration `yaml:"request_interval"`
+    // MaxBackoff caps the wait after repeated LeetCode failures.
+    MaxBackoff time.Duration `yaml:"max_backoff"`
+}
+
+func defaults() *Config {
+    return &Config{
+        Server: Server{
+            Port:            8080,
+            AllowedOrigins:  []string{"http://localhost:5173"},
+            StartupTimeout:  10 * time.Second,
+            ShutdownTimeout: 15 * time.Second,
+            WebURL:          "http://localhost:5173",
+        },
+        Database: Database{
+            Driver:       DriverPostgres,
+            Path:         "leettracker.db",
+            Host:         "localhost",
+            Port:         "5432",
+            Schema:       "public",
+            QueryTimeout: 5 * time.Second,
+        },
+        Cache: Cache{
+            Driver: CacheRedis,
+        },
+        Redis: Redis{
+            Addr: "localhost:6379",
+        },
+        LeetCode: LeetCode{
+            ProblemsURL: "https://leetcode.com/api/problems/all/",
+            GraphQLURL:  "https://leetcode.com/graphql",
+        },
+        Poller: Poller{
+            Enabled:         true,
+            Interval:        24 * time.Hour,
+            Jitter:          time.Hour,
+            RequestInterval: 2 * time.Second,
+            MaxBackoff:      30 * time.Minute,
+        },
+    }
+}
+
+// Load builds the configuration and validates it. The YAML file is read from
+// CONFIG_FILE, or config.yaml in the working directory if that exists.
+func Load() (*Config, error) {
+    if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+        return nil, fmt.Errorf("config: failed to read .env: %v", err)
+    }
+
+    cfg := defaults()
+
+    path, explicit := os.LookupEnv("CONFIG_FILE")
+    if !explicit {
+        path = defaultConfigFile
+    }
+    if err := cfg.loadFile(path, explicit); err != nil {
+        return nil, err
+    }
+
+    if err := cfg.loadEnv(); err != nil {
+        return nil, err
+    }
+
+    if err := cfg.Validate(); err != nil {
+        return nil, err
+    }
+    return cfg, nil
+}
+
+func (c *Config) loadFile(path string, required bool) error {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        if errors.Is(err, os.ErrNotExist) && !required {
+            return nil
+        }
+        return fmt.Errorf("config: failed to read %s: %v", path, err)
+    }
+    if err := yaml.Unmarshal(data, c); err != nil {
+        return fmt.Errorf("config: failed to parse %s: %v", path, err)
+    }
+    return nil
+}
+
+func (c *Config) loadEnv() error {
+    if v := os.Getenv("PORT"); v != "" {
+        port, err := strconv.Atoi(v)
+        if err != nil {
+            return fmt.Errorf("config: PORT must be a number, got %q", v)
+        }
+        c.Server.Port = port
+    }
+    if v := os.Getenv("CORS_ALLOWED_ORIGINS"); v != "" {
+        c.Server.AllowedOrigins = splitList(v)
+    }
+    if err := setDuration(&c.Server.StartupTimeout, "STARTUP_TIMEOUT"); err != nil {
+        return err
+    }
+    if err := setDuration(&c.Server.ShutdownTimeout, "SHUTDOWN_TIMEOUT"); err != nil {
+        return err
+    }
+    setString(&c.Server.WebURL, "WEB_URL")
+
+    setString(&c.Database.Driver, "DB_DRIVER")
+    setString(&c.Database.Path, "DB_PATH")
+    setString(&c.Database.Host, "DB_HOST")
+    setString(&c.Database.Port, "DB_PORT")
+    setString(&c.Database.Name, "DB_DATABASE")
+    setString(&c.Database.Username, "DB_USERNAME")
+    setString(&c.Database.Password, "DB_PASSWORD")
+    setString(&c.Database.Schema, "DB_SCHEMA")
+    if err := setDuration(&c.Database.QueryTimeout, "DB_QUERY_TIMEOUT"); err != nil {
+        return err
+    }
+
+    setString(&c.Cache.Driver, "CACHE_DRIVER")
+    setString(&c.Redis.Addr, "REDIS_ADDR")
+    setString(&c.Auth.Domain, "AUTH0_DOMAIN")
+    if v := os.Getenv("ADMIN_USER_IDS"); v != "" {
+        c.Auth.AdminUserIDs = splitList(v)
+    }
+    setString(&c.LeetCode.ProblemsURL, "LEETCODE_PROBLEMS_URL")
+    setString(&c.LeetCode.GraphQLURL, "LEETCODE_GRAPHQL_URL")
+
+    if v := os.Getenv("POLLER_ENABLED"); v != "" {
+        enabled, err := strconv.ParseBool(v)
+        if err != nil {
+            return fmt.Errorf("config: POLLER_ENABLED must be true or false, got %q", v)
+        }
+        c.Poller.Enabled = enabled
+    }
+    for key, dst := range map[string]*time.Duration{
+        "POLLER_INTERVAL":         &c.Poller.Interval,
+        "POLLER_JITTER":           &c.Poller.Jitter,
+        "POLLER_REQUEST_INTERVAL": &c.Poller.RequestInterval,
+        "POLLER_MAX_BACKOFF":      &c.Poller.MaxBackoff,
+    } {
+        if err := setDuration(dst, key); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// Validate reports every missing or malformed setting at once so a broken
+// deployment can be fixed in one pass.
+func (c *Config) Validate() error {
+    var problems []string
+    if c.Server.Port <= 0 || c.Server.Port > 65535 {
+        problems = append(problems, fmt.Sprintf("server port %d is out of range (PORT)", c.Server.Port))
+    }
+    if c.Server.StartupTimeout <= 0 {
+        problems = append(problems, "startup timeout must be positive (STARTUP_TIMEOUT)")
+    }
+    if c.Server.ShutdownTimeout <= 0 {
+        problems = append(problems, "shutdown timeout must be positive (SHUTDOWN_TIMEOUT)")
+    }
+    if c.Database.QueryTimeout <= 0 {
+        problems = append(problems, "database query timeout must be positive (DB_QUERY_TIMEOUT)")
+    }
+    switch c.Database.Driver {
+    case DriverPostgres:
+        requireValue(&problems, c.Database.Host, "database host (DB_HOST)")
+        requireValue(&problems, c.Database.Port, "database port (DB_PORT)")
+        requireValue(&problems, c.Database.Name, "database name (DB_DATABASE)")
+        requireValue(&problems, c.Database.Username, "database username (DB_USERNAME)")
+    case DriverSQLite:
+        requireValue(&problems, c.Database.Path, "database file (DB_PATH)")
+    default:
+        problems = append(problems, fmt.Sprintf("database driver %q must be %q or %q (DB_DRIVER)", c.Database.Driver, DriverPostgres, DriverSQLite))
+    }
+    switch c.Cache.Driver {
+    case CacheRedis:
+        requireValue(&problems, c.Redis.Addr, "redis address (REDIS_ADDR)")
+    case CacheMemory:
+    default:
+        problems = append(problems, fmt.Sprintf("cache driver %q must be %q or %q (CACHE_DRIVER)", c.Cache.Driver, CacheRedis, CacheMemory))
+    }
+    requireValue(&problems, c.Auth.Domain, "Auth0 domain (AUTH0_DOMAIN)")
+    requireValue(&problems, c.LeetCode.ProblemsURL, "LeetCode problems URL (LEETCODE_PROBLEMS_URL)")
+    requireValue(&problems, c.LeetCode.GraphQLURL, "LeetCode GraphQL URL (LEETCODE_GRAPHQL_URL)")
+    if c.Poller.Enabled {
+        if c.Poller.Interval <= 0 {
+            problems = append(problems, "poller interval must be positive (POLLER_INTERVAL)")
+        }
+        if c.Poller.Jitter < 0 || c.Poller.RequestInterval < 0 {
+            problems = append(problems, "poller jitter and request interval must not be negative (POLLER_JITTER, POLLER_REQUEST_INTERVAL)")
+        }
+        if c.Poller.MaxBackoff < c.Poller.RequestInterval {
+            problems = append(problems, "poller max backoff must be at least the request interval (POLLER_MAX_BACKOFF)")
+        }
+    }
+
+    if len(problems) > 0 {
+        return fmt.Errorf("config: invalid configuration:\n  - %s", strings.Join(problems, "\n  - "))
+    }
+    return nil
+}
+
+func setString(dst *string, key string) {
+    if v, ok := os.LookupEnv(key); ok && v != "" {
+        *dst = v
+    }
+}
+
+func setDuration(dst *time.Duration, key string) error {
+    v := os.Getenv(key)
+    if v == "" {
+        return nil
+    }
+    d, err := time.ParseDuration(v)
+    if err != nil {
+        return fmt.Errorf("config: %s must be a duration such as 15s, got %q", key, v)
+    }
+    *dst = d
+    return nil
+}
+
+func requireValue(problems *[]string, value, name string) {
+    if strings.TrimSpace(value) == "" {
+        *problems = append(*problems, name+" is required")
+    }
+}
+
+func splitList(v string) []string {
+    var out []string
+    for _, part := range strings.Split(v, ",") {
+        if part = strings.TrimSpace(part); part != "" {
+            out = append(out, part)
+        }
+    }
+    return out
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadPrefersEnvironmentOverFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	yaml := `
+server:
+  port: 9090
+database:
+  host: db.internal
+  name: leettracker
+  username: tracker
+auth:
+  domain: file.auth0.com
+`
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("AUTH0_DOMAIN", "env.auth0.com")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://a.example, https://b.example")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if cfg.Server.Port != 9090 {
+		t.Errorf("expected port from file, got %d", cfg.Server.Port)
+	}
+	if cfg.Auth.Domain != "env.auth0.com" {
+		t.Errorf("expected env to override file, got %q", cfg.Auth.Domain)
+	}
+	if len(cfg.Server.AllowedOrigins) != 2 || cfg.Server.AllowedOrigins[1] != "https://b.example" {
+		t.Errorf("unexpected allowed origins %v", cfg.Server.AllowedOrigins)
+	}
+	if cfg.Redis.Addr != "localhost:6379" {
+		t.Errorf("expected default redis address, got %q", cfg.Redis.Addr)
+	}
+	if cfg.Server.WebURL != "http://localhost:5173" {
+		t.Errorf("expected default web URL, got %q", cfg.Server.WebURL)
+	}
+}
+
+func TestValidateListsEveryMissingValue(t *testing.T) {
+	cfg := defaults()
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{"DB_DATABASE", "DB_USERNAME", "AUTH0_DOMAIN"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to mention %s, got %v", want, err)
+		}
+	}
+}
+
+func TestValidateSQLiteNeedsNoPostgresOrRedis(t *testing.T) {
+	cfg := defaults()
+	cfg.Database.Driver = DriverSQLite
+	cfg.Cache.Driver = CacheMemory
+	cfg.Redis.Addr = ""
+	cfg.Auth.Domain = "example.auth0.com"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected sqlite configuration to be valid, got %v", err)
+	}
+
+	cfg.Database.Driver = "mysql"
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "DB_DRIVER") {
+		t.Fatalf("expected unknown driver to be rejected, got %v", err)
+	}
+}
+
+func TestPollerSettings(t *testing.T) {
+	cfg := defaults()
+	t.Setenv("POLLER_INTERVAL", "6h")
+	t.Setenv("POLLER_ENABLED", "yes")
+	if err := cfg.loadEnv(); err == nil || !strings.Contains(err.Error(), "POLLER_ENABLED") {
+		t.Fatalf("expected a POLLER_ENABLED parse error, got %v", err)
+	}
+
+	t.Setenv("POLLER_ENABLED", "true")
+	if err := cfg.loadEnv(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Poller.Interval != 6*time.Hour {
+		t.Errorf("expected interval from env, got %s", cfg.Poller.Interval)
+	}
+
+	cfg.Poller.MaxBackoff = time.Second
+	cfg.Poller.RequestInterval = time.Minute
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "POLLER_MAX_BACKOFF") {
+		t.Fatalf("expected max backoff to be rejected, got %v", err)
+	}
+	cfg.Poller.Enabled = false
+	if err := cfg.Validate(); err != nil && strings.Contains(err.Error(), "POLLER") {
+		t.Fatalf("expected a disabled poller to skip validation, got %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+// Package daily picks a list's problem of the day.
+package daily
+
+import (
+    "fmt"
+    "hash/fnv"
+    "time"
+)
+
+// Pick chooses the problem of the day for listID on date from candidates,
+// skipping those in recent, which maps problems picked within the no-repeat
+// window to the date they were picked. The choice depends only on its
+// arguments, so every server makes the same pick. When every candidate was
+// picked recently, the one picked longest ago comes back first. Pick returns
+// false if there are no candidates.
+func Pick(listID int, date time.Time, candidates []int, recent map[int]time.Time) (int, bool) {
+    var fresh []int
+    for _, id := range candidates {
+        if _, ok := recent[id]; !ok {
+            fresh = append(fresh, id)
+        }
+    }
+    if len(fresh) > 0 {
+        return fresh[seed(listID, date)%uint64(len(fresh))], true
+    }
+
+    oldest, found := 0, false
+    for _, id := range candidates {
+        if !found || recent[id].Before(recent[oldest]) || (recent[id].Equal(recent[oldest]) && id < oldest) {
+            oldest, found = id, true
+        }
+    }
+    return oldest, found
+}
+
+// seed hashes the list and date so each list gets its own sequence of picks.
+func seed(listID int, date time.Time) uint64 {
+    h := fnv.New64a()
+    fmt.Fprintf(h, "%d:%s", listID, date.UTC().Format("2006-01-02"))
+    return h.Sum64()
+}
@@ -0,0 +1,55 @@
+package daily
+
+import (
+	"testing"
+	"time"
+)
+
+var monday = time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+
+func TestPickIsDeterministic(t *testing.T) {
+	candidates := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	first, ok := Pick(1, monday, candidates, nil)
+	if !ok {
+		t.Fatal("expected a pick")
+	}
+	for i := 0; i < 10; i++ {
+		if again, _ := Pick(1, monday.Add(time.Duration(i)*time.Hour), candidates, nil); again != first {
+			t.Fatalf("expected the same pick all day, got %d and %d", first, again)
+		}
+	}
+
+	// Different days and lists should not all land on the same problem.
+	seen := map[int]bool{first: true}
+	for day := 1; day < 14; day++ {
+		pick, _ := Pick(1, monday.AddDate(0, 0, day), candidates, nil)
+		seen[pick] = true
+		pick, _ = Pick(day+1, monday, candidates, nil)
+		seen[pick] = true
+	}
+	if len(seen) < 3 {
+		t.Errorf("expected picks to vary over days and lists, got %v", seen)
+	}
+}
+
+func TestPickSkipsRecentProblems(t *testing.T) {
+	candidates := []int{1, 2, 3}
+	recent := map[int]time.Time{1: monday.AddDate(0, 0, -1), 3: monday.AddDate(0, 0, -2)}
+	for day := 0; day < 7; day++ {
+		if pick, _ := Pick(day, monday, candidates, recent); pick != 2 {
+			t.Fatalf("expected the only fresh problem, got %d", pick)
+		}
+	}
+
+	recent[2] = monday.AddDate(0, 0, -3)
+	recent[1] = monday.AddDate(0, 0, -3)
+	if pick, _ := Pick(1, monday, candidates, recent); pick != 1 {
+		t.Errorf("expected the problem picked longest ago with the lowest ID, got %d", pick)
+	}
+}
+
+func TestPickWithoutCandidates(t *testing.T) {
+	if _, ok := Pick(1, monday, nil, nil); ok {
+		t.Error("expected no pick without candidates")
+	}
+}
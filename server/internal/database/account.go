@@ -0,0 +1,78 @@
+package database
+
+import (
+    "context"
+    "database/sql"
+)
+
+// Progress visibility levels. Private history is only shown to its owner,
+// team history to members of a study group the owner is in and public
+// history to anyone.
+const (
+    VisibilityPrivate = "private"
+    VisibilityTeam    = "team"
+    VisibilityPublic  = "public"
+)
+
+// Visibilities lists every accepted progress visibility.
+var Visibilities = []string{VisibilityPrivate, VisibilityTeam, VisibilityPublic}
+
+// Account holds the settings stored on a users row. LeetCodeUsername is nil
+// until the user links one.
+type Account struct {
+    UserID             string  `json:"-"`
+    LeetCodeUsername   *string `json:"leetcode_username"`
+    ProgressVisibility string  `json:"progress_visibility"`
+    LeetCodePremium    bool    `json:"leetcode_premium"`
+}
+
+func (s *service) GetAccount(ctx context.Context, userID string) (*Account, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    return s.scanAccount(ctx, "id = $1", userID)
+}
+
+// GetAccountByLeetCodeUsername returns the account that linked username.
+func (s *service) GetAccountByLeetCodeUsername(ctx context.Context, username string) (*Account, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    return s.scanAccount(ctx, "leetcode_username = $1", username)
+}
+
+// UpdateAccount stores the account's settings. Linking a LeetCode username
+// another account already claimed returns ErrConflict.
+func (s *service) UpdateAccount(ctx context.Context, account *Account) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    result, err := s.exec(ctx, `
+        UPDATE users SET leetcode_username = $1, progress_visibility = $2, leetcode_premium = $3
+        WHERE id = $4
+    `, account.LeetCodeUsername, account.ProgressVisibility, account.LeetCodePremium, account.UserID)
+    if err != nil {
+        return wrapError(ctx, err, "failed to link LeetCode username")
+    }
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return wrapError(ctx, err, "error checking rows affected")
+    }
+    if rowsAffected == 0 {
+        return notFoundError("user not found")
+    }
+    return nil
+}
+
+func (s *service) scanAccount(ctx context.Context, where string, arg interface{}) (*Account, error) {
+    var account Account
+    err := s.queryRow(ctx, "SELECT id, leetcode_username, progress_visibility, leetcode_premium FROM users WHERE "+where, arg).
+        Scan(&account.UserID, &account.LeetCodeUsername, &account.ProgressVisibility, &account.LeetCodePremium)
+    if err == sql.ErrNoRows {
+        return nil, notFoundError("account not found")
+    }
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to fetch account")
+    }
+    return &account, nil
+}
@@ -0,0 +1,1468 @@
+package database
+
+import (
+	"LeetTracker/internal/utils/leetcode"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// runServiceContract exercises behaviour every Service implementation must
+// share. newService must return an empty, fully migrated store.
+func runServiceContract(t *testing.T, newService func(t *testing.T) Service) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, s Service)
+	}{
+		{"CatalogUpsertAndPagination", contractCatalog},
+		{"ProblemTopics", contractProblemTopics},
+		{"Users", contractUsers},
+		{"ListOwnership", contractListOwnership},
+		{"ListItems", contractListItems},
+		{"CompletionStatus", contractCompletionStatus},
+		{"Progress", contractProgress},
+		{"Feedback", contractFeedback},
+		{"Accounts", contractAccounts},
+		{"PollRuns", contractPollRuns},
+		{"SubmissionImport", contractSubmissionImport},
+		{"Groups", contractGroups},
+		{"ListSharing", contractListSharing},
+		{"Leaderboards", contractLeaderboards},
+		{"StudyPlans", contractStudyPlans},
+		{"Interviews", contractInterviews},
+		{"ProblemActivity", contractProblemActivity},
+		{"DailyProblems", contractDailyProblems},
+		{"CancelledContext", contractCancelledContext},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, newService(t))
+		})
+	}
+}
+
+func contractProblems() []leetcode.Problem {
+	return []leetcode.Problem{
+		{FrontendID: 1, Title: "Two Sum", TitleSlug: "two-sum", Difficulty: "Easy", AcceptanceRate: 50.5, URL: "https://leetcode.com/problems/two-sum/", Topics: []string{"hash-table", "array"}},
+		{FrontendID: 2, Title: "Add Two Numbers", TitleSlug: "add-two-numbers", Difficulty: "Medium", AcceptanceRate: 40.1, URL: "https://leetcode.com/problems/add-two-numbers/", Topics: []string{"linked-list", "math"}},
+		{FrontendID: 4, Title: "Median of Two Sorted Arrays", TitleSlug: "median-of-two-sorted-arrays", Difficulty: "Hard", AcceptanceRate: 38.2, IsPremium: true, URL: "https://leetcode.com/problems/median-of-two-sorted-arrays/", Topics: []string{"array", "binary-search"}},
+	}
+}
+
+func seedCatalog(t *testing.T, s Service) {
+	t.Helper()
+	if err := s.InsertLeetCodeProblems(context.Background(), contractProblems()); err != nil {
+		t.Fatalf("InsertLeetCodeProblems() returned error: %v", err)
+	}
+}
+
+func mustCreateList(t *testing.T, s Service, userID, name string) int {
+	t.Helper()
+	ctx := context.Background()
+	if err := s.EnsureUserExists(ctx, userID); err != nil {
+		t.Fatalf("EnsureUserExists() returned error: %v", err)
+	}
+	id, err := s.CreateList(ctx, userID, &List{Name: name, Difficulty: "easy"})
+	if err != nil {
+		t.Fatalf("CreateList() returned error: %v", err)
+	}
+	return id
+}
+
+func expectKind(t *testing.T, err error, kind error) {
+	t.Helper()
+	if !errors.Is(err, kind) {
+		t.Fatalf("expected error of kind %q, got %v", kind, err)
+	}
+}
+
+func contractCatalog(t *testing.T, s Service) {
+	ctx := context.Background()
+
+	status, err := s.CatalogStatus(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.ProblemCount != 0 || status.LastSync != nil {
+		t.Fatalf("expected empty catalog, got %+v", status)
+	}
+
+	seedCatalog(t, s)
+	updated := contractProblems()[0]
+	updated.Title = "Two Sum (renamed)"
+	// Topics that were not fetched leave the stored ones alone, and an empty
+	// list clears them.
+	updated.Topics = nil
+	cleared := contractProblems()[1]
+	cleared.Topics = []string{}
+	if err := s.InsertLeetCodeProblems(ctx, []leetcode.Problem{updated, cleared}); err != nil {
+		t.Fatal(err)
+	}
+
+	page, total, err := s.GetLeetCodeProblems(ctx, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 3 {
+		t.Fatalf("expected 3 problems in total, got %d", total)
+	}
+	if len(page) != 2 || page[0].FrontendID != 1 || page[1].FrontendID != 2 {
+		t.Fatalf("unexpected first page %+v", page)
+	}
+	if page[0].Title != "Two Sum (renamed)" {
+		t.Fatalf("expected upsert to update title, got %q", page[0].Title)
+	}
+	if page[1].TitleSlug != "add-two-numbers" {
+		t.Fatalf("expected slug to be stored, got %q", page[1].TitleSlug)
+	}
+	if strings.Join(page[0].Topics, ",") != "array,hash-table" || len(page[1].Topics) != 0 {
+		t.Fatalf("expected sorted topics for 1 and none for 2, got %v and %v", page[0].Topics, page[1].Topics)
+	}
+
+	page, _, err = s.GetLeetCodeProblems(ctx, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 1 || page[0].FrontendID != 4 || !page[0].IsPremium {
+		t.Fatalf("unexpected second page %+v", page)
+	}
+
+	status, err = s.CatalogStatus(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.ProblemCount != 3 || status.LastSync == nil {
+		t.Fatalf("expected synced catalog of 3, got %+v", status)
+	}
+}
+
+func contractProblemTopics(t *testing.T, s Service) {
+	ctx := context.Background()
+	seedCatalog(t, s)
+
+	// Problem 4 is left alone and problem 99 is not in the catalog.
+	err := s.SetProblemTopics(ctx, map[int][]string{1: {"two-pointers", "array"}, 2: {}, 99: {"graph"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _, err := s.GetLeetCodeProblems(ctx, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, len(page))
+	for i, p := range page {
+		got[i] = strings.Join(p.Topics, ",")
+	}
+	if want := []string{"array,two-pointers", "", "array,binary-search"}; !slices.Equal(got, want) {
+		t.Fatalf("expected topics %q, got %q", want, got)
+	}
+}
+
+func contractUsers(t *testing.T, s Service) {
+	ctx := context.Background()
+
+	exists, err := s.UserExists(ctx, "auth0|alice")
+	if err != nil || exists {
+		t.Fatalf("expected unknown user, got exists=%v err=%v", exists, err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := s.EnsureUserExists(ctx, "auth0|alice"); err != nil {
+			t.Fatalf("EnsureUserExists() call %d returned error: %v", i+1, err)
+		}
+	}
+	exists, err = s.UserExists(ctx, "auth0|alice")
+	if err != nil || !exists {
+		t.Fatalf("expected user to exist, got exists=%v err=%v", exists, err)
+	}
+
+	_, err = s.CreateList(ctx, "auth0|nobody", &List{Name: "orphan"})
+	expectKind(t, err, ErrValidation)
+}
+
+func contractListOwnership(t *testing.T, s Service) {
+	ctx := context.Background()
+	first := mustCreateList(t, s, "auth0|alice", "Arrays")
+	second := mustCreateList(t, s, "auth0|alice", "Graphs")
+	mustCreateList(t, s, "auth0|bob", "Bob's list")
+
+	list, err := s.GetListByID(ctx, first, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Name != "Arrays" || list.UserID != "auth0|alice" || list.CreatedAt.IsZero() {
+		t.Fatalf("unexpected list %+v", list)
+	}
+
+	_, err = s.GetListByID(ctx, first, "auth0|bob")
+	expectKind(t, err, ErrNotFound)
+
+	lists, err := s.GetUserLists(ctx, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lists) != 2 {
+		t.Fatalf("expected 2 lists, got %d", len(lists))
+	}
+	seen := map[int]bool{lists[0].ID: true, lists[1].ID: true}
+	if !seen[first] || !seen[second] {
+		t.Fatalf("expected lists %d and %d, got %+v", first, second, lists)
+	}
+
+	expectKind(t, s.DeleteList(ctx, first, "auth0|bob"), ErrNotFound)
+	if err := s.DeleteList(ctx, first, "auth0|alice"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.GetListByID(ctx, first, "auth0|alice")
+	expectKind(t, err, ErrNotFound)
+}
+
+func contractListItems(t *testing.T, s Service) {
+	ctx := context.Background()
+	seedCatalog(t, s)
+	listID := mustCreateList(t, s, "auth0|alice", "Mixed")
+
+	if err := s.AddProblemsToList(ctx, listID, []int{2, 1}); err != nil {
+		t.Fatal(err)
+	}
+	// Re-adding is a no-op rather than a conflict.
+	if err := s.AddProblemsToList(ctx, listID, []int{1}); err != nil {
+		t.Fatalf("expected duplicate add to be ignored, got %v", err)
+	}
+	// A missing problem rejects the whole batch.
+	expectKind(t, s.AddProblemsToList(ctx, listID, []int{4, 999}), ErrValidation)
+
+	items, err := s.GetListItems(ctx, listID, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %+v", items)
+	}
+	if items[0].ProblemID != 2 || items[1].ProblemID != 1 {
+		t.Fatalf("expected items in insertion order, got %+v", items)
+	}
+	if items[0].ProblemTitle != "Add Two Numbers" || items[0].ProblemDifficulty != "Medium" || items[0].ListID != listID {
+		t.Fatalf("expected catalog fields to be joined, got %+v", items[0])
+	}
+
+	expectKind(t, s.RemoveProblemFromList(ctx, listID, 4), ErrNotFound)
+	if err := s.RemoveProblemFromList(ctx, listID, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.DeleteList(ctx, listID, "auth0|alice"); err != nil {
+		t.Fatal(err)
+	}
+	items, err = s.GetListItems(ctx, listID, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 0 {
+		t.Fatalf("expected items to be deleted with their list, got %+v", items)
+	}
+}
+
+func contractCompletionStatus(t *testing.T, s Service) {
+	ctx := context.Background()
+	seedCatalog(t, s)
+	listID := mustCreateList(t, s, "auth0|alice", "Mixed")
+	mustCreateList(t, s, "auth0|bob", "Other")
+	if err := s.AddProblemsToList(ctx, listID, []int{1}); err != nil {
+		t.Fatal(err)
+	}
+	items, err := s.GetListItems(ctx, listID, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	itemID := items[0].ID
+
+	expectKind(t, s.UpdateProblemCompletionStatus(ctx, itemID+1000, "auth0|alice", true), ErrNotFound)
+	expectKind(t, s.UpdateProblemCompletionStatus(ctx, itemID, "auth0|bob", true), ErrForbidden)
+
+	if err := s.UpdateProblemCompletionStatus(ctx, itemID, "auth0|alice", true); err != nil {
+		t.Fatal(err)
+	}
+	items, err = s.GetListItems(ctx, listID, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !items[0].Completed || items[0].CompletedAt == nil {
+		t.Fatalf("expected item to be completed with a completion time, got %+v", items[0])
+	}
+	firstCompletion := *items[0].CompletedAt
+
+	// Ticking a completed item again keeps its original completion time.
+	if err := s.UpdateProblemCompletionStatus(ctx, itemID, "auth0|alice", true); err != nil {
+		t.Fatal(err)
+	}
+	items, err = s.GetListItems(ctx, listID, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items[0].CompletedAt == nil || !items[0].CompletedAt.Equal(firstCompletion) {
+		t.Fatalf("expected completion time %v to be kept, got %v", firstCompletion, items[0].CompletedAt)
+	}
+
+	if err := s.UpdateProblemCompletionStatus(ctx, itemID, "auth0|alice", false); err != nil {
+		t.Fatal(err)
+	}
+	items, err = s.GetListItems(ctx, listID, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items[0].Completed || items[0].CompletedAt != nil {
+		t.Fatalf("expected unticking to clear the completion time, got %+v", items[0])
+	}
+}
+
+func progressStats(total, easy, medium, hard int) leetcode.SolvedCounts {
+	return leetcode.SolvedCounts{Total: total, Easy: easy, Medium: medium, Hard: hard}
+}
+
+func contractProgress(t *testing.T, s Service) {
+	ctx := context.Background()
+
+	if err := s.StoreLeetCodeUserProgress(ctx, "alice", progressStats(10, 5, 4, 1)); err != nil {
+		t.Fatal(err)
+	}
+	// A second snapshot on the same day replaces the first.
+	if err := s.StoreLeetCodeUserProgress(ctx, "alice", progressStats(12, 6, 5, 1)); err != nil {
+		t.Fatal(err)
+	}
+	expectKind(t, s.StoreLeetCodeUserProgress(ctx, "alice", progressStats(1, 2, 0, 0)), ErrValidation)
+	expectKind(t, s.StoreLeetCodeUserProgress(ctx, "alice", progressStats(-1, 0, 0, 0)), ErrValidation)
+
+	history, err := s.GetUserProgressHistory(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("expected one entry per day, got %+v", history)
+	}
+	got := history[0]
+	if got.TotalSolved != 12 || got.EasySolved != 6 || got.MediumSolved != 5 || got.HardSolved != 1 {
+		t.Fatalf("unexpected progress entry %+v", got)
+	}
+
+	history, err = s.GetUserProgressHistory(ctx, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 0 {
+		t.Fatalf("expected no history for bob, got %+v", history)
+	}
+}
+
+func contractCancelledContext(t *testing.T, s Service) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.GetUserLists(ctx, "auth0|alice")
+	var canceled *CanceledError
+	if !errors.As(err, &canceled) {
+		t.Fatalf("expected *CanceledError, got %v", err)
+	}
+	if canceled.Timeout() {
+		t.Fatalf("expected cancellation, not a timeout")
+	}
+}
+
+func contractFeedback(t *testing.T, s Service) {
+	ctx := context.Background()
+	seedCatalog(t, s)
+	for _, user := range []string{"auth0|alice", "auth0|bob"} {
+		if err := s.EnsureUserExists(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	likely := 4
+
+	expectKind(t, s.SaveProblemFeedback(ctx, &ProblemFeedback{ProblemID: 999, UserID: "auth0|alice", DifficultyRating: 3}), ErrNotFound)
+
+	first := &ProblemFeedback{ProblemID: 1, UserID: "auth0|alice", DifficultyRating: 1, Comment: "warm-up"}
+	if err := s.SaveProblemFeedback(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	if first.ID == 0 || first.CreatedAt.IsZero() {
+		t.Fatalf("expected stored fields to be filled in, got %+v", first)
+	}
+	// Resubmitting replaces the earlier rating rather than adding a second.
+	updated := &ProblemFeedback{ProblemID: 1, UserID: "auth0|alice", DifficultyRating: 2, InterviewLikelihood: &likely, Comment: "classic"}
+	if err := s.SaveProblemFeedback(ctx, updated); err != nil {
+		t.Fatal(err)
+	}
+	if updated.ID != first.ID {
+		t.Fatalf("expected resubmission to keep id %d, got %d", first.ID, updated.ID)
+	}
+	if err := s.SaveProblemFeedback(ctx, &ProblemFeedback{ProblemID: 1, UserID: "auth0|bob", DifficultyRating: 4}); err != nil {
+		t.Fatal(err)
+	}
+
+	feedback, err := s.GetProblemFeedback(ctx, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feedback) != 2 {
+		t.Fatalf("expected feedback from two users, got %+v", feedback)
+	}
+	limited, err := s.GetProblemFeedback(ctx, 1, 1)
+	if err != nil || len(limited) != 1 {
+		t.Fatalf("expected limit to apply, got %+v (%v)", limited, err)
+	}
+	_, err = s.GetProblemFeedback(ctx, 999, 10)
+	expectKind(t, err, ErrNotFound)
+
+	ratings, err := s.GetCommunityRatings(ctx, []int{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ratings[2]; ok {
+		t.Fatalf("expected unrated problem to be absent, got %+v", ratings[2])
+	}
+	got := ratings[1]
+	if got.Ratings != 2 || got.AvgDifficulty != 3 || got.AvgInterviewLikelihood == nil || *got.AvgInterviewLikelihood != 4 {
+		t.Fatalf("unexpected community rating %+v", got)
+	}
+
+	if err := s.DeleteProblemFeedback(ctx, 1, "auth0|alice"); err != nil {
+		t.Fatal(err)
+	}
+	expectKind(t, s.DeleteProblemFeedback(ctx, 1, "auth0|alice"), ErrNotFound)
+	ratings, err = s.GetCommunityRatings(ctx, []int{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ratings[1].Ratings != 1 || ratings[1].AvgInterviewLikelihood != nil {
+		t.Fatalf("expected only bob's rating to remain, got %+v", ratings[1])
+	}
+}
+
+func contractAccounts(t *testing.T, s Service) {
+	ctx := context.Background()
+	for _, user := range []string{"auth0|alice", "auth0|bob"} {
+		if err := s.EnsureUserExists(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	account, err := s.GetAccount(ctx, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.LeetCodeUsername != nil || account.ProgressVisibility != VisibilityPrivate || account.LeetCodePremium {
+		t.Fatalf("expected an unlinked private account, got %+v", account)
+	}
+	_, err = s.GetAccount(ctx, "auth0|nobody")
+	expectKind(t, err, ErrNotFound)
+
+	username := "alice_lc"
+	account.LeetCodeUsername = &username
+	account.ProgressVisibility = VisibilityPublic
+	account.LeetCodePremium = true
+	if err := s.UpdateAccount(ctx, account); err != nil {
+		t.Fatal(err)
+	}
+	linked, err := s.GetAccountByLeetCodeUsername(ctx, "alice_lc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if linked.UserID != "auth0|alice" || linked.ProgressVisibility != VisibilityPublic || !linked.LeetCodePremium {
+		t.Fatalf("unexpected linked account %+v", linked)
+	}
+	_, err = s.GetAccountByLeetCodeUsername(ctx, "someone_else")
+	expectKind(t, err, ErrNotFound)
+
+	// A LeetCode username can only belong to one account.
+	expectKind(t, s.UpdateAccount(ctx, &Account{UserID: "auth0|bob", LeetCodeUsername: &username, ProgressVisibility: VisibilityPrivate}), ErrConflict)
+	expectKind(t, s.UpdateAccount(ctx, &Account{UserID: "auth0|bob", ProgressVisibility: "friends"}), ErrValidation)
+	expectKind(t, s.UpdateAccount(ctx, &Account{UserID: "auth0|nobody", ProgressVisibility: VisibilityPrivate}), ErrNotFound)
+
+	// Unlinking frees the username.
+	if err := s.UpdateAccount(ctx, &Account{UserID: "auth0|alice", ProgressVisibility: VisibilityTeam}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.GetAccountByLeetCodeUsername(ctx, "alice_lc")
+	expectKind(t, err, ErrNotFound)
+	if err := s.UpdateAccount(ctx, &Account{UserID: "auth0|bob", LeetCodeUsername: &username, ProgressVisibility: VisibilityPrivate}); err != nil {
+		t.Fatalf("expected unlinked username to be claimable, got %v", err)
+	}
+}
+
+func contractPollRuns(t *testing.T, s Service) {
+	ctx := context.Background()
+	for user, username := range map[string]string{"auth0|bob": "bob_lc", "auth0|alice": "alice_lc", "auth0|carol": ""} {
+		if err := s.EnsureUserExists(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+		if username == "" {
+			continue
+		}
+		name := username
+		if err := s.UpdateAccount(ctx, &Account{UserID: user, LeetCodeUsername: &name, ProgressVisibility: VisibilityPrivate}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	usernames, err := s.GetTrackedLeetCodeUsernames(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usernames) != 2 || usernames[0] != "alice_lc" || usernames[1] != "bob_lc" {
+		t.Fatalf("expected linked usernames in order, got %v", usernames)
+	}
+
+	scheduled := &PollRun{Kind: PollScheduled}
+	if err := s.StartPollRun(ctx, scheduled); err != nil {
+		t.Fatal(err)
+	}
+	if scheduled.ID == 0 || scheduled.StartedAt.IsZero() {
+		t.Fatalf("expected stored fields to be filled in, got %+v", scheduled)
+	}
+	scheduled.Polled, scheduled.Failed, scheduled.Error = 2, 1, "bob_lc: rate limited"
+	if err := s.FinishPollRun(ctx, scheduled); err != nil {
+		t.Fatal(err)
+	}
+	if scheduled.FinishedAt == nil {
+		t.Fatal("expected finish time to be filled in")
+	}
+
+	username := "alice_lc"
+	manual := &PollRun{Kind: PollManual, Username: &username}
+	if err := s.StartPollRun(ctx, manual); err != nil {
+		t.Fatal(err)
+	}
+	expectKind(t, s.StartPollRun(ctx, &PollRun{Kind: "hourly"}), ErrValidation)
+	expectKind(t, s.FinishPollRun(ctx, &PollRun{ID: 999}), ErrNotFound)
+
+	runs, err := s.GetPollRuns(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].ID != manual.ID || runs[0].FinishedAt != nil || runs[0].Username == nil || *runs[0].Username != "alice_lc" {
+		t.Fatalf("expected the unfinished manual run first, got %+v", runs)
+	}
+	if got := runs[1]; got.Polled != 2 || got.Failed != 1 || got.Error != "bob_lc: rate limited" || got.FinishedAt == nil || got.Username != nil {
+		t.Fatalf("unexpected scheduled run %+v", got)
+	}
+	limited, err := s.GetPollRuns(ctx, 1)
+	if err != nil || len(limited) != 1 {
+		t.Fatalf("expected limit to apply, got %+v (%v)", limited, err)
+	}
+}
+
+func contractSubmissionImport(t *testing.T, s Service) {
+	ctx := context.Background()
+	seedCatalog(t, s)
+
+	ids, err := s.GetProblemIDsBySlugs(ctx, []string{"two-sum", "median-of-two-sorted-arrays", "not-a-problem"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids["two-sum"] != 1 || ids["median-of-two-sorted-arrays"] != 4 {
+		t.Fatalf("unexpected slug matches %v", ids)
+	}
+
+	arrays := mustCreateList(t, s, "auth0|alice", "Arrays")
+	hard := mustCreateList(t, s, "auth0|alice", "Hard")
+	other := mustCreateList(t, s, "auth0|bob", "Bob's")
+	for _, add := range []struct {
+		listID   int
+		problems []int
+	}{{arrays, []int{1, 2}}, {hard, []int{1, 4}}, {other, []int{1}}} {
+		if err := s.AddProblemsToList(ctx, add.listID, add.problems); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Problem 4 was already ticked by hand and must keep its state.
+	hardItems, err := s.GetListItems(ctx, hard, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateProblemCompletionStatus(ctx, hardItems[1].ID, "auth0|alice", true); err != nil {
+		t.Fatal(err)
+	}
+
+	first := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	solves := []Solve{
+		{ProblemID: 1, SolvedAt: first.Add(time.Hour)},
+		{ProblemID: 1, SolvedAt: first},
+		{ProblemID: 4, SolvedAt: first},
+	}
+
+	preview, err := s.CompleteSolvedItems(ctx, "auth0|alice", solves, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(preview) != 2 || preview[0].ListID != arrays || preview[1].ListID != hard || preview[0].ProblemTitle != "Two Sum" || preview[1].ListName != "Hard" {
+		t.Fatalf("expected problem 1 in both of alice's lists, got %+v", preview)
+	}
+	if !preview[0].CompletedAt.Equal(first) {
+		t.Fatalf("expected the earliest solve time, got %v", preview[0].CompletedAt)
+	}
+	items, err := s.GetListItems(ctx, arrays, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items[0].Completed {
+		t.Fatal("expected a dry run to leave items untouched")
+	}
+
+	applied, err := s.CompleteSolvedItems(ctx, "auth0|alice", solves, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 2 {
+		t.Fatalf("expected the previewed items to change, got %+v", applied)
+	}
+	items, err = s.GetListItems(ctx, arrays, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !items[0].Completed || items[0].CompletedAt == nil || !items[0].CompletedAt.Equal(first) || items[1].Completed {
+		t.Fatalf("expected only Two Sum to be completed at the solve time, got %+v", items)
+	}
+	bobItems, err := s.GetListItems(ctx, other, "auth0|bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bobItems[0].Completed {
+		t.Fatal("expected another user's list to be untouched")
+	}
+
+	again, err := s.CompleteSolvedItems(ctx, "auth0|alice", solves, false)
+	if err != nil || len(again) != 0 {
+		t.Fatalf("expected a repeated import to change nothing, got %+v (%v)", again, err)
+	}
+}
+
+func contractGroups(t *testing.T, s Service) {
+	ctx := context.Background()
+	seedCatalog(t, s)
+	for _, user := range []string{"auth0|alice", "auth0|bob", "auth0|carol"} {
+		if err := s.EnsureUserExists(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	group := &Group{Name: "Graph club", Description: "Weekly graphs", InviteCode: "CODE1"}
+	if err := s.CreateGroup(ctx, "auth0|alice", group); err != nil {
+		t.Fatal(err)
+	}
+	if group.ID == 0 || group.Role != RoleOwner || group.MemberCount != 1 || group.CreatedAt.IsZero() {
+		t.Fatalf("expected generated fields to be filled in, got %+v", group)
+	}
+	expectKind(t, s.CreateGroup(ctx, "auth0|bob", &Group{Name: "Copy", InviteCode: "CODE1"}), ErrConflict)
+	expectKind(t, s.CreateGroup(ctx, "auth0|nobody", &Group{Name: "Orphan", InviteCode: "CODE2"}), ErrValidation)
+
+	_, err := s.GetGroup(ctx, group.ID, "auth0|bob")
+	expectKind(t, err, ErrNotFound)
+	_, err = s.JoinGroup(ctx, "WRONG", "auth0|bob")
+	expectKind(t, err, ErrNotFound)
+
+	for i := 0; i < 2; i++ {
+		joined, err := s.JoinGroup(ctx, "CODE1", "auth0|bob")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if joined.ID != group.ID || joined.Role != RoleMember || joined.MemberCount != 2 {
+			t.Fatalf("expected join %d to leave bob a member of two, got %+v", i+1, joined)
+		}
+	}
+	if _, err := s.JoinGroup(ctx, "CODE1", "auth0|carol"); err != nil {
+		t.Fatal(err)
+	}
+
+	groups, err := s.GetUserGroups(ctx, "auth0|bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 || groups[0].Name != "Graph club" || groups[0].MemberCount != 3 {
+		t.Fatalf("unexpected groups %+v", groups)
+	}
+
+	expectKind(t, s.SetGroupMemberRole(ctx, group.ID, "auth0|alice", RoleMember), ErrForbidden)
+	expectKind(t, s.SetGroupMemberRole(ctx, group.ID, "auth0|bob", RoleOwner), ErrValidation)
+	expectKind(t, s.SetGroupMemberRole(ctx, group.ID, "auth0|nobody", RoleAdmin), ErrNotFound)
+	if err := s.SetGroupMemberRole(ctx, group.ID, "auth0|carol", RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	members, err := s.GetGroupMembers(ctx, group.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 3 || members[0].UserID != "auth0|alice" || members[1].UserID != "auth0|carol" || members[2].Role != RoleMember {
+		t.Fatalf("expected owner, admin, then members, got %+v", members)
+	}
+
+	shared, err := s.SharesGroup(ctx, "auth0|bob", "auth0|alice")
+	if err != nil || !shared {
+		t.Fatalf("expected bob and alice to share a group, got %v (%v)", shared, err)
+	}
+
+	mustCreateList(t, s, "auth0|bob", "Bob's own")
+	listID, err := s.CreateList(ctx, "auth0|alice", &List{GroupID: &group.ID, Name: "Graphs", Difficulty: "medium"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddProblemsToList(ctx, listID, []int{1}); err != nil {
+		t.Fatal(err)
+	}
+	lists, err := s.GetUserLists(ctx, "auth0|bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lists) != 2 || lists[0].GroupID != nil || lists[1].ID != listID || lists[1].GroupID == nil || *lists[1].GroupID != group.ID {
+		t.Fatalf("expected bob's own list before the group list, got %+v", lists)
+	}
+	groupLists, err := s.GetGroupLists(ctx, group.ID)
+	if err != nil || len(groupLists) != 1 || groupLists[0].ID != listID {
+		t.Fatalf("expected the group's list, got %+v (%v)", groupLists, err)
+	}
+	if _, err := s.GetListByID(ctx, listID, "auth0|bob"); err != nil {
+		t.Fatalf("expected a member to see the group list, got %v", err)
+	}
+
+	items, err := s.GetListItems(ctx, listID, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateProblemCompletionStatus(ctx, items[0].ID, "auth0|bob", true); err != nil {
+		t.Fatalf("expected a member to tick group items, got %v", err)
+	}
+	items, err = s.GetListItems(ctx, listID, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items[0].Completed || items[0].DoneCount != 1 || items[0].MemberCount != 3 {
+		t.Fatalf("expected bob's tick to count for him alone, got %+v", items[0])
+	}
+	expectKind(t, s.DeleteList(ctx, listID, "auth0|bob"), ErrNotFound)
+
+	expectKind(t, s.RemoveGroupMember(ctx, group.ID, "auth0|alice"), ErrForbidden)
+	if err := s.RemoveGroupMember(ctx, group.ID, "auth0|bob"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.GetListByID(ctx, listID, "auth0|bob")
+	expectKind(t, err, ErrNotFound)
+	expectKind(t, s.UpdateProblemCompletionStatus(ctx, items[0].ID, "auth0|bob", false), ErrForbidden)
+	shared, err = s.SharesGroup(ctx, "auth0|bob", "auth0|alice")
+	if err != nil || shared {
+		t.Fatalf("expected bob to have left, got %v (%v)", shared, err)
+	}
+
+	expectKind(t, s.SetGroupInviteCode(ctx, group.ID+1000, "CODE3"), ErrNotFound)
+	if err := s.SetGroupInviteCode(ctx, group.ID, "CODE3"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.JoinGroup(ctx, "CODE1", "auth0|bob")
+	expectKind(t, err, ErrNotFound)
+
+	// An admin can delete group lists.
+	if err := s.DeleteList(ctx, listID, "auth0|carol"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateList(ctx, "auth0|alice", &List{GroupID: &group.ID, Name: "Trees", Difficulty: "easy"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteGroup(ctx, group.ID); err != nil {
+		t.Fatal(err)
+	}
+	expectKind(t, s.DeleteGroup(ctx, group.ID), ErrNotFound)
+	lists, err = s.GetUserLists(ctx, "auth0|alice")
+	if err != nil || len(lists) != 0 {
+		t.Fatalf("expected group lists to go with the group, got %+v (%v)", lists, err)
+	}
+}
+
+func contractListSharing(t *testing.T, s Service) {
+	ctx := context.Background()
+	seedCatalog(t, s)
+	for _, user := range []string{"auth0|alice", "auth0|bob", "auth0|carol"} {
+		if err := s.EnsureUserExists(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	listID := mustCreateList(t, s, "auth0|alice", "Shared")
+	own := mustCreateList(t, s, "auth0|bob", "Bob's")
+	if err := s.AddProblemsToList(ctx, listID, []int{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := s.GetListByID(ctx, listID, "auth0|bob")
+	expectKind(t, err, ErrNotFound)
+
+	expectKind(t, s.SetListMember(ctx, listID, "auth0|bob", "owner"), ErrValidation)
+	expectKind(t, s.SetListMember(ctx, listID, "auth0|alice", ListEditor), ErrForbidden)
+	expectKind(t, s.SetListMember(ctx, listID+1000, "auth0|bob", ListEditor), ErrNotFound)
+	expectKind(t, s.SetListMember(ctx, listID, "auth0|nobody", ListEditor), ErrValidation)
+	if err := s.SetListMember(ctx, listID, "auth0|carol", ListEditor); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetListMember(ctx, listID, "auth0|bob", ListEditor); err != nil {
+		t.Fatal(err)
+	}
+	// Setting a role again changes it in place.
+	if err := s.SetListMember(ctx, listID, "auth0|carol", ListViewer); err != nil {
+		t.Fatal(err)
+	}
+	members, err := s.GetListMembers(ctx, listID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roles []string
+	for _, member := range members {
+		roles = append(roles, member.UserID+"="+member.Role)
+	}
+	if strings.Join(roles, ",") != "auth0|alice=owner,auth0|bob=editor,auth0|carol=viewer" {
+		t.Fatalf("expected the owner, then editors, then viewers, got %v", roles)
+	}
+
+	lists, err := s.GetUserLists(ctx, "auth0|bob")
+	if err != nil || len(lists) != 2 || lists[0].ID != own || lists[1].ID != listID {
+		t.Fatalf("expected bob's own list before the shared one, got %+v (%v)", lists, err)
+	}
+	if _, err := s.GetListByID(ctx, listID, "auth0|carol"); err != nil {
+		t.Fatalf("expected a viewer to see the list, got %v", err)
+	}
+
+	// Everyone ticks items off for themselves.
+	items, err := s.GetListItems(ctx, listID, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tick := range []struct {
+		user string
+		item int
+	}{{"auth0|alice", 0}, {"auth0|bob", 0}, {"auth0|carol", 1}} {
+		if err := s.UpdateProblemCompletionStatus(ctx, items[tick.item].ID, tick.user, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	items, err = s.GetListItems(ctx, listID, "auth0|carol")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items[0].Completed || !items[1].Completed || items[1].CompletedAt == nil {
+		t.Fatalf("expected carol's own completion state, got %+v", items)
+	}
+	if items[0].DoneCount != 2 || items[1].DoneCount != 1 || items[0].MemberCount != 3 {
+		t.Fatalf("expected 2/3 and 1/3 members done, got %+v", items)
+	}
+
+	solved, err := s.CompleteSolvedItems(ctx, "auth0|carol", []Solve{{ProblemID: 1, SolvedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}}, false)
+	if err != nil || len(solved) != 1 || solved[0].ListID != listID {
+		t.Fatalf("expected an import to tick carol's state on the shared list, got %+v (%v)", solved, err)
+	}
+
+	// A member who is removed no longer counts, and loses access.
+	expectKind(t, s.RemoveListMember(ctx, listID, "auth0|alice"), ErrNotFound)
+	if err := s.RemoveListMember(ctx, listID, "auth0|bob"); err != nil {
+		t.Fatal(err)
+	}
+	expectKind(t, s.RemoveListMember(ctx, listID, "auth0|bob"), ErrNotFound)
+	items, err = s.GetListItems(ctx, listID, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !items[0].Completed || items[0].DoneCount != 2 || items[0].MemberCount != 2 {
+		t.Fatalf("expected alice and carol to be done with Two Sum, got %+v", items[0])
+	}
+	expectKind(t, s.UpdateProblemCompletionStatus(ctx, items[0].ID, "auth0|bob", false), ErrForbidden)
+	expectKind(t, s.DeleteList(ctx, listID, "auth0|carol"), ErrNotFound)
+
+	if err := s.DeleteList(ctx, listID, "auth0|alice"); err != nil {
+		t.Fatal(err)
+	}
+	members, err = s.GetListMembers(ctx, listID)
+	if err != nil || len(members) != 0 {
+		t.Fatalf("expected members to go with the list, got %+v (%v)", members, err)
+	}
+}
+
+// storeProgressOn records a snapshot dated date, which
+// StoreLeetCodeUserProgress cannot do because it always uses today.
+func storeProgressOn(t *testing.T, s Service, username, date string, total, easy, medium, hard int) {
+	t.Helper()
+	switch svc := s.(type) {
+	case *memoryService:
+		day, err := time.Parse(dateLayout, date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		svc.mu.Lock()
+		defer svc.mu.Unlock()
+		if svc.progress[username] == nil {
+			svc.progress[username] = make(map[string]ProgressEntry)
+		}
+		svc.progress[username][date] = ProgressEntry{Date: day, TotalSolved: total, EasySolved: easy, MediumSolved: medium, HardSolved: hard}
+	case *service:
+		_, err := svc.exec(context.Background(), `
+			INSERT INTO user_progress (username, date, total_solved, easy_solved, medium_solved, hard_solved)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, username, date, total, easy, medium, hard)
+		if err != nil {
+			t.Fatal(err)
+		}
+	default:
+		t.Fatalf("cannot backdate progress for %T", s)
+	}
+}
+
+func contractLeaderboards(t *testing.T, s Service) {
+	ctx := context.Background()
+	accounts := []Account{
+		{UserID: "auth0|alice", ProgressVisibility: VisibilityPrivate},
+		{UserID: "auth0|bob", ProgressVisibility: VisibilityPrivate},
+		{UserID: "auth0|carol", ProgressVisibility: VisibilityTeam},
+		{UserID: "auth0|dave", ProgressVisibility: VisibilityTeam},
+	}
+	for i := range accounts {
+		username := strings.TrimPrefix(accounts[i].UserID, "auth0|") + "_lc"
+		accounts[i].LeetCodeUsername = &username
+		if err := s.EnsureUserExists(ctx, accounts[i].UserID); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.UpdateAccount(ctx, &accounts[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// carol shares a group with alice, so her team progress is visible to
+	// alice; dave's is not.
+	if err := s.CreateGroup(ctx, "auth0|alice", &Group{Name: "Prep", InviteCode: "PREP"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.JoinGroup(ctx, "PREP", "auth0|carol"); err != nil {
+		t.Fatal(err)
+	}
+
+	storeProgressOn(t, s, "alice_lc", "2024-03-01", 10, 5, 4, 1)
+	storeProgressOn(t, s, "alice_lc", "2024-03-05", 14, 6, 6, 2)
+	storeProgressOn(t, s, "alice_lc", "2024-03-12", 20, 8, 8, 4)
+	storeProgressOn(t, s, "carol_lc", "2024-03-03", 5, 5, 0, 0)
+	storeProgressOn(t, s, "carol_lc", "2024-03-04", 8, 6, 1, 1)
+	storeProgressOn(t, s, "stranger", "2024-03-06", 3, 3, 0, 0)
+	storeProgressOn(t, s, "stranger", "2024-03-08", 6, 4, 0, 2)
+	storeProgressOn(t, s, "bob_lc", "2024-03-01", 1, 1, 0, 0)
+	storeProgressOn(t, s, "bob_lc", "2024-03-06", 50, 20, 20, 10)
+
+	board := &Leaderboard{
+		OwnerID:      "auth0|alice",
+		Name:         "March",
+		Weights:      Weights{Easy: 1, Medium: 2, Hard: 4},
+		TieBreak:     TieBreakHardest,
+		Participants: []string{"stranger", "alice_lc", "bob_lc", "carol_lc", "dave_lc"},
+	}
+	if err := s.CreateLeaderboard(ctx, board); err != nil {
+		t.Fatal(err)
+	}
+	expectKind(t, s.CreateLeaderboard(ctx, &Leaderboard{OwnerID: "auth0|nobody", Name: "Orphan", TieBreak: TieBreakShared}), ErrValidation)
+
+	_, err := s.GetLeaderboard(ctx, board.ID, "auth0|bob")
+	expectKind(t, err, ErrNotFound)
+	got, err := s.GetLeaderboard(ctx, board.ID, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "March" || got.Weights.Hard != 4 || len(got.Participants) != 5 || got.Participants[0] != "alice_lc" || got.Version != board.Version {
+		t.Fatalf("unexpected leaderboard %+v", got)
+	}
+
+	tracked, err := s.GetTrackedLeetCodeUsernames(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracked) != 5 || tracked[4] != "stranger" {
+		t.Fatalf("expected linked usernames and leaderboard participants, got %v", tracked)
+	}
+
+	from := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	rank := func(tieBreak string) []Standing {
+		t.Helper()
+		board.TieBreak = tieBreak
+		standings, err := s.RankLeaderboard(ctx, board, from, to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(standings) != 3 {
+			t.Fatalf("expected bob and dave to be hidden, got %+v", standings)
+		}
+		return standings
+	}
+
+	// alice and stranger both score 9; carol's gain on the 4th counts in
+	// full and alice's gain up to the 5th is credited to the 5th.
+	standings := rank(TieBreakHardest)
+	want := []Standing{
+		{Rank: 1, Username: "stranger", Score: 9, EasySolved: 1, HardSolved: 2, TotalSolved: 6},
+		{Rank: 2, Username: "alice_lc", Score: 9, EasySolved: 1, MediumSolved: 2, HardSolved: 1, TotalSolved: 14},
+		{Rank: 3, Username: "carol_lc", Score: 7, EasySolved: 1, MediumSolved: 1, HardSolved: 1, TotalSolved: 8},
+	}
+	for i := range want {
+		if standings[i] != want[i] {
+			t.Fatalf("standing %d: expected %+v, got %+v", i, want[i], standings[i])
+		}
+	}
+	standings = rank(TieBreakTotal)
+	if standings[0].Username != "alice_lc" || standings[1].Rank != 2 {
+		t.Fatalf("expected the larger total to win the tie, got %+v", standings)
+	}
+	standings = rank(TieBreakShared)
+	if standings[0].Rank != 1 || standings[1].Rank != 1 || standings[0].Username != "alice_lc" || standings[2].Rank != 3 {
+		t.Fatalf("expected a shared first place ordered by username, got %+v", standings)
+	}
+	board.TieBreak = "coin-flip"
+	_, err = s.RankLeaderboard(ctx, board, from, to)
+	expectKind(t, err, ErrValidation)
+
+	board.Name = "March madness"
+	board.TieBreak = TieBreakTotal
+	version := board.Version
+	if err := s.UpdateLeaderboard(ctx, board); err != nil {
+		t.Fatal(err)
+	}
+	if board.Version <= version {
+		t.Fatalf("expected the version to move on from %d, got %d", version, board.Version)
+	}
+	expectKind(t, s.UpdateLeaderboard(ctx, &Leaderboard{ID: board.ID, OwnerID: "auth0|bob", Name: "Mine", TieBreak: TieBreakTotal}), ErrNotFound)
+
+	if err := s.AddLeaderboardParticipants(ctx, board.ID, []string{"stranger", "zed"}); err != nil {
+		t.Fatal(err)
+	}
+	expectKind(t, s.RemoveLeaderboardParticipant(ctx, board.ID, "nobody"), ErrNotFound)
+	if err := s.RemoveLeaderboardParticipant(ctx, board.ID, "stranger"); err != nil {
+		t.Fatal(err)
+	}
+	got, err = s.GetLeaderboard(ctx, board.ID, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Participants) != 5 || got.Participants[4] != "zed" || got.Version != board.Version+2 || got.TieBreak != TieBreakTotal {
+		t.Fatalf("unexpected leaderboard after edits %+v", got)
+	}
+	expectKind(t, s.AddLeaderboardParticipants(ctx, board.ID+1000, []string{"zed"}), ErrNotFound)
+
+	boards, err := s.GetUserLeaderboards(ctx, "auth0|alice")
+	if err != nil || len(boards) != 1 || boards[0].Name != "March madness" {
+		t.Fatalf("unexpected leaderboards %+v (%v)", boards, err)
+	}
+	expectKind(t, s.DeleteLeaderboard(ctx, board.ID, "auth0|bob"), ErrNotFound)
+	if err := s.DeleteLeaderboard(ctx, board.ID, "auth0|alice"); err != nil {
+		t.Fatal(err)
+	}
+	boards, err = s.GetUserLeaderboards(ctx, "auth0|alice")
+	if err != nil || len(boards) != 0 {
+		t.Fatalf("expected no leaderboards, got %+v (%v)", boards, err)
+	}
+}
+
+func contractStudyPlans(t *testing.T, s Service) {
+	ctx := context.Background()
+	seedCatalog(t, s)
+	listID := mustCreateList(t, s, "auth0|alice", "Plan me")
+	if err := s.AddProblemsToList(ctx, listID, []int{1, 2, 4}); err != nil {
+		t.Fatal(err)
+	}
+	items, err := s.GetListItems(ctx, listID, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	monday := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	plan := StudyPlan{UserID: "auth0|alice", ListID: listID, StartDate: monday, DaysPerWeek: 3, ProblemsPerDay: 1}
+	expectKind(t, s.CreateStudyPlan(ctx, &StudyPlan{UserID: "auth0|alice", ListID: listID + 1000, StartDate: monday, DaysPerWeek: 3, ProblemsPerDay: 1}, nil), ErrValidation)
+	err = s.CreateStudyPlan(ctx, &plan, []Assignment{
+		{ListItemID: items[0].ID, DueDate: monday},
+		{ListItemID: items[1].ID, DueDate: monday.AddDate(0, 0, 2)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.ID == 0 || plan.CreatedAt.IsZero() {
+		t.Fatalf("expected the generated fields to be filled in, got %+v", plan)
+	}
+
+	got, err := s.GetStudyPlan(ctx, plan.ID, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ListName != "Plan me" || !got.StartDate.Equal(monday) || got.DaysPerWeek != 3 || got.RebalancedAt != nil {
+		t.Fatalf("unexpected plan %+v", got)
+	}
+	_, err = s.GetStudyPlan(ctx, plan.ID, "auth0|bob")
+	expectKind(t, err, ErrNotFound)
+
+	if err := s.UpdateProblemCompletionStatus(ctx, items[1].ID, "auth0|alice", true); err != nil {
+		t.Fatal(err)
+	}
+	schedule, err := s.GetStudyPlanSchedule(ctx, plan.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schedule) != 2 || schedule[0].ProblemTitle != "Two Sum" || schedule[0].Completed || !schedule[1].Completed || !schedule[1].DueDate.Equal(monday.AddDate(0, 0, 2)) {
+		t.Fatalf("expected the schedule in date order with alice's completions, got %+v", schedule)
+	}
+
+	// Rescheduling moves existing items and adds new ones.
+	err = s.RescheduleStudyPlan(ctx, plan.ID, []Assignment{
+		{ListItemID: items[0].ID, DueDate: monday.AddDate(0, 0, 7)},
+		{ListItemID: items[2].ID, DueDate: monday.AddDate(0, 0, 9)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectKind(t, s.RescheduleStudyPlan(ctx, plan.ID+1000, nil), ErrNotFound)
+	schedule, err = s.GetStudyPlanSchedule(ctx, plan.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []int
+	for _, a := range schedule {
+		order = append(order, a.ProblemID)
+	}
+	if len(order) != 3 || order[0] != 2 || order[1] != 1 || order[2] != 4 {
+		t.Fatalf("expected problems 2, 1, 4 after rescheduling, got %v", order)
+	}
+	if got, err := s.GetStudyPlan(ctx, plan.ID, "auth0|alice"); err != nil || got.RebalancedAt == nil {
+		t.Fatalf("expected the rebalance to be recorded, got %+v (%v)", got, err)
+	}
+
+	// Removing a problem from the list drops its assignment.
+	if err := s.RemoveProblemFromList(ctx, listID, 4); err != nil {
+		t.Fatal(err)
+	}
+	if schedule, err = s.GetStudyPlanSchedule(ctx, plan.ID); err != nil || len(schedule) != 2 {
+		t.Fatalf("expected 2 assignments left, got %+v (%v)", schedule, err)
+	}
+
+	// A plan on a list its creator can no longer see is hidden.
+	shared := mustCreateList(t, s, "auth0|bob", "Bob's")
+	if err := s.SetListMember(ctx, shared, "auth0|alice", ListViewer); err != nil {
+		t.Fatal(err)
+	}
+	other := StudyPlan{UserID: "auth0|alice", ListID: shared, StartDate: monday, DaysPerWeek: 7, ProblemsPerDay: 2}
+	if err := s.CreateStudyPlan(ctx, &other, nil); err != nil {
+		t.Fatal(err)
+	}
+	plans, err := s.GetUserStudyPlans(ctx, "auth0|alice")
+	if err != nil || len(plans) != 2 || plans[0].ID != other.ID {
+		t.Fatalf("expected both plans newest first, got %+v (%v)", plans, err)
+	}
+	if err := s.RemoveListMember(ctx, shared, "auth0|alice"); err != nil {
+		t.Fatal(err)
+	}
+	if plans, err = s.GetUserStudyPlans(ctx, "auth0|alice"); err != nil || len(plans) != 1 || plans[0].ID != plan.ID {
+		t.Fatalf("expected only the plan on alice's own list, got %+v (%v)", plans, err)
+	}
+
+	expectKind(t, s.DeleteStudyPlan(ctx, plan.ID, "auth0|bob"), ErrNotFound)
+	if err := s.DeleteStudyPlan(ctx, plan.ID, "auth0|alice"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.GetStudyPlan(ctx, plan.ID, "auth0|alice")
+	expectKind(t, err, ErrNotFound)
+
+	// Deleting the list deletes its plans.
+	if err := s.CreateStudyPlan(ctx, &plan, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteList(ctx, listID, "auth0|alice"); err != nil {
+		t.Fatal(err)
+	}
+	expectKind(t, s.DeleteStudyPlan(ctx, plan.ID, "auth0|alice"), ErrNotFound)
+}
+
+func contractInterviews(t *testing.T, s Service) {
+	ctx := context.Background()
+	seedCatalog(t, s)
+	listID := mustCreateList(t, s, "auth0|alice", "Interview prep")
+	if err := s.AddProblemsToList(ctx, listID, []int{1, 4}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		filter ProblemFilter
+		want   []int
+	}{
+		{"everything", ProblemFilter{}, []int{1, 2, 4}},
+		{"list", ProblemFilter{ListID: &listID}, []int{1, 4}},
+		{"difficulty", ProblemFilter{Difficulties: []string{"easy", "HARD"}}, []int{1, 4}},
+		{"topics", ProblemFilter{Topics: []string{"array", "math"}}, []int{1, 2, 4}},
+		{"topic", ProblemFilter{Topics: []string{"binary-search"}}, []int{4}},
+		{"premium", ProblemFilter{ListID: &listID, ExcludePremium: true}, []int{1}},
+		{"nothing", ProblemFilter{Topics: []string{"graph"}}, []int{}},
+	} {
+		got, err := s.FilterProblems(ctx, tc.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	session := InterviewSession{
+		UserID:           "auth0|alice",
+		ListID:           &listID,
+		TimeLimitMinutes: 45,
+		StartedAt:        start,
+		ExpiresAt:        start.Add(45 * time.Minute),
+		Problems:         []InterviewProblem{{ProblemID: 4}, {ProblemID: 1}},
+	}
+	expectKind(t, s.CreateInterviewSession(ctx, &InterviewSession{UserID: "auth0|alice", TimeLimitMinutes: 45, StartedAt: start, ExpiresAt: start, Problems: []InterviewProblem{{ProblemID: 3}}}), ErrValidation)
+	if err := s.CreateInterviewSession(ctx, &session); err != nil {
+		t.Fatal(err)
+	}
+	if session.ID == 0 {
+		t.Fatal("expected the session ID to be filled in")
+	}
+
+	got, err := s.GetInterviewSession(ctx, session.ID, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got.ListID != listID || !got.StartedAt.Equal(start) || got.EndedAt != nil || len(got.Problems) != 2 {
+		t.Fatalf("unexpected session %+v", got)
+	}
+	if p := got.Problems[0]; p.ProblemID != 4 || p.ProblemTitle != "Median of Two Sorted Arrays" || p.Outcome != nil || got.Problems[1].ProblemID != 1 {
+		t.Fatalf("expected the problems in the order given, got %+v", got.Problems)
+	}
+	_, err = s.GetInterviewSession(ctx, session.ID, "auth0|bob")
+	expectKind(t, err, ErrNotFound)
+
+	recorded := start.Add(10 * time.Minute)
+	if err := s.RecordInterviewOutcome(ctx, session.ID, 1, OutcomePartial, 300, recorded); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RecordInterviewOutcome(ctx, session.ID, 1, OutcomeSolved, 600, recorded); err != nil {
+		t.Fatal(err)
+	}
+	expectKind(t, s.RecordInterviewOutcome(ctx, session.ID, 2, OutcomeSolved, 60, recorded), ErrNotFound)
+	expectKind(t, s.RecordInterviewOutcome(ctx, session.ID, 4, "aced", 60, recorded), ErrValidation)
+	if got, err = s.GetInterviewSession(ctx, session.ID, "auth0|alice"); err != nil {
+		t.Fatal(err)
+	}
+	if p := got.Problems[1]; p.Outcome == nil || *p.Outcome != OutcomeSolved || *p.ElapsedSeconds != 600 || !p.RecordedAt.Equal(recorded) {
+		t.Fatalf("expected the last outcome to stick, got %+v", p)
+	}
+
+	// Problems solved in an interview count as solved.
+	solved, err := s.FilterProblems(ctx, ProblemFilter{ExcludeSolvedBy: "auth0|alice"})
+	if err != nil || !slices.Equal(solved, []int{2, 4}) {
+		t.Fatalf("expected problems 2 and 4 unsolved, got %v (%v)", solved, err)
+	}
+	if solved, err = s.FilterProblems(ctx, ProblemFilter{ExcludeSolvedBy: "auth0|bob"}); err != nil || len(solved) != 3 {
+		t.Fatalf("expected nothing solved by bob, got %v (%v)", solved, err)
+	}
+
+	ended := start.Add(20 * time.Minute)
+	if err := s.EndInterviewSession(ctx, session.ID, ended); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.EndInterviewSession(ctx, session.ID, ended.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	expectKind(t, s.EndInterviewSession(ctx, session.ID+1000, ended), ErrNotFound)
+	if got, err = s.GetInterviewSession(ctx, session.ID, "auth0|alice"); err != nil || got.EndedAt == nil || !got.EndedAt.Equal(ended) {
+		t.Fatalf("expected the first end time to be kept, got %+v (%v)", got, err)
+	}
+
+	later := InterviewSession{UserID: "auth0|alice", TimeLimitMinutes: 30, StartedAt: start.Add(time.Hour), ExpiresAt: start.Add(90 * time.Minute), Problems: []InterviewProblem{{ProblemID: 2}}}
+	if err := s.CreateInterviewSession(ctx, &later); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := s.GetUserInterviewSessions(ctx, "auth0|alice", 10)
+	if err != nil || len(sessions) != 2 || sessions[0].ID != later.ID || sessions[0].ListID != nil {
+		t.Fatalf("expected both sessions newest first, got %+v (%v)", sessions, err)
+	}
+	if sessions, err = s.GetUserInterviewSessions(ctx, "auth0|alice", 1); err != nil || len(sessions) != 1 || sessions[0].ID != later.ID {
+		t.Fatalf("expected only the newest session, got %+v (%v)", sessions, err)
+	}
+
+	// Deleting the list keeps the sessions picked from it.
+	if err := s.DeleteList(ctx, listID, "auth0|alice"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err = s.GetInterviewSession(ctx, session.ID, "auth0|alice"); err != nil || got.ListID != nil {
+		t.Fatalf("expected the session without its list, got %+v (%v)", got, err)
+	}
+}
+
+func contractProblemActivity(t *testing.T, s Service) {
+	ctx := context.Background()
+	seedCatalog(t, s)
+	own := mustCreateList(t, s, "auth0|alice", "Mine")
+	shared := mustCreateList(t, s, "auth0|bob", "Bob's")
+	if err := s.AddProblemsToList(ctx, own, []int{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddProblemsToList(ctx, shared, []int{4}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetListMember(ctx, shared, "auth0|alice", ListViewer); err != nil {
+		t.Fatal(err)
+	}
+	ownItems, err := s.GetListItems(ctx, own, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sharedItems, err := s.GetListItems(ctx, shared, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, itemID := range []int{ownItems[0].ID, sharedItems[0].ID} {
+		if err := s.UpdateProblemCompletionStatus(ctx, itemID, "auth0|alice", true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Completions outlive access to the list they were made on.
+	if err := s.RemoveListMember(ctx, shared, "auth0|alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	session := InterviewSession{UserID: "auth0|alice", TimeLimitMinutes: 45, StartedAt: start, ExpiresAt: start.Add(45 * time.Minute),
+		Problems: []InterviewProblem{{ProblemID: 2}, {ProblemID: 4}}}
+	if err := s.CreateInterviewSession(ctx, &session); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []struct {
+		problemID int
+		outcome   string
+		at        time.Duration
+	}{{2, OutcomeFailed, 10 * time.Minute}, {2, OutcomeSolved, 20 * time.Minute}, {4, OutcomeSkipped, 30 * time.Minute}} {
+		if err := s.RecordInterviewOutcome(ctx, session.ID, r.problemID, r.outcome, 60, start.Add(r.at)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	activity, err := s.GetProblemActivity(ctx, "auth0|alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(activity) != 3 {
+		t.Fatalf("expected activity for problems 1, 2 and 4, got %+v", activity)
+	}
+	if a := activity[0]; a.ProblemID != 1 || !a.OnList || a.CompletedAt == nil || a.Outcome != nil {
+		t.Fatalf("expected problem 1 completed on alice's list, got %+v", a)
+	}
+	if a := activity[1]; a.ProblemID != 2 || !a.OnList || a.CompletedAt != nil || a.Outcome == nil || *a.Outcome != OutcomeSolved || !a.OutcomeAt.Equal(start.Add(20*time.Minute)) {
+		t.Fatalf("expected problem 2 solved in the interview, got %+v", a)
+	}
+	if a := activity[2]; a.ProblemID != 4 || a.OnList || a.CompletedAt == nil || *a.Outcome != OutcomeSkipped {
+		t.Fatalf("expected problem 4 completed on a list alice lost, got %+v", a)
+	}
+
+	activity, err = s.GetProblemActivity(ctx, "auth0|bob")
+	if err != nil || len(activity) != 1 || activity[0].ProblemID != 4 || !activity[0].OnList || activity[0].CompletedAt != nil {
+		t.Fatalf("expected only problem 4 on bob's list, got %+v (%v)", activity, err)
+	}
+}
+
+func contractDailyProblems(t *testing.T, s Service) {
+	ctx := context.Background()
+	seedCatalog(t, s)
+	listID := mustCreateList(t, s, "auth0|alice", "Warm-ups")
+	if err := s.EnsureUserExists(ctx, "auth0|bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	settings := DailySettings{ListID: listID, Source: DailySourceCatalog, Difficulties: []string{"easy", "medium"}, Topics: []string{"array"}, ExcludePremium: true, NoRepeatDays: 7}
+	if err := s.SetDailySettings(ctx, &settings); err != nil {
+		t.Fatal(err)
+	}
+	if settings.UpdatedAt.IsZero() {
+		t.Fatal("expected UpdatedAt to be filled in")
+	}
+	got, err := s.GetDailySettings(ctx, listID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Source != DailySourceCatalog || !slices.Equal(got.Difficulties, []string{"easy", "medium"}) || !slices.Equal(got.Topics, []string{"array"}) || !got.ExcludePremium || got.NoRepeatDays != 7 {
+		t.Fatalf("unexpected settings %+v", got)
+	}
+	ids, err := s.FilterProblems(ctx, got.Filter())
+	if err != nil || !slices.Equal(ids, []int{1}) {
+		t.Fatalf("expected the settings to select problem 1, got %v (%v)", ids, err)
+	}
+
+	// Saving again replaces the settings.
+	settings = DailySettings{ListID: listID, Source: DailySourceList, NoRepeatDays: 0}
+	if err := s.SetDailySettings(ctx, &settings); err != nil {
+		t.Fatal(err)
+	}
+	if got, err = s.GetDailySettings(ctx, listID); err != nil || got.Source != DailySourceList || len(got.Difficulties) != 0 || got.Difficulties == nil || got.ExcludePremium {
+		t.Fatalf("expected the settings to be replaced, got %+v (%v)", got, err)
+	}
+	if filter := got.Filter(); filter.ListID == nil || *filter.ListID != listID {
+		t.Fatalf("expected the filter to select the list's items, got %+v", filter)
+	}
+	expectKind(t, s.SetDailySettings(ctx, &DailySettings{ListID: listID, Source: "feed"}), ErrValidation)
+	expectKind(t, s.SetDailySettings(ctx, &DailySettings{ListID: listID + 1000, Source: DailySourceList}), ErrValidation)
+	_, err = s.GetDailySettings(ctx, listID+1000)
+	expectKind(t, err, ErrNotFound)
+
+	monday := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	if err := s.PickDailyProblem(ctx, listID, monday, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PickDailyProblem(ctx, listID, monday, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PickDailyProblem(ctx, listID, monday.AddDate(0, 0, 1), 4); err != nil {
+		t.Fatal(err)
+	}
+	expectKind(t, s.PickDailyProblem(ctx, listID, monday.AddDate(0, 0, 2), 3), ErrValidation)
+
+	picks, err := s.GetDailyProblems(ctx, listID, "auth0|alice", monday, monday.AddDate(0, 0, 6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(picks) != 2 || picks[0].ProblemID != 4 || !picks[0].Date.Equal(monday.AddDate(0, 0, 1)) || picks[1].ProblemID != 1 || picks[1].ProblemTitle != "Two Sum" || picks[1].Done {
+		t.Fatalf("expected the first pick of each day newest first, got %+v", picks)
+	}
+	if picks, err = s.GetDailyProblems(ctx, listID, "auth0|alice", monday, monday); err != nil || len(picks) != 1 {
+		t.Fatalf("expected one pick on monday, got %+v (%v)", picks, err)
+	}
+
+	doneAt := monday.Add(9 * time.Hour)
+	if err := s.SetDailyProblemDone(ctx, listID, monday, "auth0|alice", true, doneAt); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetDailyProblemDone(ctx, listID, monday, "auth0|alice", true, doneAt.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	expectKind(t, s.SetDailyProblemDone(ctx, listID, monday.AddDate(0, 0, 2), "auth0|alice", true, doneAt), ErrNotFound)
+	picks, err = s.GetDailyProblems(ctx, listID, "auth0|alice", monday, monday)
+	if err != nil || !picks[0].Done || !picks[0].DoneAt.Equal(doneAt) {
+		t.Fatalf("expected alice to have done monday's pick first at 9:00, got %+v (%v)", picks, err)
+	}
+	if picks, err = s.GetDailyProblems(ctx, listID, "auth0|bob", monday, monday); err != nil || picks[0].Done {
+		t.Fatalf("expected done to be per user, got %+v (%v)", picks, err)
+	}
+	if err := s.SetDailyProblemDone(ctx, listID, monday, "auth0|alice", false, doneAt); err != nil {
+		t.Fatal(err)
+	}
+	if picks, err = s.GetDailyProblems(ctx, listID, "auth0|alice", monday, monday); err != nil || picks[0].Done || picks[0].DoneAt != nil {
+		t.Fatalf("expected the pick to be undone, got %+v (%v)", picks, err)
+	}
+
+	// Turning the problem of the day off keeps its history.
+	if err := s.DeleteDailySettings(ctx, listID); err != nil {
+		t.Fatal(err)
+	}
+	expectKind(t, s.DeleteDailySettings(ctx, listID), ErrNotFound)
+	if picks, err = s.GetDailyProblems(ctx, listID, "auth0|alice", monday, monday.AddDate(0, 0, 6)); err != nil || len(picks) != 2 {
+		t.Fatalf("expected the picks to be kept, got %+v (%v)", picks, err)
+	}
+
+	if err := s.DeleteList(ctx, listID, "auth0|alice"); err != nil {
+		t.Fatal(err)
+	}
+	if picks, err = s.GetDailyProblems(ctx, listID, "auth0|alice", monday, monday.AddDate(0, 0, 6)); err != nil || len(picks) != 0 {
+		t.Fatalf("expected the picks to go with the list, got %+v (%v)", picks, err)
+	}
+}
@@ -0,0 +1,200 @@
+package database
+
+import (
+    "context"
+    "database/sql"
+    "strings"
+    "time"
+)
+
+// Where a list's problem of the day is drawn from.
+const (
+    DailySourceList    = "list"
+    DailySourceCatalog = "catalog"
+)
+
+// DailySources lists every accepted problem of the day source.
+var DailySources = []string{DailySourceList, DailySourceCatalog}
+
+// DailySettings configures a list's problem of the day. Picks come from the
+// list's items or from the whole catalog, narrowed by the filters either way.
+type DailySettings struct {
+    ListID         int       `json:"list_id"`
+    Source         string    `json:"source"`
+    Difficulties   []string  `json:"difficulties"`
+    Topics         []string  `json:"topics"`
+    ExcludePremium bool      `json:"exclude_premium"`
+    // NoRepeatDays is how many days must pass before a problem is picked
+    // again.
+    NoRepeatDays   int       `json:"no_repeat_days"`
+    UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Filter returns the problem filter that selects the candidates for a pick.
+func (d DailySettings) Filter() ProblemFilter {
+    filter := ProblemFilter{Difficulties: d.Difficulties, Topics: d.Topics, ExcludePremium: d.ExcludePremium}
+    if d.Source == DailySourceList {
+        listID := d.ListID
+        filter.ListID = &listID
+    }
+    return filter
+}
+
+// DailyProblem is a list's pick for one date. Done and DoneAt are the
+// requesting user's own.
+type DailyProblem struct {
+    Date              time.Time  `json:"date"`
+    ProblemID         int        `json:"problem_id"`
+    ProblemTitle      string     `json:"problem_title"`
+    ProblemDifficulty string     `json:"problem_difficulty"`
+    URL               string     `json:"url"`
+    Done              bool       `json:"done"`
+    DoneAt            *time.Time `json:"done_at"`
+}
+
+// SetDailySettings creates or replaces the problem of the day settings of
+// settings.ListID and fills in UpdatedAt.
+func (s *service) SetDailySettings(ctx context.Context, settings *DailySettings) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    err := s.queryRow(ctx, `
+        INSERT INTO daily_problem_settings (list_id, source, difficulties, topics, exclude_premium, no_repeat_days)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (list_id) DO UPDATE SET
+            source = EXCLUDED.source,
+            difficulties = EXCLUDED.difficulties,
+            topics = EXCLUDED.topics,
+            exclude_premium = EXCLUDED.exclude_premium,
+            no_repeat_days = EXCLUDED.no_repeat_days,
+            updated_at = CURRENT_TIMESTAMP
+        RETURNING updated_at
+    `, settings.ListID, settings.Source, strings.Join(settings.Difficulties, ","), strings.Join(settings.Topics, ","),
+        settings.ExcludePremium, settings.NoRepeatDays).Scan(&settings.UpdatedAt)
+    if err != nil {
+        return wrapError(ctx, err, "failed to save problem of the day settings")
+    }
+    return nil
+}
+
+func (s *service) GetDailySettings(ctx context.Context, listID int) (*DailySettings, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    settings := DailySettings{ListID: listID}
+    var difficulties, topics string
+    err := s.queryRow(ctx, `
+        SELECT source, difficulties, topics, exclude_premium, no_repeat_days, updated_at
+        FROM daily_problem_settings WHERE list_id = $1
+    `, listID).Scan(&settings.Source, &difficulties, &topics, &settings.ExcludePremium, &settings.NoRepeatDays, &settings.UpdatedAt)
+    if err == sql.ErrNoRows {
+        return nil, notFoundError("list %d has no problem of the day", listID)
+    }
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to fetch problem of the day settings")
+    }
+    settings.Difficulties = splitSetting(difficulties)
+    settings.Topics = splitSetting(topics)
+    return &settings, nil
+}
+
+// splitSetting splits a comma separated settings column, which is empty for
+// no values.
+func splitSetting(value string) []string {
+    if value == "" {
+        return []string{}
+    }
+    return strings.Split(value, ",")
+}
+
+// DeleteDailySettings turns off listID's problem of the day. Past picks and
+// who did them are kept.
+func (s *service) DeleteDailySettings(ctx context.Context, listID int) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    result, err := s.exec(ctx, "DELETE FROM daily_problem_settings WHERE list_id = $1", listID)
+    if err != nil {
+        return wrapError(ctx, err, "failed to delete problem of the day settings")
+    }
+    return expectRow(ctx, result, "list %d has no problem of the day", listID)
+}
+
+// PickDailyProblem stores problemID as listID's pick for date, unless the
+// date already has one.
+func (s *service) PickDailyProblem(ctx context.Context, listID int, date time.Time, problemID int) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    _, err := s.exec(ctx, `
+        INSERT INTO daily_problems (list_id, pick_date, problem_id) VALUES ($1, $2, $3)
+        ON CONFLICT (list_id, pick_date) DO NOTHING
+    `, listID, date.Format(dateLayout), problemID)
+    if err != nil {
+        return wrapError(ctx, err, "failed to pick problem of the day")
+    }
+    return nil
+}
+
+// GetDailyProblems returns listID's picks from from to to, both inclusive,
+// newest first, with userID's done state.
+func (s *service) GetDailyProblems(ctx context.Context, listID int, userID string, from, to time.Time) ([]DailyProblem, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    rows, err := s.query(ctx, `
+        SELECT d.pick_date, d.problem_id, lp.title, lp.difficulty, lp.url, c.completed_at
+        FROM daily_problems d
+        JOIN leetcode_problems lp ON lp.frontend_id = d.problem_id
+        LEFT JOIN daily_problem_completions c ON c.list_id = d.list_id AND c.pick_date = d.pick_date AND c.user_id = $2
+        WHERE d.list_id = $1 AND d.pick_date BETWEEN $3 AND $4
+        ORDER BY d.pick_date DESC
+    `, listID, userID, from.Format(dateLayout), to.Format(dateLayout))
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to fetch problems of the day")
+    }
+    defer rows.Close()
+
+    picks := []DailyProblem{}
+    for rows.Next() {
+        var pick DailyProblem
+        if err := rows.Scan(&pick.Date, &pick.ProblemID, &pick.ProblemTitle, &pick.ProblemDifficulty, &pick.URL, &pick.DoneAt); err != nil {
+            return nil, wrapError(ctx, err, "failed to scan problem of the day")
+        }
+        pick.Done = pick.DoneAt != nil
+        picks = append(picks, pick)
+    }
+    if err = rows.Err(); err != nil {
+        return nil, wrapError(ctx, err, "error iterating over problems of the day")
+    }
+    return picks, nil
+}
+
+// SetDailyProblemDone records whether userID has done listID's pick for
+// date. Marking a pick done again keeps the first time.
+func (s *service) SetDailyProblemDone(ctx context.Context, listID int, date time.Time, userID string, done bool, at time.Time) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    var exists bool
+    err := s.queryRow(ctx, "SELECT EXISTS(SELECT 1 FROM daily_problems WHERE list_id = $1 AND pick_date = $2)", listID, date.Format(dateLayout)).Scan(&exists)
+    if err != nil {
+        return wrapError(ctx, err, "failed to fetch problem of the day")
+    }
+    if !exists {
+        return notFoundError("list %d has no problem of the day for %s", listID, date.Format(dateLayout))
+    }
+
+    if done {
+        _, err = s.exec(ctx, `
+            INSERT INTO daily_problem_completions (list_id, pick_date, user_id, completed_at) VALUES ($1, $2, $3, $4)
+            ON CONFLICT (list_id, pick_date, user_id) DO NOTHING
+        `, listID, date.Format(dateLayout), userID, at)
+    } else {
+        _, err = s.exec(ctx, "DELETE FROM daily_problem_completions WHERE list_id = $1 AND pick_date = $2 AND user_id = $3", listID, date.Format(dateLayout), userID)
+    }
+    if err != nil {
+        return wrapError(ctx, err, "failed to update problem of the day")
+    }
+    return nil
+}
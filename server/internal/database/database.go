@@ -5,45 +5,114 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"strconv"
 	"time"
     "strings"
     "sync"
+    "LeetTracker/internal/config"
     "LeetTracker/internal/utils/leetcode"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
-	_ "github.com/joho/godotenv/autoload"
 )
 
 // Service represents a service that interacts with a database.
 type Service interface {
-	Health() map[string]string
+	PoolStats() map[string]string
+	Ping(ctx context.Context) error
+	SchemaVersion(ctx context.Context) (int, error)
+	CatalogStatus(ctx context.Context) (CatalogStatus, error)
 	Close() error
 
-    InsertLeetCodeProblems(problems []leetcode.Problem) error
-    GetListByID(listID int, userID string) (*List, error)
-    GetListItems(listID int) ([]ListItem, error)
-    CreateList(userID string, list *List) (int, error)
-    GetUserLists(userID string) ([]List, error)
-    EnsureUserExists(userID string) error
-    UserExists(userID string) (bool, error)
-    GetLeetCodeProblems(page, pageSize int) ([]leetcode.Problem, int, error)
-    AddProblemsToList(listID int, problemIDs []int) error
-    DeleteList(listID int, userID string) error
-    RemoveProblemFromList(listID int, problemID int) error
-    UpdateProblemCompletionStatus(listItemID int, completed bool) error
-    StoreLeetCodeUserProgress(username string, stats map[string]interface{}) error
-    GetUserProgressHistory(username string) ([]ProgressEntry, error)
+    InsertLeetCodeProblems(ctx context.Context, problems []leetcode.Problem) error
+    SetProblemTopics(ctx context.Context, topics map[int][]string) error
+    GetListByID(ctx context.Context, listID int, userID string) (*List, error)
+    GetListItems(ctx context.Context, listID int, userID string) ([]ListItem, error)
+    CreateList(ctx context.Context, userID string, list *List) (int, error)
+    GetUserLists(ctx context.Context, userID string) ([]List, error)
+    EnsureUserExists(ctx context.Context, userID string) error
+    UserExists(ctx context.Context, userID string) (bool, error)
+    GetLeetCodeProblems(ctx context.Context, page, pageSize int) ([]leetcode.Problem, int, error)
+    AddProblemsToList(ctx context.Context, listID int, problemIDs []int) error
+    DeleteList(ctx context.Context, listID int, userID string) error
+    RemoveProblemFromList(ctx context.Context, listID int, problemID int) error
+    UpdateProblemCompletionStatus(ctx context.Context, listItemID int, userID string, completed bool) error
+    GetListMembers(ctx context.Context, listID int) ([]ListMember, error)
+    SetListMember(ctx context.Context, listID int, userID, role string) error
+    RemoveListMember(ctx context.Context, listID int, userID string) error
+    GetProblemIDsBySlugs(ctx context.Context, slugs []string) (map[string]int, error)
+    CompleteSolvedItems(ctx context.Context, userID string, solves []Solve, dryRun bool) ([]SolvedItem, error)
+    StoreLeetCodeUserProgress(ctx context.Context, username string, solved leetcode.SolvedCounts) error
+    GetUserProgressHistory(ctx context.Context, username string) ([]ProgressEntry, error)
+
+    CreateGroup(ctx context.Context, ownerID string, group *Group) error
+    GetGroup(ctx context.Context, groupID int, userID string) (*Group, error)
+    GetUserGroups(ctx context.Context, userID string) ([]Group, error)
+    JoinGroup(ctx context.Context, inviteCode, userID string) (*Group, error)
+    SetGroupInviteCode(ctx context.Context, groupID int, inviteCode string) error
+    DeleteGroup(ctx context.Context, groupID int) error
+    GetGroupMembers(ctx context.Context, groupID int) ([]GroupMember, error)
+    SetGroupMemberRole(ctx context.Context, groupID int, userID, role string) error
+    RemoveGroupMember(ctx context.Context, groupID int, userID string) error
+    GetGroupLists(ctx context.Context, groupID int) ([]List, error)
+    SharesGroup(ctx context.Context, userID, otherID string) (bool, error)
+
+    CreateLeaderboard(ctx context.Context, board *Leaderboard) error
+    GetLeaderboard(ctx context.Context, boardID int, ownerID string) (*Leaderboard, error)
+    GetUserLeaderboards(ctx context.Context, ownerID string) ([]Leaderboard, error)
+    UpdateLeaderboard(ctx context.Context, board *Leaderboard) error
+    DeleteLeaderboard(ctx context.Context, boardID int, ownerID string) error
+    AddLeaderboardParticipants(ctx context.Context, boardID int, usernames []string) error
+    RemoveLeaderboardParticipant(ctx context.Context, boardID int, username string) error
+    RankLeaderboard(ctx context.Context, board *Leaderboard, from, to time.Time) ([]Standing, error)
+
+    CreateStudyPlan(ctx context.Context, plan *StudyPlan, schedule []Assignment) error
+    GetStudyPlan(ctx context.Context, planID int, userID string) (*StudyPlan, error)
+    GetUserStudyPlans(ctx context.Context, userID string) ([]StudyPlan, error)
+    GetStudyPlanSchedule(ctx context.Context, planID int) ([]Assignment, error)
+    RescheduleStudyPlan(ctx context.Context, planID int, schedule []Assignment) error
+    DeleteStudyPlan(ctx context.Context, planID int, userID string) error
+
+    FilterProblems(ctx context.Context, filter ProblemFilter) ([]int, error)
+    GetProblemActivity(ctx context.Context, userID string) ([]ProblemActivity, error)
+    CreateInterviewSession(ctx context.Context, session *InterviewSession) error
+    GetInterviewSession(ctx context.Context, sessionID int, userID string) (*InterviewSession, error)
+    GetUserInterviewSessions(ctx context.Context, userID string, limit int) ([]InterviewSession, error)
+    RecordInterviewOutcome(ctx context.Context, sessionID, problemID int, outcome string, elapsedSeconds int, recordedAt time.Time) error
+    EndInterviewSession(ctx context.Context, sessionID int, endedAt time.Time) error
+
+    SetDailySettings(ctx context.Context, settings *DailySettings) error
+    GetDailySettings(ctx context.Context, listID int) (*DailySettings, error)
+    DeleteDailySettings(ctx context.Context, listID int) error
+    PickDailyProblem(ctx context.Context, listID int, date time.Time, problemID int) error
+    GetDailyProblems(ctx context.Context, listID int, userID string, from, to time.Time) ([]DailyProblem, error)
+    SetDailyProblemDone(ctx context.Context, listID int, date time.Time, userID string, done bool, at time.Time) error
+
+    GetAccount(ctx context.Context, userID string) (*Account, error)
+    GetAccountByLeetCodeUsername(ctx context.Context, username string) (*Account, error)
+    UpdateAccount(ctx context.Context, account *Account) error
+    GetTrackedLeetCodeUsernames(ctx context.Context) ([]string, error)
+
+    StartPollRun(ctx context.Context, run *PollRun) error
+    FinishPollRun(ctx context.Context, run *PollRun) error
+    GetPollRuns(ctx context.Context, limit int) ([]PollRun, error)
+
+    SaveProblemFeedback(ctx context.Context, fb *ProblemFeedback) error
+    DeleteProblemFeedback(ctx context.Context, problemID int, userID string) error
+    GetProblemFeedback(ctx context.Context, problemID int, limit int) ([]ProblemFeedback, error)
+    GetCommunityRatings(ctx context.Context, problemIDs []int) (map[int]CommunityRating, error)
 }
 
 type service struct {
-	db *sql.DB
+	conn
+	dbName       string
+	queryTimeout time.Duration
 }
 
 type List struct {
     ID            int       `json:"id"`
     UserID        string    `json:"user_id"`
+    // GroupID is set on lists owned by a study group.
+    GroupID       *int      `json:"group_id"`
     Name          string    `json:"name"`
     Description   string    `json:"description"`
     Tags          string    `json:"tags"`
@@ -63,8 +132,19 @@ type ListItem struct {
     AcceptanceRate    float64   `json:"acceptance_rate"`
     IsPremium         bool      `json:"is_premium"`
     URL               string    `json:"url"`
-    AddedAt           time.Time `json:"added_at"`
-    Completed         bool      `json:"completed"`
+    AddedAt           time.Time  `json:"added_at"`
+    // Completed and CompletedAt are the caller's own state.
+    Completed         bool       `json:"completed"`
+    CompletedAt       *time.Time `json:"completed_at"`
+    // DoneCount is how many of the MemberCount people who can see the list
+    // have completed the item.
+    DoneCount         int        `json:"done_count"`
+    MemberCount       int        `json:"member_count"`
+}
+
+type CatalogStatus struct {
+    ProblemCount int        `json:"problem_count"`
+    LastSync     *time.Time `json:"last_sync"`
 }
 
 type ProgressEntry struct {
@@ -75,52 +155,32 @@ type ProgressEntry struct {
     HardSolved   int       `json:"hardSolved"`
 }
 
-var (
-	database   = os.Getenv("DB_DATABASE")
-	password   = os.Getenv("DB_PASSWORD")
-	username   = os.Getenv("DB_USERNAME")
-	port       = os.Getenv("DB_PORT")
-	host       = os.Getenv("DB_HOST")
-	schema     = os.Getenv("DB_SCHEMA")
-	dbInstance *service
-)
-
-
-func New() Service {
-	// Reuse Connection
-	if dbInstance != nil {
-		return dbInstance
+// New opens a connection pool for the configured driver. The pool connects
+// lazily, so an unreachable server is only reported on first use.
+func New(cfg config.Database) (Service, error) {
+	d, err := dialectFor(cfg.Driver)
+	if err != nil {
+		return nil, err
 	}
-	connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable&search_path=%s", username, password, host, port, database, schema)
-	db, err := sql.Open("pgx", connStr)
+	db, err := d.open(cfg)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	dbInstance = &service{
-		db: db,
+	name := cfg.Name
+	if d.name == config.DriverSQLite {
+		name = cfg.Path
 	}
-	return dbInstance
+	return &service{
+		conn:         conn{db: db, dialect: d},
+		dbName:       name,
+		queryTimeout: cfg.QueryTimeout,
+	}, nil
 }
 
-// Health checks the health of the database connection by pinging the database.
-// It returns a map with keys indicating various health statistics.
-func (s *service) Health() map[string]string {
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-	defer cancel()
-
+// PoolStats reports connection pool statistics along with a hint when the
+// numbers suggest the pool is misconfigured or under pressure.
+func (s *service) PoolStats() map[string]string {
 	stats := make(map[string]string)
-
-	// Ping the database
-	err := s.db.PingContext(ctx)
-	if err != nil {
-		stats["status"] = "down"
-		stats["error"] = fmt.Sprintf("db down: %v", err)
-		log.Fatalf(fmt.Sprintf("db down: %v", err)) // Log the error and terminate the program
-		return stats
-	}
-
-	// Database is up, add more statistics
-	stats["status"] = "up"
 	stats["message"] = "It's healthy"
 
 	// Get database stats (like open connections, in use, idle, etc.)
@@ -153,16 +213,51 @@ func (s *service) Health() map[string]string {
 	return stats
 }
 
+// Ping reports whether the database accepts connections.
+func (s *service) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// SchemaVersion returns the highest migration applied to the database.
+func (s *service) SchemaVersion(ctx context.Context) (int, error) {
+	return schemaVersion(ctx, s.conn)
+}
+
+// CatalogStatus reports how many problems are stored and when the catalog
+// was last synced from LeetCode. LastSync is nil for an empty catalog.
+func (s *service) CatalogStatus(ctx context.Context) (CatalogStatus, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var status CatalogStatus
+	err := s.queryRow(ctx, "SELECT COUNT(*) FROM leetcode_problems").Scan(&status.ProblemCount)
+	if err != nil {
+		return status, wrapError(ctx, err, "failed to read catalog status")
+	}
+	// Selecting the column rather than MAX(synced_at) keeps its declared type,
+	// which SQLite drops from aggregates, so both drivers scan a time.Time.
+	var lastSync time.Time
+	err = s.queryRow(ctx, "SELECT synced_at FROM leetcode_problems ORDER BY synced_at DESC LIMIT 1").Scan(&lastSync)
+	if err == sql.ErrNoRows {
+		return status, nil
+	}
+	if err != nil {
+		return status, wrapError(ctx, err, "failed to read catalog status")
+	}
+	status.LastSync = &lastSync
+	return status, nil
+}
+
 // Close closes the database connection.
 // It logs a message indicating the disconnection from the specific database.
 // If the connection is successfully closed, it returns nil.
 // If an error occurs while closing the connection, it returns the error.
 func (s *service) Close() error {
-	log.Printf("Disconnected from database: %s", database)
+	log.Printf("Disconnected from database: %s", s.dbName)
 	return s.db.Close()
 }
 
-func (s *service) InsertLeetCodeProblems(problems []leetcode.Problem) error {
+func (s *service) InsertLeetCodeProblems(ctx context.Context, problems []leetcode.Problem) error {
     start := time.Now()
     defer func() {
         elapsed := time.Since(start)
@@ -190,7 +285,7 @@ func (s *service) InsertLeetCodeProblems(problems []leetcode.Problem) error {
         go func(workerBatches [][]leetcode.Problem) {
             defer wg.Done()
             for _, batch := range workerBatches {
-                if err := s.insertBatch(batch); err != nil {
+                if err := s.insertBatch(ctx, batch); err != nil {
                     errCh <- err
                     return
                 }
@@ -209,334 +304,567 @@ func (s *service) InsertLeetCodeProblems(problems []leetcode.Problem) error {
     return nil
 }
 
-func (s *service) insertBatch(problems []leetcode.Problem) error {
+func (s *service) insertBatch(ctx context.Context, problems []leetcode.Problem) error {
     if len(problems) == 0 {
         return nil
     }
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
 
     valueStrings := make([]string, len(problems))
-    valueArgs := make([]interface{}, 0, len(problems)*6)
+    valueArgs := make([]interface{}, 0, len(problems)*7)
 
     for i, problem := range problems {
-        valueStrings[i] = fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d)", i*6+1, i*6+2, i*6+3, i*6+4, i*6+5, i*6+6)
-        valueArgs = append(valueArgs, problem.Title, problem.Difficulty, problem.AcceptanceRate, problem.FrontendID, problem.IsPremium, problem.URL)
+        valueStrings[i] = fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d)", i*7+1, i*7+2, i*7+3, i*7+4, i*7+5, i*7+6, i*7+7)
+        valueArgs = append(valueArgs, problem.Title, problem.TitleSlug, problem.Difficulty, problem.AcceptanceRate, problem.FrontendID, problem.IsPremium, problem.URL)
     }
 
     stmt := fmt.Sprintf(`
-        INSERT INTO leetcode_problems (title, difficulty, acceptance_rate, frontend_id, is_premium, url)
+        INSERT INTO leetcode_problems (title, title_slug, difficulty, acceptance_rate, frontend_id, is_premium, url)
         VALUES %s
         ON CONFLICT (frontend_id) DO UPDATE SET
             title = EXCLUDED.title,
+            title_slug = EXCLUDED.title_slug,
             difficulty = EXCLUDED.difficulty,
             acceptance_rate = EXCLUDED.acceptance_rate,
             is_premium = EXCLUDED.is_premium,
-            url = EXCLUDED.url
+            url = EXCLUDED.url,
+            synced_at = CURRENT_TIMESTAMP
     `, strings.Join(valueStrings, ","))
 
-    _, err := s.db.Exec(stmt, valueArgs...)
+    tx, err := s.db.BeginTx(ctx, nil)
     if err != nil {
-        return fmt.Errorf("error inserting batch: %v", err)
+        return wrapError(ctx, err, "failed to begin transaction")
     }
+    defer tx.Rollback()
 
+    if _, err := tx.ExecContext(ctx, s.dialect.rebind(stmt), valueArgs...); err != nil {
+        return wrapError(ctx, err, "error inserting batch")
+    }
+    if err := s.replaceTopics(ctx, tx, problems); err != nil {
+        return err
+    }
+    if err := tx.Commit(); err != nil {
+        return wrapError(ctx, err, "failed to commit transaction")
+    }
     return nil
 }
 
-func (s *service) GetListByID(listID int, userID string) (*List, error) {
-    var list List
-    err := s.db.QueryRow(`
-        SELECT id, user_id, name, description, tags, difficulty, estimated_time, notes, created_at
-        FROM lists
-        WHERE id = $1 AND user_id = $2
-    `, listID, userID).Scan(&list.ID, &list.UserID, &list.Name, &list.Description, &list.Tags, &list.Difficulty, &list.EstimatedTime, &list.Notes, &list.CreatedAt)
-    
-    if err != nil {
-        if err == sql.ErrNoRows {
-            log.Println("No list found for the given id and user")
-            return nil, nil
+// replaceTopics stores the topics of every problem whose topics were
+// fetched, replacing what was stored before.
+func (s *service) replaceTopics(ctx context.Context, tx *sql.Tx, problems []leetcode.Problem) error {
+    var ids []string
+    var idArgs []interface{}
+    var values []string
+    var valueArgs []interface{}
+    for _, problem := range problems {
+        if problem.Topics == nil {
+            continue
+        }
+        idArgs = append(idArgs, problem.FrontendID)
+        ids = append(ids, fmt.Sprintf("$%d", len(idArgs)))
+        for _, topic := range problem.Topics {
+            valueArgs = append(valueArgs, problem.FrontendID, topic)
+            values = append(values, fmt.Sprintf("($%d, $%d)", len(valueArgs)-1, len(valueArgs)))
         }
-        log.Printf("Error querying list: %v", err)
-        return nil, err
     }
-    return &list, nil
+    if len(ids) == 0 {
+        return nil
+    }
+
+    stmt := "DELETE FROM problem_topics WHERE problem_id IN (" + strings.Join(ids, ", ") + ")"
+    if _, err := tx.ExecContext(ctx, s.dialect.rebind(stmt), idArgs...); err != nil {
+        return wrapError(ctx, err, "error clearing problem topics")
+    }
+    if len(values) == 0 {
+        return nil
+    }
+    stmt = "INSERT INTO problem_topics (problem_id, topic) VALUES " + strings.Join(values, ", ") + " ON CONFLICT DO NOTHING"
+    if _, err := tx.ExecContext(ctx, s.dialect.rebind(stmt), valueArgs...); err != nil {
+        return wrapError(ctx, err, "error inserting problem topics")
+    }
+    return nil
 }
 
-func (s *service) CreateList(userID string, list *List) (int, error) {
-    var listID int
-    err := s.db.QueryRow(`
-        INSERT INTO lists (user_id, name, description, tags, difficulty, estimated_time, notes)
-        VALUES ($1, $2, $3, $4, $5, $6, $7)
-        RETURNING id
-    `, userID, list.Name, list.Description, list.Tags, list.Difficulty, list.EstimatedTime, list.Notes).Scan(&listID)
+// SetProblemTopics replaces the stored topics of every problem in topics.
+// Problems that are not in the catalog are skipped, and other problems keep
+// their topics.
+func (s *service) SetProblemTopics(ctx context.Context, topics map[int][]string) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    rows, err := s.query(ctx, "SELECT frontend_id FROM leetcode_problems ORDER BY frontend_id")
     if err != nil {
-        return 0, fmt.Errorf("failed to create list: %v", err)
+        return wrapError(ctx, err, "failed to fetch problem IDs")
     }
-    return listID, nil
+    var problems []leetcode.Problem
+    for rows.Next() {
+        var id int
+        if err := rows.Scan(&id); err != nil {
+            rows.Close()
+            return wrapError(ctx, err, "failed to scan problem ID")
+        }
+        if slugs, ok := topics[id]; ok {
+            if slugs == nil {
+                slugs = []string{}
+            }
+            problems = append(problems, leetcode.Problem{FrontendID: id, Topics: slugs})
+        }
+    }
+    rows.Close()
+    if err = rows.Err(); err != nil {
+        return wrapError(ctx, err, "error iterating over problem IDs")
+    }
+
+    tx, err := s.db.BeginTx(ctx, nil)
+    if err != nil {
+        return wrapError(ctx, err, "failed to begin transaction")
+    }
+    defer tx.Rollback()
+
+    const batchSize = 100
+    for i := 0; i < len(problems); i += batchSize {
+        if err := s.replaceTopics(ctx, tx, problems[i:min(i+batchSize, len(problems))]); err != nil {
+            return err
+        }
+    }
+    if err := tx.Commit(); err != nil {
+        return wrapError(ctx, err, "failed to commit transaction")
+    }
+    return nil
 }
 
-func (s *service) GetUserLists(userID string) ([]List, error) {
-    rows, err := s.db.Query(`
-        SELECT id, name, description, tags, difficulty, estimated_time, notes, created_at
-        FROM lists
-        WHERE user_id = $1
-        ORDER BY created_at DESC
-    `, userID)
+// problemTopics returns the stored topics of the problems in ids, in
+// alphabetical order. Problems without topics are left out.
+func (s *service) problemTopics(ctx context.Context, ids []int) (map[int][]string, error) {
+    topics := make(map[int][]string)
+    if len(ids) == 0 {
+        return topics, nil
+    }
+    placeholders := make([]string, len(ids))
+    args := make([]interface{}, len(ids))
+    for i, id := range ids {
+        placeholders[i] = fmt.Sprintf("$%d", i+1)
+        args[i] = id
+    }
+
+    rows, err := s.query(ctx, `
+        SELECT problem_id, topic FROM problem_topics
+        WHERE problem_id IN (`+strings.Join(placeholders, ", ")+`)
+        ORDER BY problem_id, topic
+    `, args...)
     if err != nil {
-        return nil, fmt.Errorf("failed to fetch user lists: %v", err)
+        return nil, wrapError(ctx, err, "failed to fetch problem topics")
     }
     defer rows.Close()
 
+    for rows.Next() {
+        var id int
+        var topic string
+        if err := rows.Scan(&id, &topic); err != nil {
+            return nil, wrapError(ctx, err, "failed to scan problem topic")
+        }
+        topics[id] = append(topics[id], topic)
+    }
+    if err = rows.Err(); err != nil {
+        return nil, wrapError(ctx, err, "error iterating over problem topics")
+    }
+    return topics, nil
+}
+
+// listColumns selects a List from lists aliased as l.
+const listColumns = "l.id, l.user_id, l.group_id, l.name, l.description, l.tags, l.difficulty, l.estimated_time, l.notes, l.created_at"
+
+// listVisibleTo is the condition on lists aliased as l for the user bound to
+// param: their own lists, lists shared with them, and their groups' lists.
+func listVisibleTo(param string) string {
+    return `(
+        (l.group_id IS NULL AND (l.user_id = ` + param + ` OR l.id IN (SELECT list_id FROM list_members WHERE user_id = ` + param + `)))
+        OR l.group_id IN (SELECT group_id FROM study_group_members WHERE user_id = ` + param + `)
+    )`
+}
+
+func scanLists(ctx context.Context, rows *sql.Rows) ([]List, error) {
+    defer rows.Close()
+
     var lists []List
     for rows.Next() {
         var list List
-        err := rows.Scan(&list.ID, &list.Name, &list.Description, &list.Tags, &list.Difficulty, &list.EstimatedTime, &list.Notes, &list.CreatedAt)
+        err := rows.Scan(&list.ID, &list.UserID, &list.GroupID, &list.Name, &list.Description, &list.Tags, &list.Difficulty, &list.EstimatedTime, &list.Notes, &list.CreatedAt)
         if err != nil {
-            return nil, fmt.Errorf("failed to scan list: %v", err)
+            return nil, wrapError(ctx, err, "failed to scan list")
         }
         lists = append(lists, list)
     }
 
-    if err = rows.Err(); err != nil {
-        return nil, fmt.Errorf("error iterating over lists: %v", err)
+    if err := rows.Err(); err != nil {
+        return nil, wrapError(ctx, err, "error iterating over lists")
     }
 
     return lists, nil
 }
 
-func (s *service) UserExists(userID string) (bool, error) {
+// GetListByID returns a list userID can see: one of their own, one shared
+// with them, or one owned by a group they belong to.
+func (s *service) GetListByID(ctx context.Context, listID int, userID string) (*List, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    var list List
+    err := s.queryRow(ctx, `
+        SELECT `+listColumns+`
+        FROM lists l
+        WHERE l.id = $1 AND `+listVisibleTo("$2")+`
+    `, listID, userID).Scan(&list.ID, &list.UserID, &list.GroupID, &list.Name, &list.Description, &list.Tags, &list.Difficulty, &list.EstimatedTime, &list.Notes, &list.CreatedAt)
+    
+    if err != nil {
+        if err == sql.ErrNoRows {
+            return nil, notFoundError("list %d not found", listID)
+        }
+        return nil, wrapError(ctx, err, "failed to query list")
+    }
+    return &list, nil
+}
+
+func (s *service) CreateList(ctx context.Context, userID string, list *List) (int, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    var listID int
+    err := s.queryRow(ctx, `
+        INSERT INTO lists (user_id, group_id, name, description, tags, difficulty, estimated_time, notes)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id
+    `, userID, list.GroupID, list.Name, list.Description, list.Tags, list.Difficulty, list.EstimatedTime, list.Notes).Scan(&listID)
+    if err != nil {
+        return 0, wrapError(ctx, err, "failed to create list")
+    }
+    return listID, nil
+}
+
+// GetUserLists returns userID's own lists, then the lists shared with them,
+// then the lists of every group they belong to, each newest first.
+func (s *service) GetUserLists(ctx context.Context, userID string) ([]List, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    rows, err := s.query(ctx, `
+        SELECT `+listColumns+`
+        FROM lists l
+        WHERE `+listVisibleTo("$1")+`
+        ORDER BY CASE WHEN l.group_id IS NOT NULL THEN 2 WHEN l.user_id = $1 THEN 0 ELSE 1 END, l.created_at DESC, l.id DESC
+    `, userID)
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to fetch user lists")
+    }
+    return scanLists(ctx, rows)
+}
+
+func (s *service) UserExists(ctx context.Context, userID string) (bool, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
     var exists bool
-    err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)", userID).Scan(&exists)
+    err := s.queryRow(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)", userID).Scan(&exists)
     if err != nil {
-        return false, fmt.Errorf("failed to check if user exists: %v", err)
+        return false, wrapError(ctx, err, "failed to check if user exists")
     }
     return exists, nil
 }
 
-func (s *service) EnsureUserExists(userID string) error {
-    exists, err := s.UserExists(userID)
+func (s *service) EnsureUserExists(ctx context.Context, userID string) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    exists, err := s.UserExists(ctx, userID)
     if err != nil {
         return err
     }
     if !exists {
-        _, err := s.db.Exec("INSERT INTO users (id) VALUES ($1)", userID)
+        _, err := s.exec(ctx, "INSERT INTO users (id) VALUES ($1)", userID)
         if err != nil {
-            return fmt.Errorf("failed to create user: %v", err)
+            return wrapError(ctx, err, "failed to create user")
         }
     }
     return nil
 }
 
-func (s *service) GetListItems(listID int) ([]ListItem, error) {
-    rows, err := s.db.Query(`
-        SELECT li.id, li.problem_id, lp.title, lp.difficulty, lp.acceptance_rate, lp.is_premium, lp.url, li.added_at, li.completed
+// GetListItems returns the items of listID with userID's completion state,
+// and how many of the list's members have completed each one. The members
+// are the owner and collaborators of a personal list, or everyone in the
+// group that owns a group list.
+func (s *service) GetListItems(ctx context.Context, listID int, userID string) ([]ListItem, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    rows, err := s.query(ctx, `
+        WITH members AS (
+            SELECT user_id FROM lists WHERE id = $1 AND group_id IS NULL
+            UNION
+            SELECT user_id FROM list_members WHERE list_id = $1
+            UNION
+            SELECT m.user_id FROM study_group_members m JOIN lists l ON l.group_id = m.group_id WHERE l.id = $1
+        )
+        SELECT li.id, li.problem_id, lp.title, lp.difficulty, lp.acceptance_rate, lp.is_premium, lp.url, li.added_at,
+            mine.list_item_id IS NOT NULL, mine.completed_at,
+            (SELECT COUNT(*) FROM list_item_completions c JOIN members m ON m.user_id = c.user_id WHERE c.list_item_id = li.id),
+            (SELECT COUNT(*) FROM members)
         FROM list_items li
         JOIN leetcode_problems lp ON li.problem_id = lp.frontend_id
+        LEFT JOIN list_item_completions mine ON mine.list_item_id = li.id AND mine.user_id = $2
         WHERE li.list_id = $1
         ORDER BY li.id ASC
-    `, listID)
+    `, listID, userID)
     if err != nil {
-        return nil, err
+        return nil, wrapError(ctx, err, "failed to fetch list items")
     }
     defer rows.Close()
 
     var items []ListItem
     for rows.Next() {
         var li ListItem
-        err := rows.Scan(&li.ID, &li.ProblemID, &li.ProblemTitle, &li.ProblemDifficulty, &li.AcceptanceRate, &li.IsPremium, &li.URL, &li.AddedAt, &li.Completed)
+        err := rows.Scan(&li.ID, &li.ProblemID, &li.ProblemTitle, &li.ProblemDifficulty, &li.AcceptanceRate, &li.IsPremium, &li.URL, &li.AddedAt,
+            &li.Completed, &li.CompletedAt, &li.DoneCount, &li.MemberCount)
         if err != nil {
-            return nil, err
+            return nil, wrapError(ctx, err, "failed to scan list item")
         }
         li.ListID = listID
         items = append(items, li)
     }
+    if err = rows.Err(); err != nil {
+        return nil, wrapError(ctx, err, "error iterating over list items")
+    }
     return items, nil
 }
 
-func (s *service) UpdateProblemCompletionStatus(listItemID int, completed bool) error {
-    _, err := s.db.Exec("UPDATE list_items SET completed = $1 WHERE id = $2", completed, listItemID)
-    return err
+// UpdateProblemCompletionStatus ticks or unticks an item for userID alone.
+// Anyone who can see the list may do so.
+func (s *service) UpdateProblemCompletionStatus(ctx context.Context, listItemID int, userID string, completed bool) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    var allowed bool
+    err := s.queryRow(ctx, `
+        SELECT EXISTS(SELECT 1 FROM lists l WHERE l.id = li.list_id AND `+listVisibleTo("$2")+`)
+        FROM list_items li
+        WHERE li.id = $1
+    `, listItemID, userID).Scan(&allowed)
+    if err != nil {
+        if err == sql.ErrNoRows {
+            return notFoundError("list item %d not found", listItemID)
+        }
+        return wrapError(ctx, err, "failed to look up list item owner")
+    }
+    if !allowed {
+        return forbiddenError("list item %d belongs to another user", listItemID)
+    }
+
+    // Ticking an item that is already complete keeps its original solve time.
+    if completed {
+        _, err = s.exec(ctx, `
+            INSERT INTO list_item_completions (list_item_id, user_id, completed_at) VALUES ($1, $2, CURRENT_TIMESTAMP)
+            ON CONFLICT (list_item_id, user_id) DO NOTHING
+        `, listItemID, userID)
+    } else {
+        _, err = s.exec(ctx, "DELETE FROM list_item_completions WHERE list_item_id = $1 AND user_id = $2", listItemID, userID)
+    }
+    if err != nil {
+        return wrapError(ctx, err, "failed to update completion status")
+    }
+    return nil
 }
 
 
 //for pagination
-func (s *service) GetLeetCodeProblems(page, pageSize int) ([]leetcode.Problem, int, error) {
+func (s *service) GetLeetCodeProblems(ctx context.Context, page, pageSize int) ([]leetcode.Problem, int, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
     var totalCount int
-    err := s.db.QueryRow("SELECT COUNT(*) FROM leetcode_problems").Scan(&totalCount)
+    err := s.queryRow(ctx, "SELECT COUNT(*) FROM leetcode_problems").Scan(&totalCount)
     if err != nil {
-        return nil, 0, fmt.Errorf("failed to get total count of problems: %v", err)
+        return nil, 0, wrapError(ctx, err, "failed to get total count of problems")
     }
 
     //offset
     offset := (page - 1) * pageSize
 
     //get results
-    rows, err := s.db.Query(`
-        SELECT frontend_id, title, difficulty, acceptance_rate, is_premium, url
+    rows, err := s.query(ctx, `
+        SELECT frontend_id, title, COALESCE(title_slug, ''), difficulty, acceptance_rate, is_premium, url
         FROM leetcode_problems
         ORDER BY frontend_id
         LIMIT $1 OFFSET $2
     `, pageSize, offset)
     if err != nil {
-        return nil, 0, fmt.Errorf("failed to fetch LeetCode problems: %v", err)
+        return nil, 0, wrapError(ctx, err, "failed to fetch LeetCode problems")
     }
     defer rows.Close()
 
     var problems []leetcode.Problem
     for rows.Next() {
         var p leetcode.Problem
-        err := rows.Scan(&p.FrontendID, &p.Title, &p.Difficulty, &p.AcceptanceRate, &p.IsPremium, &p.URL)
+        err := rows.Scan(&p.FrontendID, &p.Title, &p.TitleSlug, &p.Difficulty, &p.AcceptanceRate, &p.IsPremium, &p.URL)
         if err != nil {
-            return nil, 0, fmt.Errorf("failed to scan LeetCode problem: %v", err)
+            return nil, 0, wrapError(ctx, err, "failed to scan LeetCode problem")
         }
         problems = append(problems, p)
     }
 
     if err = rows.Err(); err != nil {
-        return nil, 0, fmt.Errorf("error iterating over LeetCode problems: %v", err)
+        return nil, 0, wrapError(ctx, err, "error iterating over LeetCode problems")
+    }
+
+    ids := make([]int, len(problems))
+    for i, p := range problems {
+        ids[i] = p.FrontendID
+    }
+    topics, err := s.problemTopics(ctx, ids)
+    if err != nil {
+        return nil, 0, err
+    }
+    for i := range problems {
+        problems[i].Topics = topics[problems[i].FrontendID]
     }
 
     return problems, totalCount, nil
 }
 
 
-func (s *service) AddProblemsToList(listID int, problemIDs []int) error {
+func (s *service) AddProblemsToList(ctx context.Context, listID int, problemIDs []int) error {
     if len(problemIDs) == 0 {
         return nil
     }
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
 
-    tx, err := s.db.Begin()
+    tx, err := s.db.BeginTx(ctx, nil)
     if err != nil {
-        return fmt.Errorf("failed to begin transaction: %v", err)
+        return wrapError(ctx, err, "failed to begin transaction")
     }
     defer tx.Rollback()
 
-    checkStmt, err := tx.Prepare("SELECT EXISTS(SELECT 1 FROM leetcode_problems WHERE frontend_id = $1)")
+    checkStmt, err := tx.PrepareContext(ctx, s.dialect.rebind("SELECT EXISTS(SELECT 1 FROM leetcode_problems WHERE frontend_id = $1)"))
     if err != nil {
-        return fmt.Errorf("failed to prepare check statement: %v", err)
+        return wrapError(ctx, err, "failed to prepare check statement")
     }
     defer checkStmt.Close()
 
-    insertStmt, err := tx.Prepare(`
+    insertStmt, err := tx.PrepareContext(ctx, s.dialect.rebind(`
         INSERT INTO list_items (list_id, problem_id)
         VALUES ($1, $2)
         ON CONFLICT (list_id, problem_id) DO NOTHING
-    `)
+    `))
     if err != nil {
-        return fmt.Errorf("failed to prepare insert statement: %v", err)
+        return wrapError(ctx, err, "failed to prepare insert statement")
     }
     defer insertStmt.Close()
 
     for _, problemID := range problemIDs {
         var exists bool
-        if err := checkStmt.QueryRow(problemID).Scan(&exists); err != nil {
-            return fmt.Errorf("failed to check if problem exists: %v", err)
+        if err := checkStmt.QueryRowContext(ctx, problemID).Scan(&exists); err != nil {
+            return wrapError(ctx, err, "failed to check if problem exists")
         }
         if !exists {
-            return fmt.Errorf("problem with ID %d does not exist in the database", problemID)
+            return validationError("problem %d does not exist", problemID)
         }
 
-        if _, err := insertStmt.Exec(listID, problemID); err != nil {
-            return fmt.Errorf("failed to add problem %d to list: %v", problemID, err)
+        if _, err := insertStmt.ExecContext(ctx, listID, problemID); err != nil {
+            return wrapError(ctx, err, fmt.Sprintf("failed to add problem %d to list", problemID))
         }
     }
 
     if err := tx.Commit(); err != nil {
-        return fmt.Errorf("failed to commit transaction: %v", err)
+        return wrapError(ctx, err, "failed to commit transaction")
     }
 
     return nil
 }
 
 
-func (s *service) DeleteList(listID int, userID string) error {
-    result, err := s.db.Exec(`
+func (s *service) DeleteList(ctx context.Context, listID int, userID string) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    result, err := s.exec(ctx, `
         DELETE FROM lists
-        WHERE id = $1 AND user_id = $2
+        WHERE id = $1 AND (
+            (group_id IS NULL AND user_id = $2)
+            OR group_id IN (SELECT group_id FROM study_group_members WHERE user_id = $2 AND role IN ('owner', 'admin'))
+        )
     `, listID, userID)
     if err != nil {
-        return fmt.Errorf("failed to delete list: %v", err)
+        return wrapError(ctx, err, "failed to delete list")
     }
     
     rowsAffected, err := result.RowsAffected()
     if err != nil {
-        return fmt.Errorf("error checking rows affected: %v", err)
+        return wrapError(ctx, err, "error checking rows affected")
     }
     if rowsAffected == 0 {
-        return fmt.Errorf("no list found with id %d for user %s", listID, userID)
+        return notFoundError("list %d not found", listID)
     }
     
     return nil
 }
 
-func (s *service) RemoveProblemFromList(listID int, problemID int) error {
-    result, err := s.db.Exec(`
+func (s *service) RemoveProblemFromList(ctx context.Context, listID int, problemID int) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    result, err := s.exec(ctx, `
         DELETE FROM list_items
         WHERE list_id = $1 AND problem_id = $2
     `, listID, problemID)
     if err != nil {
-        return fmt.Errorf("failed to remove problem from list: %v", err)
+        return wrapError(ctx, err, "failed to remove problem from list")
     }
     
     rowsAffected, err := result.RowsAffected()
     if err != nil {
-        return fmt.Errorf("error checking rows affected: %v", err)
+        return wrapError(ctx, err, "error checking rows affected")
     }
     if rowsAffected == 0 {
-        return fmt.Errorf("problem %d not found in list %d", problemID, listID)
+        return notFoundError("problem %d not found in list %d", problemID, listID)
     }
     
     return nil
 }
 
 
-func (s *service) StoreLeetCodeUserProgress(username string, stats map[string]interface{}) error {
-    submitStats, ok := stats["submitStats"].(map[string]interface{})
-    if !ok {
-        return fmt.Errorf("invalid stats structure: submitStats not found")
-    }
-    
-    acSubmissionNum, ok := submitStats["acSubmissionNum"].([]interface{})
-    if !ok {
-        return fmt.Errorf("invalid stats structure: acSubmissionNum not found")
-    }
-
-    var totalSolved, easySolved, mediumSolved, hardSolved int
-    for _, v := range acSubmissionNum {
-        stat := v.(map[string]interface{})
-        difficulty := stat["difficulty"].(string)
-        count := int(stat["count"].(float64))
-        switch difficulty {
-        case "All":
-            totalSolved = count
-        case "Easy":
-            easySolved = count
-        case "Medium":
-            mediumSolved = count
-        case "Hard":
-            hardSolved = count
-        }
+func (s *service) StoreLeetCodeUserProgress(ctx context.Context, username string, solved leetcode.SolvedCounts) error {
+    entry, err := progressFromCounts(solved)
+    if err != nil {
+        return err
     }
 
-    _, err := s.db.Exec(`
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    _, err = s.exec(ctx, `
         INSERT INTO user_progress (username, date, total_solved, easy_solved, medium_solved, hard_solved)
         VALUES ($1, CURRENT_DATE, $2, $3, $4, $5)
         ON CONFLICT (username, date) DO UPDATE
         SET total_solved = $2, easy_solved = $3, medium_solved = $4, hard_solved = $5
-    `, username, totalSolved, easySolved, mediumSolved, hardSolved)
+    `, username, entry.TotalSolved, entry.EasySolved, entry.MediumSolved, entry.HardSolved)
     if err != nil {
-        return fmt.Errorf("failed to store LeetCode user progress: %v", err)
+        return wrapError(ctx, err, "failed to store LeetCode user progress")
     }
     return nil
 }
 
-func (s *service) GetUserProgressHistory(username string) ([]ProgressEntry, error) {
-    rows, err := s.db.Query(`
+func (s *service) GetUserProgressHistory(ctx context.Context, username string) ([]ProgressEntry, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    rows, err := s.query(ctx, `
         SELECT date, total_solved, easy_solved, medium_solved, hard_solved
         FROM user_progress
         WHERE username = $1
         ORDER BY date ASC
     `, username)
     if err != nil {
-        return nil, fmt.Errorf("failed to query user progress history: %v", err)
+        return nil, wrapError(ctx, err, "failed to query user progress history")
     }
     defer rows.Close()
 
@@ -545,16 +873,41 @@ func (s *service) GetUserProgressHistory(username string) ([]ProgressEntry, erro
         var entry ProgressEntry
         err := rows.Scan(&entry.Date, &entry.TotalSolved, &entry.EasySolved, &entry.MediumSolved, &entry.HardSolved)
         if err != nil {
-            return nil, fmt.Errorf("failed to scan progress entry: %v", err)
+            return nil, wrapError(ctx, err, "failed to scan progress entry")
         }
         history = append(history, entry)
     }
 
     if err = rows.Err(); err != nil {
-        return nil, fmt.Errorf("error iterating over progress entries: %v", err)
+        return nil, wrapError(ctx, err, "error iterating over progress entries")
     }
 
     return history, nil
 }
 
 
+// progressFromCounts converts a LeetCode snapshot into a progress row,
+// rejecting counts that cannot be right.
+func progressFromCounts(solved leetcode.SolvedCounts) (ProgressEntry, error) {
+    if solved.Total < 0 || solved.Easy < 0 || solved.Medium < 0 || solved.Hard < 0 {
+        return ProgressEntry{}, validationError("solved counts must not be negative")
+    }
+    if solved.Easy+solved.Medium+solved.Hard > solved.Total {
+        return ProgressEntry{}, validationError("solved counts per difficulty exceed the total")
+    }
+    return ProgressEntry{
+        TotalSolved:  solved.Total,
+        EasySolved:   solved.Easy,
+        MediumSolved: solved.Medium,
+        HardSolved:   solved.Hard,
+    }, nil
+}
+
+// withTimeout bounds a single Service call by the configured query timeout.
+// A caller deadline that is already shorter wins.
+func (s *service) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+    if s.queryTimeout <= 0 {
+        return context.WithCancel(ctx)
+    }
+    return context.WithTimeout(ctx, s.queryTimeout)
+}
@@ -1,15 +1,26 @@
 package database
 
 import (
+	"LeetTracker/internal/config"
 	"context"
+	"fmt"
 	"log"
+	"os"
 	"testing"
 	"time"
+
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
+var (
+	testConfig config.Database
+	// postgresAvailable is false when Docker is missing; Postgres tests then
+	// skip while the in-memory contract tests still run.
+	postgresAvailable bool
+)
+
 func mustStartPostgresContainer() (func(context.Context) error, error) {
 	var (
 		dbName = "database"
@@ -32,9 +43,12 @@ func mustStartPostgresContainer() (func(context.Context) error, error) {
 		return nil, err
 	}
 
-	database = dbName
-	password = dbPwd
-	username = dbUser
+	testConfig = config.Database{
+		Name:     dbName,
+		Password: dbPwd,
+		Username: dbUser,
+		Schema:   "public",
+	}
 
 	dbHost, err := dbContainer.Host(context.Background())
 	if err != nil {
@@ -46,43 +60,86 @@ func mustStartPostgresContainer() (func(context.Context) error, error) {
 		return dbContainer.Terminate, err
 	}
 
-	host = dbHost
-	port = dbPort.Port()
+	testConfig.Host = dbHost
+	testConfig.Port = dbPort.Port()
 
     return dbContainer.Terminate, err
 }
 
 func TestMain(m *testing.M) {
-	teardown, err := mustStartPostgresContainer()
+	teardown, err := startPostgres()
 	if err != nil {
-		log.Fatalf("could not start postgres container: %v", err)
+		log.Printf("skipping Postgres tests, could not start postgres container: %v", err)
+	} else {
+		migrator, err := NewMigrator(testConfig)
+		if err != nil {
+			log.Fatalf("could not create migrator: %v", err)
+		}
+		if _, err := migrator.Up(context.Background()); err != nil {
+			log.Fatalf("could not migrate database: %v", err)
+		}
+		migrator.Close()
+		postgresAvailable = true
 	}
 
-	m.Run()
+	code := m.Run()
 
 	if teardown != nil && teardown(context.Background()) != nil {
 		log.Fatalf("could not teardown postgres container: %v", err)
 	}
+	os.Exit(code)
+}
+
+// startPostgres recovers from testcontainers panicking when no Docker daemon
+// is reachable and reports it as an ordinary error.
+func startPostgres() (teardown func(context.Context) error, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return mustStartPostgresContainer()
+}
+
+func requirePostgres(t *testing.T) {
+	t.Helper()
+	if !postgresAvailable {
+		t.Skip("Postgres container not available")
+	}
+}
+
+func mustNew(t *testing.T) Service {
+	t.Helper()
+	requirePostgres(t)
+	srv, err := New(testConfig)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	return srv
 }
 
 func TestNew(t *testing.T) {
-	srv := New()
+	srv := mustNew(t)
 	if srv == nil {
 		t.Fatal("New() returned nil")
 	}
 }
 
-func TestHealth(t *testing.T) {
-	srv := New()
-
-	stats := srv.Health()
+func TestPing(t *testing.T) {
+	srv := mustNew(t)
 
-	if stats["status"] != "up" {
-		t.Fatalf("expected status to be up, got %s", stats["status"])
+	if err := srv.Ping(context.Background()); err != nil {
+		t.Fatalf("expected Ping() to succeed, got %v", err)
 	}
+}
+
+func TestPoolStats(t *testing.T) {
+	srv := mustNew(t)
 
-	if _, ok := stats["error"]; ok {
-		t.Fatalf("expected error not to be present")
+	stats := srv.PoolStats()
+
+	if _, ok := stats["open_connections"]; !ok {
+		t.Fatalf("expected open_connections to be present")
 	}
 
 	if stats["message"] != "It's healthy" {
@@ -91,9 +148,67 @@ func TestHealth(t *testing.T) {
 }
 
 func TestClose(t *testing.T) {
-	srv := New()
+	srv := mustNew(t)
 
 	if srv.Close() != nil {
 		t.Fatalf("expected Close() to return nil")
 	}
 }
+
+func TestMigrationsRoundTrip(t *testing.T) {
+	requirePostgres(t)
+	ctx := context.Background()
+	migrator, err := NewMigrator(testConfig)
+	if err != nil {
+		t.Fatalf("NewMigrator() returned error: %v", err)
+	}
+	defer migrator.Close()
+
+	if _, err := migrator.Down(ctx); err != nil {
+		t.Fatalf("Down() returned error: %v", err)
+	}
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != ExpectedSchemaVersion()-1 {
+		t.Fatalf("expected version %d after Down(), got %d", ExpectedSchemaVersion()-1, version)
+	}
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up() returned error: %v", err)
+	}
+	if len(applied) != 1 {
+		t.Fatalf("expected Up() to reapply one migration, applied %d", len(applied))
+	}
+
+	srv := mustNew(t)
+	got, err := srv.SchemaVersion(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != ExpectedSchemaVersion() {
+		t.Fatalf("expected schema version %d, got %d", ExpectedSchemaVersion(), got)
+	}
+}
+
+func TestPostgresContract(t *testing.T) {
+	requirePostgres(t)
+	runServiceContract(t, func(t *testing.T) Service {
+		srv := mustNew(t)
+		_, err := srv.(*service).db.Exec(`TRUNCATE
+			daily_problem_completions, daily_problems, daily_problem_settings,
+			interview_problems, interview_sessions, problem_topics,
+			study_plan_assignments, study_plans, list_item_completions, list_members,
+			leaderboard_participants, leaderboards, study_group_members, study_groups,
+			poll_runs, problem_feedback, user_progress, list_items, lists, users,
+			leetcode_problems
+			RESTART IDENTITY CASCADE`)
+		if err != nil {
+			t.Fatalf("could not reset database: %v", err)
+		}
+		t.Cleanup(func() { srv.Close() })
+		return srv
+	})
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "regexp"
+
+    "LeetTracker/internal/config"
+)
+
+// placeholder matches the Postgres-style $N parameters queries are written in.
+var placeholder = regexp.MustCompile(`\$(\d+)`)
+
+// dialect captures what differs between the supported databases. Queries are
+// written once, in the subset of SQL that Postgres and SQLite share, with $N
+// placeholders that rebind rewrites where needed.
+type dialect struct {
+    // name is also the migrations subdirectory.
+    name       string
+    driverName string
+    // paramPrefix replaces the "$" of each placeholder.
+    paramPrefix string
+    // tableExists is a query taking the table name and returning a boolean.
+    tableExists string
+}
+
+var (
+    postgresDialect = dialect{
+        name:        config.DriverPostgres,
+        driverName:  "pgx",
+        paramPrefix: "$",
+        tableExists: "SELECT to_regclass($1) IS NOT NULL",
+    }
+    // SQLite reads ?N as the Nth argument, so reused parameters keep working.
+    sqliteDialect = dialect{
+        name:        config.DriverSQLite,
+        driverName:  "sqlite3",
+        paramPrefix: "?",
+        tableExists: "SELECT EXISTS(SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = $1)",
+    }
+)
+
+func dialectFor(driver string) (dialect, error) {
+    switch driver {
+    case config.DriverPostgres, "":
+        return postgresDialect, nil
+    case config.DriverSQLite:
+        return sqliteDialect, nil
+    }
+    return dialect{}, fmt.Errorf("unsupported database driver %q", driver)
+}
+
+func (d dialect) rebind(query string) string {
+    if d.paramPrefix == "$" {
+        return query
+    }
+    return placeholder.ReplaceAllString(query, d.paramPrefix+"$1")
+}
+
+// open returns a pool for cfg using this dialect's driver.
+func (d dialect) open(cfg config.Database) (*sql.DB, error) {
+    if d.name == config.DriverSQLite {
+        return openSQLite(cfg.Path)
+    }
+    db, err := sql.Open(d.driverName, cfg.DSN())
+    if err != nil {
+        return nil, fmt.Errorf("failed to open database: %v", err)
+    }
+    return db, nil
+}
+
+// conn runs queries through a dialect so callers can keep writing $N.
+type conn struct {
+    db      *sql.DB
+    dialect dialect
+}
+
+func (c conn) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+    return c.db.ExecContext(ctx, c.dialect.rebind(query), args...)
+}
+
+func (c conn) query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+    return c.db.QueryContext(ctx, c.dialect.rebind(query), args...)
+}
+
+func (c conn) queryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+    return c.db.QueryRowContext(ctx, c.dialect.rebind(query), args...)
+}
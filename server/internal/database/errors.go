@@ -0,0 +1,112 @@
+package database
+
+import (
+    "context"
+    "errors"
+    "fmt"
+
+    "github.com/jackc/pgx/v5/pgconn"
+)
+
+// Sentinel kinds for domain errors. Handlers map these to HTTP status codes
+// with errors.Is, so Service implementations should return them wrapped in an
+// *Error rather than as plain strings.
+var (
+    ErrNotFound   = errors.New("not found")
+    ErrForbidden  = errors.New("forbidden")
+    ErrConflict   = errors.New("conflict")
+    ErrValidation = errors.New("validation failed")
+)
+
+// Error is a domain error whose Message is safe to show to API clients.
+type Error struct {
+    Kind    error
+    Message string
+}
+
+func (e *Error) Error() string {
+    return e.Message
+}
+
+func (e *Error) Unwrap() error {
+    return e.Kind
+}
+
+func newError(kind error, format string, args ...interface{}) error {
+    return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+func notFoundError(format string, args ...interface{}) error {
+    return newError(ErrNotFound, format, args...)
+}
+
+func forbiddenError(format string, args ...interface{}) error {
+    return newError(ErrForbidden, format, args...)
+}
+
+func conflictError(format string, args ...interface{}) error {
+    return newError(ErrConflict, format, args...)
+}
+
+func validationError(format string, args ...interface{}) error {
+    return newError(ErrValidation, format, args...)
+}
+
+// CanceledError reports a query abandoned because its context was cancelled
+// by the caller or hit its deadline.
+type CanceledError struct {
+    Op  string
+    Err error
+}
+
+func (e *CanceledError) Error() string {
+    return fmt.Sprintf("%s: %v", e.Op, e.Err)
+}
+
+func (e *CanceledError) Unwrap() error {
+    return e.Err
+}
+
+// Timeout reports whether the query ran out of time rather than being
+// cancelled by the caller.
+func (e *CanceledError) Timeout() bool {
+    return errors.Is(e.Err, context.DeadlineExceeded)
+}
+
+// wrapError turns cancellation into a *CanceledError, constraint violations
+// reported by either driver into domain errors, and wraps anything else with
+// msg for logging.
+func wrapError(ctx context.Context, err error, msg string) error {
+    if ctxErr := ctx.Err(); ctxErr != nil {
+        return &CanceledError{Op: msg, Err: ctxErr}
+    }
+    if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+        return &CanceledError{Op: msg, Err: err}
+    }
+
+    kind := postgresConstraintKind(err)
+    if kind == nil {
+        kind = sqliteConstraintKind(err)
+    }
+    switch kind {
+    case ErrConflict:
+        return conflictError("%s: already exists", msg)
+    case ErrValidation:
+        return validationError("%s: references missing or invalid data", msg)
+    }
+    return fmt.Errorf("%s: %v", msg, err)
+}
+
+func postgresConstraintKind(err error) error {
+    var pgErr *pgconn.PgError
+    if !errors.As(err, &pgErr) {
+        return nil
+    }
+    switch pgErr.Code {
+    case "23505":
+        return ErrConflict
+    case "23503", "23514":
+        return ErrValidation
+    }
+    return nil
+}
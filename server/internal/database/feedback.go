@@ -0,0 +1,161 @@
+package database
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+)
+
+// ProblemFeedback is one user's opinion of a problem. Both ratings run from 1
+// to 5; InterviewLikelihood is nil when the user did not give one.
+type ProblemFeedback struct {
+    ID                  int       `json:"id"`
+    ProblemID           int       `json:"problem_id"`
+    UserID              string    `json:"-"`
+    DifficultyRating    int       `json:"difficulty_rating"`
+    InterviewLikelihood *int      `json:"interview_likelihood"`
+    Comment             string    `json:"comment"`
+    CreatedAt           time.Time `json:"created_at"`
+    UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// CommunityRating aggregates every user's feedback on one problem.
+type CommunityRating struct {
+    Ratings                int      `json:"ratings"`
+    AvgDifficulty          float64  `json:"avg_difficulty"`
+    AvgInterviewLikelihood *float64 `json:"avg_interview_likelihood"`
+}
+
+// SaveProblemFeedback creates or replaces the caller's feedback on a problem
+// and fills in the stored timestamps.
+func (s *service) SaveProblemFeedback(ctx context.Context, fb *ProblemFeedback) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    if err := s.requireProblem(ctx, fb.ProblemID); err != nil {
+        return err
+    }
+    err := s.queryRow(ctx, `
+        INSERT INTO problem_feedback (problem_id, user_id, difficulty_rating, interview_likelihood, comment)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (problem_id, user_id) DO UPDATE SET
+            difficulty_rating = EXCLUDED.difficulty_rating,
+            interview_likelihood = EXCLUDED.interview_likelihood,
+            comment = EXCLUDED.comment,
+            updated_at = CURRENT_TIMESTAMP
+        RETURNING id, created_at, updated_at
+    `, fb.ProblemID, fb.UserID, fb.DifficultyRating, fb.InterviewLikelihood, fb.Comment).Scan(&fb.ID, &fb.CreatedAt, &fb.UpdatedAt)
+    if err != nil {
+        return wrapError(ctx, err, "failed to save problem feedback")
+    }
+    return nil
+}
+
+func (s *service) DeleteProblemFeedback(ctx context.Context, problemID int, userID string) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    result, err := s.exec(ctx, "DELETE FROM problem_feedback WHERE problem_id = $1 AND user_id = $2", problemID, userID)
+    if err != nil {
+        return wrapError(ctx, err, "failed to delete problem feedback")
+    }
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return wrapError(ctx, err, "error checking rows affected")
+    }
+    if rowsAffected == 0 {
+        return notFoundError("no feedback on problem %d", problemID)
+    }
+    return nil
+}
+
+// GetProblemFeedback returns up to limit feedback entries for a problem,
+// most recently updated first.
+func (s *service) GetProblemFeedback(ctx context.Context, problemID int, limit int) ([]ProblemFeedback, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    if err := s.requireProblem(ctx, problemID); err != nil {
+        return nil, err
+    }
+    rows, err := s.query(ctx, `
+        SELECT id, problem_id, user_id, difficulty_rating, interview_likelihood, comment, created_at, updated_at
+        FROM problem_feedback
+        WHERE problem_id = $1
+        ORDER BY updated_at DESC, id DESC
+        LIMIT $2
+    `, problemID, limit)
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to fetch problem feedback")
+    }
+    defer rows.Close()
+
+    var feedback []ProblemFeedback
+    for rows.Next() {
+        var fb ProblemFeedback
+        err := rows.Scan(&fb.ID, &fb.ProblemID, &fb.UserID, &fb.DifficultyRating, &fb.InterviewLikelihood, &fb.Comment, &fb.CreatedAt, &fb.UpdatedAt)
+        if err != nil {
+            return nil, wrapError(ctx, err, "failed to scan problem feedback")
+        }
+        feedback = append(feedback, fb)
+    }
+    if err = rows.Err(); err != nil {
+        return nil, wrapError(ctx, err, "error iterating over problem feedback")
+    }
+    return feedback, nil
+}
+
+// GetCommunityRatings aggregates feedback for the given problems. Problems
+// nobody has rated are absent from the result.
+func (s *service) GetCommunityRatings(ctx context.Context, problemIDs []int) (map[int]CommunityRating, error) {
+    ratings := make(map[int]CommunityRating)
+    if len(problemIDs) == 0 {
+        return ratings, nil
+    }
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    placeholders := make([]string, len(problemIDs))
+    args := make([]interface{}, len(problemIDs))
+    for i, id := range problemIDs {
+        placeholders[i] = fmt.Sprintf("$%d", i+1)
+        args[i] = id
+    }
+    rows, err := s.query(ctx, fmt.Sprintf(`
+        SELECT problem_id, COUNT(*), AVG(CAST(difficulty_rating AS REAL)), AVG(CAST(interview_likelihood AS REAL))
+        FROM problem_feedback
+        WHERE problem_id IN (%s)
+        GROUP BY problem_id
+    `, strings.Join(placeholders, ", ")), args...)
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to aggregate problem feedback")
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var problemID int
+        var rating CommunityRating
+        if err := rows.Scan(&problemID, &rating.Ratings, &rating.AvgDifficulty, &rating.AvgInterviewLikelihood); err != nil {
+            return nil, wrapError(ctx, err, "failed to scan community rating")
+        }
+        ratings[problemID] = rating
+    }
+    if err = rows.Err(); err != nil {
+        return nil, wrapError(ctx, err, "error iterating over community ratings")
+    }
+    return ratings, nil
+}
+
+// requireProblem returns ErrNotFound for a frontend ID missing from the catalog.
+func (s *service) requireProblem(ctx context.Context, problemID int) error {
+    var exists bool
+    err := s.queryRow(ctx, "SELECT EXISTS(SELECT 1 FROM leetcode_problems WHERE frontend_id = $1)", problemID).Scan(&exists)
+    if err != nil {
+        return wrapError(ctx, err, "failed to check if problem exists")
+    }
+    if !exists {
+        return notFoundError("problem %d not found", problemID)
+    }
+    return nil
+}
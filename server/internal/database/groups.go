@@ -0,0 +1,311 @@
+package database
+
+import (
+    "context"
+    "database/sql"
+    "time"
+)
+
+// Group roles. The owner can do everything, admins manage lists and
+// members, and members can read everything in the group.
+const (
+    RoleOwner  = "owner"
+    RoleAdmin  = "admin"
+    RoleMember = "member"
+)
+
+// Group is a study group as seen by one of its members. Role is that
+// member's role.
+type Group struct {
+    ID          int       `json:"id"`
+    Name        string    `json:"name"`
+    Description string    `json:"description"`
+    InviteCode  string    `json:"invite_code,omitempty"`
+    Role        string    `json:"role"`
+    MemberCount int       `json:"member_count"`
+    CreatedAt   time.Time `json:"created_at"`
+}
+
+type GroupMember struct {
+    UserID             string    `json:"user_id"`
+    LeetCodeUsername   *string   `json:"leetcode_username"`
+    ProgressVisibility string    `json:"-"`
+    Role               string    `json:"role"`
+    JoinedAt           time.Time `json:"joined_at"`
+}
+
+// groupColumns selects a Group for the member joined as m.
+const groupColumns = `
+    g.id, g.name, g.description, g.invite_code, m.role, g.created_at,
+    (SELECT COUNT(*) FROM study_group_members c WHERE c.group_id = g.id)
+`
+
+func scanGroup(row interface{ Scan(...interface{}) error }, g *Group) error {
+    return row.Scan(&g.ID, &g.Name, &g.Description, &g.InviteCode, &g.Role, &g.CreatedAt, &g.MemberCount)
+}
+
+// CreateGroup stores group with ownerID as its owner and fills in the
+// generated fields. group.InviteCode must be set; a code already in use
+// returns ErrConflict.
+func (s *service) CreateGroup(ctx context.Context, ownerID string, group *Group) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    tx, err := s.db.BeginTx(ctx, nil)
+    if err != nil {
+        return wrapError(ctx, err, "failed to begin transaction")
+    }
+    defer tx.Rollback()
+
+    err = tx.QueryRowContext(ctx, s.dialect.rebind(`
+        INSERT INTO study_groups (name, description, invite_code)
+        VALUES ($1, $2, $3)
+        RETURNING id, created_at
+    `), group.Name, group.Description, group.InviteCode).Scan(&group.ID, &group.CreatedAt)
+    if err != nil {
+        return wrapError(ctx, err, "failed to create group")
+    }
+    _, err = tx.ExecContext(ctx, s.dialect.rebind(`
+        INSERT INTO study_group_members (group_id, user_id, role) VALUES ($1, $2, $3)
+    `), group.ID, ownerID, RoleOwner)
+    if err != nil {
+        return wrapError(ctx, err, "failed to add group owner")
+    }
+    if err := tx.Commit(); err != nil {
+        return wrapError(ctx, err, "failed to commit transaction")
+    }
+    group.Role = RoleOwner
+    group.MemberCount = 1
+    return nil
+}
+
+// GetGroup returns groupID as seen by userID. Groups userID is not a member
+// of are reported as not found.
+func (s *service) GetGroup(ctx context.Context, groupID int, userID string) (*Group, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    var group Group
+    err := scanGroup(s.queryRow(ctx, `
+        SELECT `+groupColumns+`
+        FROM study_groups g
+        JOIN study_group_members m ON m.group_id = g.id
+        WHERE g.id = $1 AND m.user_id = $2
+    `, groupID, userID), &group)
+    if err == sql.ErrNoRows {
+        return nil, notFoundError("group %d not found", groupID)
+    }
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to fetch group")
+    }
+    return &group, nil
+}
+
+func (s *service) GetUserGroups(ctx context.Context, userID string) ([]Group, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    rows, err := s.query(ctx, `
+        SELECT `+groupColumns+`
+        FROM study_groups g
+        JOIN study_group_members m ON m.group_id = g.id
+        WHERE m.user_id = $1
+        ORDER BY g.name, g.id
+    `, userID)
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to fetch groups")
+    }
+    defer rows.Close()
+
+    var groups []Group
+    for rows.Next() {
+        var group Group
+        if err := scanGroup(rows, &group); err != nil {
+            return nil, wrapError(ctx, err, "failed to scan group")
+        }
+        groups = append(groups, group)
+    }
+    if err = rows.Err(); err != nil {
+        return nil, wrapError(ctx, err, "error iterating over groups")
+    }
+    return groups, nil
+}
+
+// JoinGroup adds userID to the group with inviteCode as a member. Joining a
+// group twice keeps the existing role.
+func (s *service) JoinGroup(ctx context.Context, inviteCode, userID string) (*Group, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    var groupID int
+    err := s.queryRow(ctx, "SELECT id FROM study_groups WHERE invite_code = $1", inviteCode).Scan(&groupID)
+    if err == sql.ErrNoRows {
+        return nil, notFoundError("invite code not found")
+    }
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to look up invite code")
+    }
+
+    _, err = s.exec(ctx, `
+        INSERT INTO study_group_members (group_id, user_id, role) VALUES ($1, $2, $3)
+        ON CONFLICT (group_id, user_id) DO NOTHING
+    `, groupID, userID, RoleMember)
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to join group")
+    }
+    return s.GetGroup(ctx, groupID, userID)
+}
+
+func (s *service) SetGroupInviteCode(ctx context.Context, groupID int, inviteCode string) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    result, err := s.exec(ctx, "UPDATE study_groups SET invite_code = $1 WHERE id = $2", inviteCode, groupID)
+    if err != nil {
+        return wrapError(ctx, err, "failed to update invite code")
+    }
+    return expectRow(ctx, result, "group %d not found", groupID)
+}
+
+// DeleteGroup removes the group with its memberships and lists.
+func (s *service) DeleteGroup(ctx context.Context, groupID int) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    result, err := s.exec(ctx, "DELETE FROM study_groups WHERE id = $1", groupID)
+    if err != nil {
+        return wrapError(ctx, err, "failed to delete group")
+    }
+    return expectRow(ctx, result, "group %d not found", groupID)
+}
+
+// GetGroupMembers lists the group's members, owner first, then admins, then
+// everyone else in joining order.
+func (s *service) GetGroupMembers(ctx context.Context, groupID int) ([]GroupMember, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    rows, err := s.query(ctx, `
+        SELECT m.user_id, u.leetcode_username, u.progress_visibility, m.role, m.joined_at
+        FROM study_group_members m
+        JOIN users u ON u.id = m.user_id
+        WHERE m.group_id = $1
+        ORDER BY CASE m.role WHEN 'owner' THEN 0 WHEN 'admin' THEN 1 ELSE 2 END, m.joined_at, m.user_id
+    `, groupID)
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to fetch group members")
+    }
+    defer rows.Close()
+
+    var members []GroupMember
+    for rows.Next() {
+        var member GroupMember
+        if err := rows.Scan(&member.UserID, &member.LeetCodeUsername, &member.ProgressVisibility, &member.Role, &member.JoinedAt); err != nil {
+            return nil, wrapError(ctx, err, "failed to scan group member")
+        }
+        members = append(members, member)
+    }
+    if err = rows.Err(); err != nil {
+        return nil, wrapError(ctx, err, "error iterating over group members")
+    }
+    return members, nil
+}
+
+// SetGroupMemberRole makes a member an admin or a plain member. The owner's
+// role cannot be changed.
+func (s *service) SetGroupMemberRole(ctx context.Context, groupID int, userID, role string) error {
+    if role != RoleAdmin && role != RoleMember {
+        return validationError("role must be %s or %s", RoleAdmin, RoleMember)
+    }
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    if err := s.checkNotOwner(ctx, groupID, userID); err != nil {
+        return err
+    }
+    _, err := s.exec(ctx, `
+        UPDATE study_group_members SET role = $1 WHERE group_id = $2 AND user_id = $3
+    `, role, groupID, userID)
+    if err != nil {
+        return wrapError(ctx, err, "failed to update member role")
+    }
+    return nil
+}
+
+// RemoveGroupMember removes userID from the group. The owner cannot leave;
+// the group has to be deleted instead.
+func (s *service) RemoveGroupMember(ctx context.Context, groupID int, userID string) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    if err := s.checkNotOwner(ctx, groupID, userID); err != nil {
+        return err
+    }
+    _, err := s.exec(ctx, "DELETE FROM study_group_members WHERE group_id = $1 AND user_id = $2", groupID, userID)
+    if err != nil {
+        return wrapError(ctx, err, "failed to remove group member")
+    }
+    return nil
+}
+
+func (s *service) checkNotOwner(ctx context.Context, groupID int, userID string) error {
+    var role string
+    err := s.queryRow(ctx, "SELECT role FROM study_group_members WHERE group_id = $1 AND user_id = $2", groupID, userID).Scan(&role)
+    if err == sql.ErrNoRows {
+        return notFoundError("member not found in group %d", groupID)
+    }
+    if err != nil {
+        return wrapError(ctx, err, "failed to look up group member")
+    }
+    if role == RoleOwner {
+        return forbiddenError("the group owner cannot be changed or removed")
+    }
+    return nil
+}
+
+func (s *service) GetGroupLists(ctx context.Context, groupID int) ([]List, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    rows, err := s.query(ctx, `
+        SELECT `+listColumns+`
+        FROM lists l
+        WHERE l.group_id = $1
+        ORDER BY l.created_at DESC, l.id DESC
+    `, groupID)
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to fetch group lists")
+    }
+    return scanLists(ctx, rows)
+}
+
+// SharesGroup reports whether the two users are members of a common group.
+func (s *service) SharesGroup(ctx context.Context, userID, otherID string) (bool, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    var shared bool
+    err := s.queryRow(ctx, `
+        SELECT EXISTS(
+            SELECT 1 FROM study_group_members a
+            JOIN study_group_members b ON a.group_id = b.group_id
+            WHERE a.user_id = $1 AND b.user_id = $2
+        )
+    `, userID, otherID).Scan(&shared)
+    if err != nil {
+        return false, wrapError(ctx, err, "failed to check group membership")
+    }
+    return shared, nil
+}
+
+// expectRow turns an update or delete that matched nothing into ErrNotFound.
+func expectRow(ctx context.Context, result sql.Result, format string, args ...interface{}) error {
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return wrapError(ctx, err, "error checking rows affected")
+    }
+    if rowsAffected == 0 {
+        return notFoundError(format, args...)
+    }
+    return nil
+}
@@ -0,0 +1,168 @@
+package database
+
+import (
+    "context"
+    "time"
+)
+
+// Outcomes of a problem in a mock interview.
+const (
+    OutcomeSolved  = "solved"
+    OutcomePartial = "partial"
+    OutcomeFailed  = "failed"
+    OutcomeSkipped = "skipped"
+)
+
+var Outcomes = []string{OutcomeSolved, OutcomePartial, OutcomeFailed, OutcomeSkipped}
+
+type InterviewSession struct {
+    ID               int                `json:"id"`
+    UserID           string             `json:"-"`
+    // ListID is the list the problems were picked from, if any.
+    ListID           *int               `json:"list_id"`
+    TimeLimitMinutes int                `json:"time_limit_minutes"`
+    StartedAt        time.Time          `json:"started_at"`
+    ExpiresAt        time.Time          `json:"expires_at"`
+    // EndedAt is set when the session was ended before it expired.
+    EndedAt          *time.Time         `json:"ended_at"`
+    Problems         []InterviewProblem `json:"problems"`
+}
+
+// InterviewProblem is one problem of a session, in the order it was asked.
+// The outcome fields stay nil until one is recorded.
+type InterviewProblem struct {
+    ProblemID         int        `json:"problem_id"`
+    ProblemTitle      string     `json:"problem_title"`
+    ProblemDifficulty string     `json:"problem_difficulty"`
+    URL               string     `json:"url"`
+    Outcome           *string    `json:"outcome"`
+    ElapsedSeconds    *int       `json:"elapsed_seconds"`
+    RecordedAt        *time.Time `json:"recorded_at"`
+}
+
+// CreateInterviewSession stores session with its problems, of which only
+// ProblemID is read, and fills in the ID.
+func (s *service) CreateInterviewSession(ctx context.Context, session *InterviewSession) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    tx, err := s.db.BeginTx(ctx, nil)
+    if err != nil {
+        return wrapError(ctx, err, "failed to begin transaction")
+    }
+    defer tx.Rollback()
+
+    err = tx.QueryRowContext(ctx, s.dialect.rebind(`
+        INSERT INTO interview_sessions (user_id, list_id, time_limit_minutes, started_at, expires_at)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id
+    `), session.UserID, session.ListID, session.TimeLimitMinutes, session.StartedAt, session.ExpiresAt).Scan(&session.ID)
+    if err != nil {
+        return wrapError(ctx, err, "failed to create interview session")
+    }
+    for i, problem := range session.Problems {
+        _, err := tx.ExecContext(ctx, s.dialect.rebind(`
+            INSERT INTO interview_problems (session_id, position, problem_id) VALUES ($1, $2, $3)
+        `), session.ID, i+1, problem.ProblemID)
+        if err != nil {
+            return wrapError(ctx, err, "failed to add interview problem")
+        }
+    }
+    if err := tx.Commit(); err != nil {
+        return wrapError(ctx, err, "failed to commit transaction")
+    }
+    return nil
+}
+
+// GetInterviewSession returns sessionID if userID started it.
+func (s *service) GetInterviewSession(ctx context.Context, sessionID int, userID string) (*InterviewSession, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    sessions, err := s.interviewSessions(ctx, "i.id = $1 AND i.user_id = $2", sessionID, userID)
+    if err != nil {
+        return nil, err
+    }
+    if len(sessions) == 0 {
+        return nil, notFoundError("interview session %d not found", sessionID)
+    }
+    return &sessions[0], nil
+}
+
+// GetUserInterviewSessions returns userID's most recent sessions, up to
+// limit, newest first.
+func (s *service) GetUserInterviewSessions(ctx context.Context, userID string, limit int) ([]InterviewSession, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    return s.interviewSessions(ctx, `i.id IN (
+        SELECT id FROM interview_sessions WHERE user_id = $1 ORDER BY started_at DESC, id DESC LIMIT $2
+    )`, userID, limit)
+}
+
+// interviewSessions loads the sessions matching where, newest first, with
+// their problems in order.
+func (s *service) interviewSessions(ctx context.Context, where string, args ...interface{}) ([]InterviewSession, error) {
+    rows, err := s.query(ctx, `
+        SELECT i.id, i.user_id, i.list_id, i.time_limit_minutes, i.started_at, i.expires_at, i.ended_at,
+            p.problem_id, lp.title, lp.difficulty, lp.url, p.outcome, p.elapsed_seconds, p.recorded_at
+        FROM interview_sessions i
+        JOIN interview_problems p ON p.session_id = i.id
+        JOIN leetcode_problems lp ON lp.frontend_id = p.problem_id
+        WHERE `+where+`
+        ORDER BY i.started_at DESC, i.id DESC, p.position
+    `, args...)
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to fetch interview sessions")
+    }
+    defer rows.Close()
+
+    var sessions []InterviewSession
+    for rows.Next() {
+        var session InterviewSession
+        var problem InterviewProblem
+        err := rows.Scan(&session.ID, &session.UserID, &session.ListID, &session.TimeLimitMinutes, &session.StartedAt, &session.ExpiresAt, &session.EndedAt,
+            &problem.ProblemID, &problem.ProblemTitle, &problem.ProblemDifficulty, &problem.URL, &problem.Outcome, &problem.ElapsedSeconds, &problem.RecordedAt)
+        if err != nil {
+            return nil, wrapError(ctx, err, "failed to scan interview session")
+        }
+        if n := len(sessions); n == 0 || sessions[n-1].ID != session.ID {
+            sessions = append(sessions, session)
+        }
+        last := &sessions[len(sessions)-1]
+        last.Problems = append(last.Problems, problem)
+    }
+    if err = rows.Err(); err != nil {
+        return nil, wrapError(ctx, err, "error iterating over interview sessions")
+    }
+    return sessions, nil
+}
+
+// RecordInterviewOutcome sets the outcome of problemID in sessionID,
+// replacing any recorded before.
+func (s *service) RecordInterviewOutcome(ctx context.Context, sessionID, problemID int, outcome string, elapsedSeconds int, recordedAt time.Time) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    result, err := s.exec(ctx, `
+        UPDATE interview_problems SET outcome = $1, elapsed_seconds = $2, recorded_at = $3
+        WHERE session_id = $4 AND problem_id = $5
+    `, outcome, elapsedSeconds, recordedAt, sessionID, problemID)
+    if err != nil {
+        return wrapError(ctx, err, "failed to record interview outcome")
+    }
+    return expectRow(ctx, result, "problem %d is not in interview session %d", problemID, sessionID)
+}
+
+// EndInterviewSession records that sessionID ended at endedAt. A session
+// that has already ended keeps its first end time.
+func (s *service) EndInterviewSession(ctx context.Context, sessionID int, endedAt time.Time) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    result, err := s.exec(ctx, "UPDATE interview_sessions SET ended_at = COALESCE(ended_at, $1) WHERE id = $2", endedAt, sessionID)
+    if err != nil {
+        return wrapError(ctx, err, "failed to end interview session")
+    }
+    return expectRow(ctx, result, "interview session %d not found", sessionID)
+}
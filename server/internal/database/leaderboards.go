@@ -0,0 +1,332 @@
+package database
+
+import (
+    "context"
+    "database/sql"
+    "time"
+)
+
+// Tie-break rules for leaderboard participants with equal scores.
+const (
+    // TieBreakHardest ranks more hard, then medium, solves in the window
+    // higher.
+    TieBreakHardest = "hardest"
+    // TieBreakTotal ranks the larger all-time total higher.
+    TieBreakTotal = "total"
+    // TieBreakShared gives equal scores the same rank.
+    TieBreakShared = "shared"
+)
+
+var TieBreaks = []string{TieBreakHardest, TieBreakTotal, TieBreakShared}
+
+// tieBreakOrder is the ORDER BY each rule ranks by.
+var tieBreakOrder = map[string]string{
+    TieBreakHardest: "score DESC, hard DESC, medium DESC",
+    TieBreakTotal:   "score DESC, total DESC",
+    TieBreakShared:  "score DESC",
+}
+
+// dateLayout is how dates are bound for comparison with DATE columns. SQLite
+// stores them as text in this format, and Postgres parses it.
+const dateLayout = "2006-01-02"
+
+// Weights are the points each problem solved in the window is worth.
+type Weights struct {
+    Easy   int `json:"easy"`
+    Medium int `json:"medium"`
+    Hard   int `json:"hard"`
+}
+
+type Leaderboard struct {
+    ID           int       `json:"id"`
+    OwnerID      string    `json:"-"`
+    Name         string    `json:"name"`
+    Weights      Weights   `json:"weights"`
+    TieBreak     string    `json:"tie_break"`
+    Participants []string  `json:"participants"`
+    // Version changes whenever the leaderboard or its participants do.
+    Version      int       `json:"-"`
+    CreatedAt    time.Time `json:"created_at"`
+}
+
+// Standing is one participant's place on a leaderboard for a window.
+type Standing struct {
+    Rank         int    `json:"rank"`
+    Username     string `json:"username"`
+    Score        int    `json:"score"`
+    EasySolved   int    `json:"easy_solved"`
+    MediumSolved int    `json:"medium_solved"`
+    HardSolved   int    `json:"hard_solved"`
+    // TotalSolved is the all-time total at the end of the window.
+    TotalSolved  int    `json:"total_solved"`
+}
+
+// CreateLeaderboard stores board for board.OwnerID with its participants
+// and fills in the generated fields.
+func (s *service) CreateLeaderboard(ctx context.Context, board *Leaderboard) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    tx, err := s.db.BeginTx(ctx, nil)
+    if err != nil {
+        return wrapError(ctx, err, "failed to begin transaction")
+    }
+    defer tx.Rollback()
+
+    err = tx.QueryRowContext(ctx, s.dialect.rebind(`
+        INSERT INTO leaderboards (owner_id, name, easy_weight, medium_weight, hard_weight, tie_break)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, version, created_at
+    `), board.OwnerID, board.Name, board.Weights.Easy, board.Weights.Medium, board.Weights.Hard, board.TieBreak).Scan(&board.ID, &board.Version, &board.CreatedAt)
+    if err != nil {
+        return wrapError(ctx, err, "failed to create leaderboard")
+    }
+    for _, username := range board.Participants {
+        _, err := tx.ExecContext(ctx, s.dialect.rebind(`
+            INSERT INTO leaderboard_participants (leaderboard_id, username) VALUES ($1, $2)
+            ON CONFLICT (leaderboard_id, username) DO NOTHING
+        `), board.ID, username)
+        if err != nil {
+            return wrapError(ctx, err, "failed to add leaderboard participant")
+        }
+    }
+    if err := tx.Commit(); err != nil {
+        return wrapError(ctx, err, "failed to commit transaction")
+    }
+    if board.Participants == nil {
+        board.Participants = []string{}
+    }
+    return nil
+}
+
+// GetLeaderboard returns boardID if ownerID created it.
+func (s *service) GetLeaderboard(ctx context.Context, boardID int, ownerID string) (*Leaderboard, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    boards, err := s.leaderboards(ctx, "b.id = $1 AND b.owner_id = $2", boardID, ownerID)
+    if err != nil {
+        return nil, err
+    }
+    if len(boards) == 0 {
+        return nil, notFoundError("leaderboard %d not found", boardID)
+    }
+    return &boards[0], nil
+}
+
+func (s *service) GetUserLeaderboards(ctx context.Context, ownerID string) ([]Leaderboard, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    return s.leaderboards(ctx, "b.owner_id = $1", ownerID)
+}
+
+// leaderboards loads the boards matching where, newest first, with their
+// participants in alphabetical order.
+func (s *service) leaderboards(ctx context.Context, where string, args ...interface{}) ([]Leaderboard, error) {
+    rows, err := s.query(ctx, `
+        SELECT b.id, b.owner_id, b.name, b.easy_weight, b.medium_weight, b.hard_weight, b.tie_break, b.version, b.created_at, p.username
+        FROM leaderboards b
+        LEFT JOIN leaderboard_participants p ON p.leaderboard_id = b.id
+        WHERE `+where+`
+        ORDER BY b.created_at DESC, b.id DESC, p.username
+    `, args...)
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to fetch leaderboards")
+    }
+    defer rows.Close()
+
+    var boards []Leaderboard
+    for rows.Next() {
+        var board Leaderboard
+        var username sql.NullString
+        err := rows.Scan(&board.ID, &board.OwnerID, &board.Name, &board.Weights.Easy, &board.Weights.Medium, &board.Weights.Hard, &board.TieBreak, &board.Version, &board.CreatedAt, &username)
+        if err != nil {
+            return nil, wrapError(ctx, err, "failed to scan leaderboard")
+        }
+        if n := len(boards); n == 0 || boards[n-1].ID != board.ID {
+            board.Participants = []string{}
+            boards = append(boards, board)
+        }
+        if username.Valid {
+            last := &boards[len(boards)-1]
+            last.Participants = append(last.Participants, username.String)
+        }
+    }
+    if err = rows.Err(); err != nil {
+        return nil, wrapError(ctx, err, "error iterating over leaderboards")
+    }
+    return boards, nil
+}
+
+// UpdateLeaderboard saves board's name, weights and tie-break rule.
+func (s *service) UpdateLeaderboard(ctx context.Context, board *Leaderboard) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    err := s.queryRow(ctx, `
+        UPDATE leaderboards
+        SET name = $1, easy_weight = $2, medium_weight = $3, hard_weight = $4, tie_break = $5, version = version + 1
+        WHERE id = $6 AND owner_id = $7
+        RETURNING version
+    `, board.Name, board.Weights.Easy, board.Weights.Medium, board.Weights.Hard, board.TieBreak, board.ID, board.OwnerID).Scan(&board.Version)
+    if err == sql.ErrNoRows {
+        return notFoundError("leaderboard %d not found", board.ID)
+    }
+    if err != nil {
+        return wrapError(ctx, err, "failed to update leaderboard")
+    }
+    return nil
+}
+
+func (s *service) DeleteLeaderboard(ctx context.Context, boardID int, ownerID string) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    result, err := s.exec(ctx, "DELETE FROM leaderboards WHERE id = $1 AND owner_id = $2", boardID, ownerID)
+    if err != nil {
+        return wrapError(ctx, err, "failed to delete leaderboard")
+    }
+    return expectRow(ctx, result, "leaderboard %d not found", boardID)
+}
+
+// AddLeaderboardParticipants adds usernames to boardID, skipping any that
+// are already on it.
+func (s *service) AddLeaderboardParticipants(ctx context.Context, boardID int, usernames []string) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    tx, err := s.db.BeginTx(ctx, nil)
+    if err != nil {
+        return wrapError(ctx, err, "failed to begin transaction")
+    }
+    defer tx.Rollback()
+
+    result, err := tx.ExecContext(ctx, s.dialect.rebind("UPDATE leaderboards SET version = version + 1 WHERE id = $1"), boardID)
+    if err != nil {
+        return wrapError(ctx, err, "failed to update leaderboard")
+    }
+    if err := expectRow(ctx, result, "leaderboard %d not found", boardID); err != nil {
+        return err
+    }
+    for _, username := range usernames {
+        _, err := tx.ExecContext(ctx, s.dialect.rebind(`
+            INSERT INTO leaderboard_participants (leaderboard_id, username) VALUES ($1, $2)
+            ON CONFLICT (leaderboard_id, username) DO NOTHING
+        `), boardID, username)
+        if err != nil {
+            return wrapError(ctx, err, "failed to add leaderboard participant")
+        }
+    }
+    if err := tx.Commit(); err != nil {
+        return wrapError(ctx, err, "failed to commit transaction")
+    }
+    return nil
+}
+
+func (s *service) RemoveLeaderboardParticipant(ctx context.Context, boardID int, username string) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    tx, err := s.db.BeginTx(ctx, nil)
+    if err != nil {
+        return wrapError(ctx, err, "failed to begin transaction")
+    }
+    defer tx.Rollback()
+
+    result, err := tx.ExecContext(ctx, s.dialect.rebind(`
+        DELETE FROM leaderboard_participants WHERE leaderboard_id = $1 AND username = $2
+    `), boardID, username)
+    if err != nil {
+        return wrapError(ctx, err, "failed to remove leaderboard participant")
+    }
+    if err := expectRow(ctx, result, "%s is not on leaderboard %d", username, boardID); err != nil {
+        return err
+    }
+    if _, err := tx.ExecContext(ctx, s.dialect.rebind("UPDATE leaderboards SET version = version + 1 WHERE id = $1"), boardID); err != nil {
+        return wrapError(ctx, err, "failed to update leaderboard")
+    }
+    if err := tx.Commit(); err != nil {
+        return wrapError(ctx, err, "failed to commit transaction")
+    }
+    return nil
+}
+
+// RankLeaderboard ranks board's participants by their weighted solves from
+// from to to, both inclusive. Each snapshot's gain over the previous one is
+// credited to the snapshot's date, so a gap in the history is credited to
+// the day it ends, and nothing is known before a username's first snapshot.
+//
+// Participants whose linked account hides its progress from the board's
+// owner are left out: private accounts, and team accounts that share no
+// group with the owner.
+func (s *service) RankLeaderboard(ctx context.Context, board *Leaderboard, from, to time.Time) ([]Standing, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    order, ok := tieBreakOrder[board.TieBreak]
+    if !ok {
+        return nil, validationError("unknown tie-break rule %q", board.TieBreak)
+    }
+    rows, err := s.query(ctx, `
+        WITH visible AS (
+            SELECT p.username
+            FROM leaderboard_participants p
+            JOIN leaderboards b ON b.id = p.leaderboard_id
+            LEFT JOIN users u ON u.leetcode_username = p.username
+            WHERE p.leaderboard_id = $1 AND (
+                u.id IS NULL OR u.id = b.owner_id OR u.progress_visibility = 'public'
+                OR (u.progress_visibility = 'team' AND EXISTS(
+                    SELECT 1 FROM study_group_members a
+                    JOIN study_group_members o ON a.group_id = o.group_id
+                    WHERE a.user_id = u.id AND o.user_id = b.owner_id
+                ))
+            )
+        ),
+        deltas AS (
+            SELECT up.username, up.date,
+                up.easy_solved - LAG(up.easy_solved) OVER w AS easy,
+                up.medium_solved - LAG(up.medium_solved) OVER w AS medium,
+                up.hard_solved - LAG(up.hard_solved) OVER w AS hard,
+                LAST_VALUE(up.total_solved) OVER (w ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING) AS latest_total
+            FROM user_progress up
+            JOIN visible v ON v.username = up.username
+            WHERE up.date <= $3
+            WINDOW w AS (PARTITION BY up.username ORDER BY up.date)
+        ),
+        totals AS (
+            SELECT v.username,
+                COALESCE(SUM(CASE WHEN d.date >= $2 THEN d.easy END), 0) AS easy,
+                COALESCE(SUM(CASE WHEN d.date >= $2 THEN d.medium END), 0) AS medium,
+                COALESCE(SUM(CASE WHEN d.date >= $2 THEN d.hard END), 0) AS hard,
+                COALESCE(MAX(d.latest_total), 0) AS total
+            FROM visible v
+            LEFT JOIN deltas d ON d.username = v.username
+            GROUP BY v.username
+        ),
+        scored AS (
+            SELECT username, easy, medium, hard, total, easy * $4 + medium * $5 + hard * $6 AS score
+            FROM totals
+        )
+        SELECT RANK() OVER (ORDER BY `+order+`) AS place, username, score, easy, medium, hard, total
+        FROM scored
+        ORDER BY place, username
+    `, board.ID, from.Format(dateLayout), to.Format(dateLayout), board.Weights.Easy, board.Weights.Medium, board.Weights.Hard)
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to rank leaderboard")
+    }
+    defer rows.Close()
+
+    standings := []Standing{}
+    for rows.Next() {
+        var st Standing
+        if err := rows.Scan(&st.Rank, &st.Username, &st.Score, &st.EasySolved, &st.MediumSolved, &st.HardSolved, &st.TotalSolved); err != nil {
+            return nil, wrapError(ctx, err, "failed to scan standing")
+        }
+        standings = append(standings, st)
+    }
+    if err = rows.Err(); err != nil {
+        return nil, wrapError(ctx, err, "error iterating over standings")
+    }
+    return standings, nil
+}
@@ -0,0 +1,1931 @@
+package database
+
+import (
+    "context"
+    "slices"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    "LeetTracker/internal/utils/leetcode"
+)
+
+// memoryService is a Service backed by maps. It mirrors the Postgres
+// implementation closely enough to pass the shared contract tests, which
+// makes it suitable for handler tests that should not need Docker.
+type memoryService struct {
+    mu sync.Mutex
+
+    problems       map[int]leetcode.Problem
+    lastSync       time.Time
+    users          map[string]time.Time
+    accounts       map[string]Account
+    lists          map[int]List
+    items          map[int]ListItem
+    listMembers    map[int]map[string]ListMember
+    // completions maps a list item to the users who completed it and when.
+    completions    map[int]map[string]*time.Time
+    progress       map[string]map[string]ProgressEntry
+    feedback       map[int]map[string]ProblemFeedback
+    pollRuns       []PollRun
+    groups         map[int]Group
+    members        map[int]map[string]GroupMember
+    nextGroupID    int
+    leaderboards   map[int]Leaderboard
+    nextBoardID    int
+    plans          map[int]StudyPlan
+    // schedules maps a study plan to its list items' due dates.
+    schedules      map[int]map[int]time.Time
+    nextPlanID     int
+    interviews     map[int]InterviewSession
+    nextSessionID  int
+    daily          map[int]DailySettings
+    // dailyPicks maps a list to its picked problem by date, and dailyDone
+    // maps a list and date to the users who did the pick and when.
+    dailyPicks     map[int]map[string]int
+    dailyDone      map[int]map[string]map[string]time.Time
+    nextListID     int
+    nextItemID     int
+    nextFeedbackID int
+
+    // now is swappable so tests can control dates.
+    now func() time.Time
+}
+
+// NewMemory returns an empty in-memory Service. It is safe for concurrent use.
+func NewMemory() Service {
+    return &memoryService{
+        problems:     make(map[int]leetcode.Problem),
+        users:        make(map[string]time.Time),
+        accounts:     make(map[string]Account),
+        lists:        make(map[int]List),
+        items:        make(map[int]ListItem),
+        listMembers:  make(map[int]map[string]ListMember),
+        completions:  make(map[int]map[string]*time.Time),
+        progress:     make(map[string]map[string]ProgressEntry),
+        feedback:     make(map[int]map[string]ProblemFeedback),
+        groups:       make(map[int]Group),
+        members:      make(map[int]map[string]GroupMember),
+        leaderboards: make(map[int]Leaderboard),
+        plans:        make(map[int]StudyPlan),
+        schedules:    make(map[int]map[int]time.Time),
+        interviews:   make(map[int]InterviewSession),
+        daily:        make(map[int]DailySettings),
+        dailyPicks:   make(map[int]map[string]int),
+        dailyDone:    make(map[int]map[string]map[string]time.Time),
+        now:          time.Now,
+    }
+}
+
+func (m *memoryService) PoolStats() map[string]string {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return map[string]string{
+        "message":          "It's healthy",
+        "open_connections": "0",
+        "lists":            strconv.Itoa(len(m.lists)),
+        "problems":         strconv.Itoa(len(m.problems)),
+    }
+}
+
+func (m *memoryService) Ping(ctx context.Context) error {
+    return checkContext(ctx, "ping")
+}
+
+func (m *memoryService) SchemaVersion(ctx context.Context) (int, error) {
+    if err := checkContext(ctx, "failed to read schema version"); err != nil {
+        return 0, err
+    }
+    return ExpectedSchemaVersion(), nil
+}
+
+func (m *memoryService) CatalogStatus(ctx context.Context) (CatalogStatus, error) {
+    if err := checkContext(ctx, "failed to read catalog status"); err != nil {
+        return CatalogStatus{}, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    status := CatalogStatus{ProblemCount: len(m.problems)}
+    if len(m.problems) > 0 {
+        lastSync := m.lastSync
+        status.LastSync = &lastSync
+    }
+    return status, nil
+}
+
+func (m *memoryService) Close() error {
+    return nil
+}
+
+func (m *memoryService) InsertLeetCodeProblems(ctx context.Context, problems []leetcode.Problem) error {
+    if err := checkContext(ctx, "error inserting batch"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    for _, p := range problems {
+        switch {
+        case p.Topics == nil:
+            p.Topics = m.problems[p.FrontendID].Topics
+        case len(p.Topics) == 0:
+            p.Topics = nil
+        default:
+            p.Topics = addSorted(nil, p.Topics)
+        }
+        m.problems[p.FrontendID] = p
+    }
+    m.lastSync = m.now()
+    return nil
+}
+
+func (m *memoryService) SetProblemTopics(ctx context.Context, topics map[int][]string) error {
+    if err := checkContext(ctx, "error inserting problem topics"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    for id, slugs := range topics {
+        p, ok := m.problems[id]
+        if !ok {
+            continue
+        }
+        p.Topics = nil
+        if len(slugs) > 0 {
+            p.Topics = addSorted(nil, slugs)
+        }
+        m.problems[id] = p
+    }
+    return nil
+}
+
+func (m *memoryService) GetListByID(ctx context.Context, listID int, userID string) (*List, error) {
+    if err := checkContext(ctx, "failed to query list"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    list, ok := m.lists[listID]
+    if !ok || !m.canSeeList(list, userID) {
+        return nil, notFoundError("list %d not found", listID)
+    }
+    return &list, nil
+}
+
+func (m *memoryService) GetListItems(ctx context.Context, listID int, userID string) ([]ListItem, error) {
+    if err := checkContext(ctx, "failed to fetch list items"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    var members []string
+    if list, ok := m.lists[listID]; ok {
+        members = m.listUsers(list)
+    }
+    var items []ListItem
+    for _, item := range m.items {
+        if item.ListID != listID {
+            continue
+        }
+        item = m.withProblem(item)
+        item.CompletedAt, item.Completed = m.completions[item.ID][userID]
+        for _, member := range members {
+            if _, done := m.completions[item.ID][member]; done {
+                item.DoneCount++
+            }
+        }
+        item.MemberCount = len(members)
+        items = append(items, item)
+    }
+    sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+    return items, nil
+}
+
+func (m *memoryService) CreateList(ctx context.Context, userID string, list *List) (int, error) {
+    if err := checkContext(ctx, "failed to create list"); err != nil {
+        return 0, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if _, ok := m.users[userID]; !ok {
+        return 0, validationError("failed to create list: references missing or invalid data")
+    }
+    if list.GroupID != nil {
+        if _, ok := m.groups[*list.GroupID]; !ok {
+            return 0, validationError("failed to create list: references missing or invalid data")
+        }
+    }
+    m.nextListID++
+    stored := *list
+    stored.ID = m.nextListID
+    stored.UserID = userID
+    stored.CreatedAt = m.now()
+    m.lists[stored.ID] = stored
+    return stored.ID, nil
+}
+
+func (m *memoryService) GetUserLists(ctx context.Context, userID string) ([]List, error) {
+    if err := checkContext(ctx, "failed to fetch user lists"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    var lists []List
+    for _, list := range m.lists {
+        if m.canSeeList(list, userID) {
+            lists = append(lists, list)
+        }
+    }
+    sortLists(lists, userID)
+    return lists, nil
+}
+
+func (m *memoryService) EnsureUserExists(ctx context.Context, userID string) error {
+    if err := checkContext(ctx, "failed to create user"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if _, ok := m.users[userID]; !ok {
+        m.users[userID] = m.now()
+    }
+    return nil
+}
+
+func (m *memoryService) UserExists(ctx context.Context, userID string) (bool, error) {
+    if err := checkContext(ctx, "failed to check if user exists"); err != nil {
+        return false, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    _, ok := m.users[userID]
+    return ok, nil
+}
+
+func (m *memoryService) GetLeetCodeProblems(ctx context.Context, page, pageSize int) ([]leetcode.Problem, int, error) {
+    if err := checkContext(ctx, "failed to fetch LeetCode problems"); err != nil {
+        return nil, 0, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    ids := make([]int, 0, len(m.problems))
+    for id := range m.problems {
+        ids = append(ids, id)
+    }
+    sort.Ints(ids)
+
+    offset := (page - 1) * pageSize
+    var problems []leetcode.Problem
+    for i := offset; i < len(ids) && i < offset+pageSize; i++ {
+        problems = append(problems, m.problems[ids[i]])
+    }
+    return problems, len(ids), nil
+}
+
+func (m *memoryService) AddProblemsToList(ctx context.Context, listID int, problemIDs []int) error {
+    if len(problemIDs) == 0 {
+        return nil
+    }
+    if err := checkContext(ctx, "failed to add problems to list"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    // Validate everything first so a failure leaves the list untouched, like
+    // the transaction in the Postgres implementation.
+    for _, problemID := range problemIDs {
+        if _, ok := m.problems[problemID]; !ok {
+            return validationError("problem %d does not exist", problemID)
+        }
+    }
+    if _, ok := m.lists[listID]; !ok {
+        return validationError("failed to add problem %d to list: references missing or invalid data", problemIDs[0])
+    }
+
+    existing := make(map[int]bool)
+    for _, item := range m.items {
+        if item.ListID == listID {
+            existing[item.ProblemID] = true
+        }
+    }
+    for _, problemID := range problemIDs {
+        if existing[problemID] {
+            continue
+        }
+        existing[problemID] = true
+        m.nextItemID++
+        m.items[m.nextItemID] = ListItem{
+            ID:        m.nextItemID,
+            ListID:    listID,
+            ProblemID: problemID,
+            AddedAt:   m.now(),
+        }
+    }
+    return nil
+}
+
+func (m *memoryService) DeleteList(ctx context.Context, listID int, userID string) error {
+    if err := checkContext(ctx, "failed to delete list"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    list, ok := m.lists[listID]
+    if !ok || !m.canManageList(list, userID) {
+        return notFoundError("list %d not found", listID)
+    }
+    m.deleteList(listID)
+    return nil
+}
+
+func (m *memoryService) RemoveProblemFromList(ctx context.Context, listID int, problemID int) error {
+    if err := checkContext(ctx, "failed to remove problem from list"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    for id, item := range m.items {
+        if item.ListID == listID && item.ProblemID == problemID {
+            m.deleteItem(id)
+            return nil
+        }
+    }
+    return notFoundError("problem %d not found in list %d", problemID, listID)
+}
+
+func (m *memoryService) UpdateProblemCompletionStatus(ctx context.Context, listItemID int, userID string, completed bool) error {
+    if err := checkContext(ctx, "failed to update completion status"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    item, ok := m.items[listItemID]
+    if !ok {
+        return notFoundError("list item %d not found", listItemID)
+    }
+    if !m.canSeeList(m.lists[item.ListID], userID) {
+        return forbiddenError("list item %d belongs to another user", listItemID)
+    }
+    if !completed {
+        delete(m.completions[listItemID], userID)
+        return nil
+    }
+    if _, done := m.completions[listItemID][userID]; !done {
+        completedAt := m.now()
+        m.complete(listItemID, userID, &completedAt)
+    }
+    return nil
+}
+
+func (m *memoryService) GetProblemIDsBySlugs(ctx context.Context, slugs []string) (map[string]int, error) {
+    if err := checkContext(ctx, "failed to match problem slugs"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    wanted := make(map[string]bool, len(slugs))
+    for _, slug := range slugs {
+        wanted[slug] = true
+    }
+    ids := make(map[string]int)
+    for _, p := range m.problems {
+        if p.TitleSlug != "" && wanted[p.TitleSlug] {
+            ids[p.TitleSlug] = p.FrontendID
+        }
+    }
+    return ids, nil
+}
+
+func (m *memoryService) CompleteSolvedItems(ctx context.Context, userID string, solves []Solve, dryRun bool) ([]SolvedItem, error) {
+    solvedAt := earliestSolves(solves)
+    if len(solvedAt) == 0 {
+        return nil, nil
+    }
+    if err := checkContext(ctx, "failed to find solved list items"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    var items []SolvedItem
+    for _, item := range m.items {
+        list := m.lists[item.ListID]
+        at, solved := solvedAt[item.ProblemID]
+        _, done := m.completions[item.ID][userID]
+        if !m.canSeeList(list, userID) || done || !solved {
+            continue
+        }
+        items = append(items, SolvedItem{
+            ListItemID:   item.ID,
+            ListID:       item.ListID,
+            ListName:     list.Name,
+            ProblemID:    item.ProblemID,
+            ProblemTitle: m.problems[item.ProblemID].Title,
+            CompletedAt:  at,
+        })
+    }
+    sort.Slice(items, func(i, j int) bool { return items[i].ListItemID < items[j].ListItemID })
+    if dryRun {
+        return items, nil
+    }
+
+    for _, solved := range items {
+        completedAt := solved.CompletedAt
+        m.complete(solved.ListItemID, userID, &completedAt)
+    }
+    return items, nil
+}
+
+func (m *memoryService) StoreLeetCodeUserProgress(ctx context.Context, username string, solved leetcode.SolvedCounts) error {
+    entry, err := progressFromCounts(solved)
+    if err != nil {
+        return err
+    }
+    if err := checkContext(ctx, "failed to store LeetCode user progress"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    entry.Date = truncateToDate(m.now())
+    if m.progress[username] == nil {
+        m.progress[username] = make(map[string]ProgressEntry)
+    }
+    m.progress[username][entry.Date.Format("2006-01-02")] = entry
+    return nil
+}
+
+func (m *memoryService) GetUserProgressHistory(ctx context.Context, username string) ([]ProgressEntry, error) {
+    if err := checkContext(ctx, "failed to query user progress history"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    var history []ProgressEntry
+    for _, entry := range m.progress[username] {
+        history = append(history, entry)
+    }
+    sort.Slice(history, func(i, j int) bool { return history[i].Date.Before(history[j].Date) })
+    return history, nil
+}
+
+func (m *memoryService) SaveProblemFeedback(ctx context.Context, fb *ProblemFeedback) error {
+    if err := checkContext(ctx, "failed to save problem feedback"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if _, ok := m.problems[fb.ProblemID]; !ok {
+        return notFoundError("problem %d not found", fb.ProblemID)
+    }
+    if _, ok := m.users[fb.UserID]; !ok {
+        return validationError("failed to save problem feedback: references missing or invalid data")
+    }
+    if m.feedback[fb.ProblemID] == nil {
+        m.feedback[fb.ProblemID] = make(map[string]ProblemFeedback)
+    }
+    now := m.now()
+    if existing, ok := m.feedback[fb.ProblemID][fb.UserID]; ok {
+        fb.ID = existing.ID
+        fb.CreatedAt = existing.CreatedAt
+    } else {
+        m.nextFeedbackID++
+        fb.ID = m.nextFeedbackID
+        fb.CreatedAt = now
+    }
+    fb.UpdatedAt = now
+    m.feedback[fb.ProblemID][fb.UserID] = *fb
+    return nil
+}
+
+func (m *memoryService) DeleteProblemFeedback(ctx context.Context, problemID int, userID string) error {
+    if err := checkContext(ctx, "failed to delete problem feedback"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if _, ok := m.feedback[problemID][userID]; !ok {
+        return notFoundError("no feedback on problem %d", problemID)
+    }
+    delete(m.feedback[problemID], userID)
+    return nil
+}
+
+func (m *memoryService) GetProblemFeedback(ctx context.Context, problemID int, limit int) ([]ProblemFeedback, error) {
+    if err := checkContext(ctx, "failed to fetch problem feedback"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if _, ok := m.problems[problemID]; !ok {
+        return nil, notFoundError("problem %d not found", problemID)
+    }
+    var feedback []ProblemFeedback
+    for _, fb := range m.feedback[problemID] {
+        feedback = append(feedback, fb)
+    }
+    sort.Slice(feedback, func(i, j int) bool {
+        if feedback[i].UpdatedAt.Equal(feedback[j].UpdatedAt) {
+            return feedback[i].ID > feedback[j].ID
+        }
+        return feedback[i].UpdatedAt.After(feedback[j].UpdatedAt)
+    })
+    if len(feedback) > limit {
+        feedback = feedback[:limit]
+    }
+    return feedback, nil
+}
+
+func (m *memoryService) GetCommunityRatings(ctx context.Context, problemIDs []int) (map[int]CommunityRating, error) {
+    if err := checkContext(ctx, "failed to aggregate problem feedback"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    ratings := make(map[int]CommunityRating)
+    for _, problemID := range problemIDs {
+        entries := m.feedback[problemID]
+        if len(entries) == 0 {
+            continue
+        }
+        var difficulty, likelihood float64
+        var likelihoodCount int
+        for _, fb := range entries {
+            difficulty += float64(fb.DifficultyRating)
+            if fb.InterviewLikelihood != nil {
+                likelihood += float64(*fb.InterviewLikelihood)
+                likelihoodCount++
+            }
+        }
+        rating := CommunityRating{Ratings: len(entries), AvgDifficulty: difficulty / float64(len(entries))}
+        if likelihoodCount > 0 {
+            avg := likelihood / float64(likelihoodCount)
+            rating.AvgInterviewLikelihood = &avg
+        }
+        ratings[problemID] = rating
+    }
+    return ratings, nil
+}
+
+func (m *memoryService) GetAccount(ctx context.Context, userID string) (*Account, error) {
+    if err := checkContext(ctx, "failed to fetch account"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if _, ok := m.users[userID]; !ok {
+        return nil, notFoundError("account not found")
+    }
+    account := m.account(userID)
+    return &account, nil
+}
+
+func (m *memoryService) GetAccountByLeetCodeUsername(ctx context.Context, username string) (*Account, error) {
+    if err := checkContext(ctx, "failed to fetch account"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    for _, account := range m.accounts {
+        if account.LeetCodeUsername != nil && *account.LeetCodeUsername == username {
+            return &account, nil
+        }
+    }
+    return nil, notFoundError("account not found")
+}
+
+func (m *memoryService) UpdateAccount(ctx context.Context, account *Account) error {
+    if err := checkContext(ctx, "failed to link LeetCode username"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if _, ok := m.users[account.UserID]; !ok {
+        return notFoundError("user not found")
+    }
+    validVisibility := false
+    for _, v := range Visibilities {
+        validVisibility = validVisibility || v == account.ProgressVisibility
+    }
+    if !validVisibility {
+        return validationError("failed to link LeetCode username: references missing or invalid data")
+    }
+    if account.LeetCodeUsername != nil {
+        for id, other := range m.accounts {
+            if id != account.UserID && other.LeetCodeUsername != nil && *other.LeetCodeUsername == *account.LeetCodeUsername {
+                return conflictError("failed to link LeetCode username: already exists")
+            }
+        }
+    }
+    stored := *account
+    if stored.LeetCodeUsername != nil {
+        username := *stored.LeetCodeUsername
+        stored.LeetCodeUsername = &username
+    }
+    m.accounts[account.UserID] = stored
+    return nil
+}
+
+func (m *memoryService) GetTrackedLeetCodeUsernames(ctx context.Context) ([]string, error) {
+    if err := checkContext(ctx, "failed to fetch linked usernames"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    var usernames []string
+    for _, account := range m.accounts {
+        if account.LeetCodeUsername != nil {
+            usernames = addSorted(usernames, []string{*account.LeetCodeUsername})
+        }
+    }
+    for _, board := range m.leaderboards {
+        usernames = addSorted(usernames, board.Participants)
+    }
+    return usernames, nil
+}
+
+func (m *memoryService) StartPollRun(ctx context.Context, run *PollRun) error {
+    if err := checkContext(ctx, "failed to record poll run"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if run.Kind != PollScheduled && run.Kind != PollManual {
+        return validationError("failed to record poll run: references missing or invalid data")
+    }
+    run.ID = len(m.pollRuns) + 1
+    run.StartedAt = m.now()
+    run.FinishedAt = nil
+    m.pollRuns = append(m.pollRuns, *run)
+    return nil
+}
+
+func (m *memoryService) FinishPollRun(ctx context.Context, run *PollRun) error {
+    if err := checkContext(ctx, "failed to record poll run"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if run.ID < 1 || run.ID > len(m.pollRuns) {
+        return notFoundError("poll run %d not found", run.ID)
+    }
+    finishedAt := m.now()
+    stored := &m.pollRuns[run.ID-1]
+    stored.FinishedAt = &finishedAt
+    stored.Polled = run.Polled
+    stored.Failed = run.Failed
+    stored.Error = run.Error
+    run.FinishedAt = &finishedAt
+    return nil
+}
+
+func (m *memoryService) GetPollRuns(ctx context.Context, limit int) ([]PollRun, error) {
+    if err := checkContext(ctx, "failed to fetch poll runs"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    var runs []PollRun
+    for i := len(m.pollRuns) - 1; i >= 0 && len(runs) < limit; i-- {
+        runs = append(runs, m.pollRuns[i])
+    }
+    return runs, nil
+}
+
+func (m *memoryService) CreateGroup(ctx context.Context, ownerID string, group *Group) error {
+    if err := checkContext(ctx, "failed to create group"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if _, ok := m.users[ownerID]; !ok {
+        return validationError("failed to add group owner: references missing or invalid data")
+    }
+    for _, other := range m.groups {
+        if other.InviteCode == group.InviteCode {
+            return conflictError("failed to create group: already exists")
+        }
+    }
+    m.nextGroupID++
+    group.ID = m.nextGroupID
+    group.CreatedAt = m.now()
+    group.Role = RoleOwner
+    group.MemberCount = 1
+    m.groups[group.ID] = Group{ID: group.ID, Name: group.Name, Description: group.Description, InviteCode: group.InviteCode, CreatedAt: group.CreatedAt}
+    m.members[group.ID] = map[string]GroupMember{ownerID: {UserID: ownerID, Role: RoleOwner, JoinedAt: group.CreatedAt}}
+    return nil
+}
+
+func (m *memoryService) GetGroup(ctx context.Context, groupID int, userID string) (*Group, error) {
+    if err := checkContext(ctx, "failed to fetch group"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    group, ok := m.groupFor(groupID, userID)
+    if !ok {
+        return nil, notFoundError("group %d not found", groupID)
+    }
+    return &group, nil
+}
+
+func (m *memoryService) GetUserGroups(ctx context.Context, userID string) ([]Group, error) {
+    if err := checkContext(ctx, "failed to fetch groups"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    var groups []Group
+    for id := range m.groups {
+        if group, ok := m.groupFor(id, userID); ok {
+            groups = append(groups, group)
+        }
+    }
+    sort.Slice(groups, func(i, j int) bool {
+        if groups[i].Name == groups[j].Name {
+            return groups[i].ID < groups[j].ID
+        }
+        return groups[i].Name < groups[j].Name
+    })
+    return groups, nil
+}
+
+func (m *memoryService) JoinGroup(ctx context.Context, inviteCode, userID string) (*Group, error) {
+    if err := checkContext(ctx, "failed to join group"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    for id, group := range m.groups {
+        if group.InviteCode != inviteCode {
+            continue
+        }
+        if _, ok := m.users[userID]; !ok {
+            return nil, validationError("failed to join group: references missing or invalid data")
+        }
+        if _, ok := m.members[id][userID]; !ok {
+            m.members[id][userID] = GroupMember{UserID: userID, Role: RoleMember, JoinedAt: m.now()}
+        }
+        joined, _ := m.groupFor(id, userID)
+        return &joined, nil
+    }
+    return nil, notFoundError("invite code not found")
+}
+
+func (m *memoryService) SetGroupInviteCode(ctx context.Context, groupID int, inviteCode string) error {
+    if err := checkContext(ctx, "failed to update invite code"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    group, ok := m.groups[groupID]
+    if !ok {
+        return notFoundError("group %d not found", groupID)
+    }
+    for id, other := range m.groups {
+        if id != groupID && other.InviteCode == inviteCode {
+            return conflictError("failed to update invite code: already exists")
+        }
+    }
+    group.InviteCode = inviteCode
+    m.groups[groupID] = group
+    return nil
+}
+
+func (m *memoryService) DeleteGroup(ctx context.Context, groupID int) error {
+    if err := checkContext(ctx, "failed to delete group"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if _, ok := m.groups[groupID]; !ok {
+        return notFoundError("group %d not found", groupID)
+    }
+    delete(m.groups, groupID)
+    delete(m.members, groupID)
+    for listID, list := range m.lists {
+        if list.GroupID != nil && *list.GroupID == groupID {
+            m.deleteList(listID)
+        }
+    }
+    return nil
+}
+
+func (m *memoryService) GetGroupMembers(ctx context.Context, groupID int) ([]GroupMember, error) {
+    if err := checkContext(ctx, "failed to fetch group members"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    rank := map[string]int{RoleOwner: 0, RoleAdmin: 1, RoleMember: 2}
+    var members []GroupMember
+    for _, member := range m.members[groupID] {
+        account := m.account(member.UserID)
+        member.LeetCodeUsername = account.LeetCodeUsername
+        member.ProgressVisibility = account.ProgressVisibility
+        members = append(members, member)
+    }
+    sort.Slice(members, func(i, j int) bool {
+        a, b := members[i], members[j]
+        if rank[a.Role] != rank[b.Role] {
+            return rank[a.Role] < rank[b.Role]
+        }
+        if !a.JoinedAt.Equal(b.JoinedAt) {
+            return a.JoinedAt.Before(b.JoinedAt)
+        }
+        return a.UserID < b.UserID
+    })
+    return members, nil
+}
+
+func (m *memoryService) SetGroupMemberRole(ctx context.Context, groupID int, userID, role string) error {
+    if role != RoleAdmin && role != RoleMember {
+        return validationError("role must be %s or %s", RoleAdmin, RoleMember)
+    }
+    if err := checkContext(ctx, "failed to update member role"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    member, err := m.nonOwner(groupID, userID)
+    if err != nil {
+        return err
+    }
+    member.Role = role
+    m.members[groupID][userID] = member
+    return nil
+}
+
+func (m *memoryService) RemoveGroupMember(ctx context.Context, groupID int, userID string) error {
+    if err := checkContext(ctx, "failed to remove group member"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if _, err := m.nonOwner(groupID, userID); err != nil {
+        return err
+    }
+    delete(m.members[groupID], userID)
+    return nil
+}
+
+func (m *memoryService) GetGroupLists(ctx context.Context, groupID int) ([]List, error) {
+    if err := checkContext(ctx, "failed to fetch group lists"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    var lists []List
+    for _, list := range m.lists {
+        if list.GroupID != nil && *list.GroupID == groupID {
+            lists = append(lists, list)
+        }
+    }
+    sortLists(lists, "")
+    return lists, nil
+}
+
+func (m *memoryService) SharesGroup(ctx context.Context, userID, otherID string) (bool, error) {
+    if err := checkContext(ctx, "failed to check group membership"); err != nil {
+        return false, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    return m.sharesGroup(userID, otherID), nil
+}
+
+func (m *memoryService) sharesGroup(userID, otherID string) bool {
+    for _, members := range m.members {
+        _, a := members[userID]
+        _, b := members[otherID]
+        if a && b {
+            return true
+        }
+    }
+    return false
+}
+
+// groupFor returns groupID as userID sees it, if they are a member.
+func (m *memoryService) groupFor(groupID int, userID string) (Group, bool) {
+    member, ok := m.members[groupID][userID]
+    if !ok {
+        return Group{}, false
+    }
+    group := m.groups[groupID]
+    group.Role = member.Role
+    group.MemberCount = len(m.members[groupID])
+    return group, true
+}
+
+func (m *memoryService) nonOwner(groupID int, userID string) (GroupMember, error) {
+    member, ok := m.members[groupID][userID]
+    if !ok {
+        return GroupMember{}, notFoundError("member not found in group %d", groupID)
+    }
+    if member.Role == RoleOwner {
+        return GroupMember{}, forbiddenError("the group owner cannot be changed or removed")
+    }
+    return member, nil
+}
+
+func (m *memoryService) GetListMembers(ctx context.Context, listID int) ([]ListMember, error) {
+    if err := checkContext(ctx, "failed to fetch list members"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    list, ok := m.lists[listID]
+    if !ok || list.GroupID != nil {
+        return nil, nil
+    }
+    members := []ListMember{{
+        UserID:           list.UserID,
+        LeetCodeUsername: m.account(list.UserID).LeetCodeUsername,
+        Role:             RoleOwner,
+        AddedAt:          list.CreatedAt,
+    }}
+    var shared []ListMember
+    for _, member := range m.listMembers[listID] {
+        member.LeetCodeUsername = m.account(member.UserID).LeetCodeUsername
+        shared = append(shared, member)
+    }
+    sort.Slice(shared, func(i, j int) bool {
+        a, b := shared[i], shared[j]
+        if a.Role != b.Role {
+            return a.Role == ListEditor
+        }
+        if !a.AddedAt.Equal(b.AddedAt) {
+            return a.AddedAt.Before(b.AddedAt)
+        }
+        return a.UserID < b.UserID
+    })
+    return append(members, shared...), nil
+}
+
+func (m *memoryService) SetListMember(ctx context.Context, listID int, userID, role string) error {
+    if role != ListEditor && role != ListViewer {
+        return validationError("role must be %s or %s", ListEditor, ListViewer)
+    }
+    if err := checkContext(ctx, "failed to share list"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    list, ok := m.lists[listID]
+    switch {
+    case !ok:
+        return notFoundError("list %d not found", listID)
+    case list.GroupID != nil:
+        return validationError("group lists are shared with the group's members")
+    case list.UserID == userID:
+        return forbiddenError("the list owner cannot be changed or removed")
+    }
+    if _, ok := m.users[userID]; !ok {
+        return validationError("failed to share list: references missing or invalid data")
+    }
+    if m.listMembers[listID] == nil {
+        m.listMembers[listID] = make(map[string]ListMember)
+    }
+    member, ok := m.listMembers[listID][userID]
+    if !ok {
+        member = ListMember{UserID: userID, AddedAt: m.now()}
+    }
+    member.Role = role
+    m.listMembers[listID][userID] = member
+    return nil
+}
+
+func (m *memoryService) RemoveListMember(ctx context.Context, listID int, userID string) error {
+    if err := checkContext(ctx, "failed to remove list member"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if _, ok := m.listMembers[listID][userID]; !ok {
+        return notFoundError("member not found in list %d", listID)
+    }
+    delete(m.listMembers[listID], userID)
+    return nil
+}
+
+// listUsers returns everyone who can see list: its owner and collaborators,
+// or the members of the group that owns it.
+func (m *memoryService) listUsers(list List) []string {
+    var users []string
+    if list.GroupID != nil {
+        for userID := range m.members[*list.GroupID] {
+            users = append(users, userID)
+        }
+        return users
+    }
+    users = append(users, list.UserID)
+    for userID := range m.listMembers[list.ID] {
+        users = append(users, userID)
+    }
+    return users
+}
+
+func (m *memoryService) complete(listItemID int, userID string, completedAt *time.Time) {
+    if m.completions[listItemID] == nil {
+        m.completions[listItemID] = make(map[string]*time.Time)
+    }
+    m.completions[listItemID][userID] = completedAt
+}
+
+// deleteList removes a list with everything that cascades from it in SQL.
+func (m *memoryService) deleteList(listID int) {
+    delete(m.lists, listID)
+    delete(m.listMembers, listID)
+    for id, item := range m.items {
+        if item.ListID == listID {
+            m.deleteItem(id)
+        }
+    }
+    for id, plan := range m.plans {
+        if plan.ListID == listID {
+            delete(m.plans, id)
+            delete(m.schedules, id)
+        }
+    }
+    for id, session := range m.interviews {
+        if session.ListID != nil && *session.ListID == listID {
+            session.ListID = nil
+            m.interviews[id] = session
+        }
+    }
+    delete(m.daily, listID)
+    delete(m.dailyPicks, listID)
+    delete(m.dailyDone, listID)
+}
+
+// deleteItem removes a list item along with its completions and
+// assignments.
+func (m *memoryService) deleteItem(listItemID int) {
+    delete(m.items, listItemID)
+    delete(m.completions, listItemID)
+    for _, schedule := range m.schedules {
+        delete(schedule, listItemID)
+    }
+}
+
+// canSeeList mirrors the visibility rule in GetListByID.
+func (m *memoryService) canSeeList(list List, userID string) bool {
+    if list.GroupID == nil {
+        _, shared := m.listMembers[list.ID][userID]
+        return list.UserID == userID || shared
+    }
+    _, ok := m.members[*list.GroupID][userID]
+    return ok
+}
+
+// canManageList mirrors the rule in DeleteList: group lists need an owner
+// or admin.
+func (m *memoryService) canManageList(list List, userID string) bool {
+    if list.GroupID == nil {
+        return list.UserID == userID
+    }
+    member, ok := m.members[*list.GroupID][userID]
+    return ok && (member.Role == RoleOwner || member.Role == RoleAdmin)
+}
+
+// sortLists orders lists like GetUserLists: userID's own lists first, then
+// lists shared with them, then group lists, each newest first.
+func sortLists(lists []List, userID string) {
+    rank := func(list List) int {
+        switch {
+        case list.GroupID != nil:
+            return 2
+        case list.UserID == userID:
+            return 0
+        }
+        return 1
+    }
+    sort.Slice(lists, func(i, j int) bool {
+        a, b := lists[i], lists[j]
+        if rank(a) != rank(b) {
+            return rank(a) < rank(b)
+        }
+        if a.CreatedAt.Equal(b.CreatedAt) {
+            return a.ID > b.ID
+        }
+        return a.CreatedAt.After(b.CreatedAt)
+    })
+}
+
+func (m *memoryService) CreateLeaderboard(ctx context.Context, board *Leaderboard) error {
+    if err := checkContext(ctx, "failed to create leaderboard"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if _, ok := m.users[board.OwnerID]; !ok {
+        return validationError("failed to create leaderboard: references missing or invalid data")
+    }
+    m.nextBoardID++
+    board.ID = m.nextBoardID
+    board.Version = 1
+    board.CreatedAt = m.now()
+    board.Participants = addSorted(nil, board.Participants)
+    stored := *board
+    stored.Participants = append([]string(nil), board.Participants...)
+    m.leaderboards[board.ID] = stored
+    return nil
+}
+
+func (m *memoryService) GetLeaderboard(ctx context.Context, boardID int, ownerID string) (*Leaderboard, error) {
+    if err := checkContext(ctx, "failed to fetch leaderboards"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    board, ok := m.leaderboards[boardID]
+    if !ok || board.OwnerID != ownerID {
+        return nil, notFoundError("leaderboard %d not found", boardID)
+    }
+    board.Participants = append([]string{}, board.Participants...)
+    return &board, nil
+}
+
+func (m *memoryService) GetUserLeaderboards(ctx context.Context, ownerID string) ([]Leaderboard, error) {
+    if err := checkContext(ctx, "failed to fetch leaderboards"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    var boards []Leaderboard
+    for _, board := range m.leaderboards {
+        if board.OwnerID == ownerID {
+            board.Participants = append([]string{}, board.Participants...)
+            boards = append(boards, board)
+        }
+    }
+    sort.Slice(boards, func(i, j int) bool {
+        if boards[i].CreatedAt.Equal(boards[j].CreatedAt) {
+            return boards[i].ID > boards[j].ID
+        }
+        return boards[i].CreatedAt.After(boards[j].CreatedAt)
+    })
+    return boards, nil
+}
+
+func (m *memoryService) UpdateLeaderboard(ctx context.Context, board *Leaderboard) error {
+    if err := checkContext(ctx, "failed to update leaderboard"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    stored, ok := m.leaderboards[board.ID]
+    if !ok || stored.OwnerID != board.OwnerID {
+        return notFoundError("leaderboard %d not found", board.ID)
+    }
+    stored.Name = board.Name
+    stored.Weights = board.Weights
+    stored.TieBreak = board.TieBreak
+    stored.Version++
+    board.Version = stored.Version
+    m.leaderboards[board.ID] = stored
+    return nil
+}
+
+func (m *memoryService) DeleteLeaderboard(ctx context.Context, boardID int, ownerID string) error {
+    if err := checkContext(ctx, "failed to delete leaderboard"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    board, ok := m.leaderboards[boardID]
+    if !ok || board.OwnerID != ownerID {
+        return notFoundError("leaderboard %d not found", boardID)
+    }
+    delete(m.leaderboards, boardID)
+    return nil
+}
+
+func (m *memoryService) AddLeaderboardParticipants(ctx context.Context, boardID int, usernames []string) error {
+    if err := checkContext(ctx, "failed to add leaderboard participant"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    board, ok := m.leaderboards[boardID]
+    if !ok {
+        return notFoundError("leaderboard %d not found", boardID)
+    }
+    board.Participants = addSorted(board.Participants, usernames)
+    board.Version++
+    m.leaderboards[boardID] = board
+    return nil
+}
+
+func (m *memoryService) RemoveLeaderboardParticipant(ctx context.Context, boardID int, username string) error {
+    if err := checkContext(ctx, "failed to remove leaderboard participant"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    board := m.leaderboards[boardID]
+    for i, participant := range board.Participants {
+        if participant == username {
+            board.Participants = append(board.Participants[:i:i], board.Participants[i+1:]...)
+            board.Version++
+            m.leaderboards[boardID] = board
+            return nil
+        }
+    }
+    return notFoundError("%s is not on leaderboard %d", username, boardID)
+}
+
+func (m *memoryService) RankLeaderboard(ctx context.Context, board *Leaderboard, from, to time.Time) ([]Standing, error) {
+    if err := checkContext(ctx, "failed to rank leaderboard"); err != nil {
+        return nil, err
+    }
+    if _, ok := tieBreakOrder[board.TieBreak]; !ok {
+        return nil, validationError("unknown tie-break rule %q", board.TieBreak)
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    first, last := from.Format("2006-01-02"), to.Format("2006-01-02")
+    standings := []Standing{}
+    for _, username := range m.leaderboards[board.ID].Participants {
+        if !m.onLeaderboard(m.leaderboards[board.ID].OwnerID, username) {
+            continue
+        }
+        st := Standing{Username: username}
+        var dates []string
+        for date := range m.progress[username] {
+            if date <= last {
+                dates = append(dates, date)
+            }
+        }
+        sort.Strings(dates)
+        for i, date := range dates {
+            entry := m.progress[username][date]
+            st.TotalSolved = entry.TotalSolved
+            if i == 0 || date < first {
+                continue
+            }
+            prev := m.progress[username][dates[i-1]]
+            st.EasySolved += entry.EasySolved - prev.EasySolved
+            st.MediumSolved += entry.MediumSolved - prev.MediumSolved
+            st.HardSolved += entry.HardSolved - prev.HardSolved
+        }
+        st.Score = st.EasySolved*board.Weights.Easy + st.MediumSolved*board.Weights.Medium + st.HardSolved*board.Weights.Hard
+        standings = append(standings, st)
+    }
+
+    // ahead mirrors the RANK() ordering for each tie-break rule.
+    ahead := func(a, b Standing) bool {
+        if a.Score != b.Score {
+            return a.Score > b.Score
+        }
+        switch board.TieBreak {
+        case TieBreakHardest:
+            if a.HardSolved != b.HardSolved {
+                return a.HardSolved > b.HardSolved
+            }
+            return a.MediumSolved > b.MediumSolved
+        case TieBreakTotal:
+            return a.TotalSolved > b.TotalSolved
+        }
+        return false
+    }
+    sort.Slice(standings, func(i, j int) bool {
+        if ahead(standings[i], standings[j]) || ahead(standings[j], standings[i]) {
+            return ahead(standings[i], standings[j])
+        }
+        return standings[i].Username < standings[j].Username
+    })
+    for i := range standings {
+        standings[i].Rank = i + 1
+        if i > 0 && !ahead(standings[i-1], standings[i]) {
+            standings[i].Rank = standings[i-1].Rank
+        }
+    }
+    return standings, nil
+}
+
+// onLeaderboard mirrors the visibility filter in RankLeaderboard.
+func (m *memoryService) onLeaderboard(ownerID, username string) bool {
+    for _, account := range m.accounts {
+        if account.LeetCodeUsername == nil || *account.LeetCodeUsername != username {
+            continue
+        }
+        switch {
+        case account.UserID == ownerID || account.ProgressVisibility == VisibilityPublic:
+            return true
+        case account.ProgressVisibility == VisibilityTeam:
+            return m.sharesGroup(account.UserID, ownerID)
+        }
+        return false
+    }
+    return true
+}
+
+// addSorted returns a copy of the sorted slice with values added in order,
+// skipping duplicates.
+func addSorted(sorted, values []string) []string {
+    result := append([]string{}, sorted...)
+    for _, value := range values {
+        i := sort.SearchStrings(result, value)
+        if i < len(result) && result[i] == value {
+            continue
+        }
+        result = append(result[:i], append([]string{value}, result[i:]...)...)
+    }
+    return result
+}
+
+func (m *memoryService) CreateStudyPlan(ctx context.Context, plan *StudyPlan, schedule []Assignment) error {
+    if err := checkContext(ctx, "failed to create study plan"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    _, userOK := m.users[plan.UserID]
+    _, listOK := m.lists[plan.ListID]
+    if !userOK || !listOK {
+        return validationError("failed to create study plan: references missing or invalid data")
+    }
+    for _, a := range schedule {
+        if _, ok := m.items[a.ListItemID]; !ok {
+            return validationError("failed to schedule study plan item: references missing or invalid data")
+        }
+    }
+    m.nextPlanID++
+    plan.ID = m.nextPlanID
+    plan.CreatedAt = m.now()
+    stored := *plan
+    stored.StartDate = truncateToDate(plan.StartDate)
+    m.plans[plan.ID] = stored
+    m.schedules[plan.ID] = make(map[int]time.Time, len(schedule))
+    for _, a := range schedule {
+        m.schedules[plan.ID][a.ListItemID] = truncateToDate(a.DueDate)
+    }
+    return nil
+}
+
+func (m *memoryService) GetStudyPlan(ctx context.Context, planID int, userID string) (*StudyPlan, error) {
+    if err := checkContext(ctx, "failed to fetch study plans"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    plan, ok := m.studyPlan(planID)
+    if !ok || plan.UserID != userID {
+        return nil, notFoundError("study plan %d not found", planID)
+    }
+    return &plan, nil
+}
+
+func (m *memoryService) GetUserStudyPlans(ctx context.Context, userID string) ([]StudyPlan, error) {
+    if err := checkContext(ctx, "failed to fetch study plans"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    var plans []StudyPlan
+    for id := range m.plans {
+        if plan, ok := m.studyPlan(id); ok && plan.UserID == userID {
+            plans = append(plans, plan)
+        }
+    }
+    sort.Slice(plans, func(i, j int) bool {
+        a, b := plans[i], plans[j]
+        if a.CreatedAt.Equal(b.CreatedAt) {
+            return a.ID > b.ID
+        }
+        return a.CreatedAt.After(b.CreatedAt)
+    })
+    return plans, nil
+}
+
+// studyPlan mirrors the join in studyPlans: it fills in the list's name and
+// hides plans on lists their creator can no longer see.
+func (m *memoryService) studyPlan(planID int) (StudyPlan, bool) {
+    plan, ok := m.plans[planID]
+    if !ok {
+        return StudyPlan{}, false
+    }
+    list := m.lists[plan.ListID]
+    if !m.canSeeList(list, plan.UserID) {
+        return StudyPlan{}, false
+    }
+    plan.ListName = list.Name
+    return plan, true
+}
+
+func (m *memoryService) GetStudyPlanSchedule(ctx context.Context, planID int) ([]Assignment, error) {
+    if err := checkContext(ctx, "failed to fetch study plan schedule"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    userID := m.plans[planID].UserID
+    schedule := []Assignment{}
+    for itemID, due := range m.schedules[planID] {
+        item := m.withProblem(m.items[itemID])
+        a := Assignment{
+            ListItemID:        itemID,
+            ProblemID:         item.ProblemID,
+            ProblemTitle:      item.ProblemTitle,
+            ProblemDifficulty: item.ProblemDifficulty,
+            URL:               item.URL,
+            DueDate:           due,
+        }
+        a.CompletedAt, a.Completed = m.completions[itemID][userID]
+        schedule = append(schedule, a)
+    }
+    sort.Slice(schedule, func(i, j int) bool {
+        a, b := schedule[i], schedule[j]
+        if a.DueDate.Equal(b.DueDate) {
+            return a.ListItemID < b.ListItemID
+        }
+        return a.DueDate.Before(b.DueDate)
+    })
+    return schedule, nil
+}
+
+func (m *memoryService) RescheduleStudyPlan(ctx context.Context, planID int, schedule []Assignment) error {
+    if err := checkContext(ctx, "failed to update study plan"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    plan, ok := m.plans[planID]
+    if !ok {
+        return notFoundError("study plan %d not found", planID)
+    }
+    for _, a := range schedule {
+        if _, ok := m.items[a.ListItemID]; !ok {
+            return validationError("failed to reschedule study plan item: references missing or invalid data")
+        }
+    }
+    rebalancedAt := m.now()
+    plan.RebalancedAt = &rebalancedAt
+    m.plans[planID] = plan
+    for _, a := range schedule {
+        m.schedules[planID][a.ListItemID] = truncateToDate(a.DueDate)
+    }
+    return nil
+}
+
+func (m *memoryService) DeleteStudyPlan(ctx context.Context, planID int, userID string) error {
+    if err := checkContext(ctx, "failed to delete study plan"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    plan, ok := m.plans[planID]
+    if !ok || plan.UserID != userID {
+        return notFoundError("study plan %d not found", planID)
+    }
+    delete(m.plans, planID)
+    delete(m.schedules, planID)
+    return nil
+}
+
+func (m *memoryService) FilterProblems(ctx context.Context, filter ProblemFilter) ([]int, error) {
+    if err := checkContext(ctx, "failed to filter problems"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    inList := make(map[int]bool)
+    solved := make(map[int]bool)
+    for id, item := range m.items {
+        if filter.ListID != nil && item.ListID == *filter.ListID {
+            inList[item.ProblemID] = true
+        }
+        if _, done := m.completions[id][filter.ExcludeSolvedBy]; done {
+            solved[item.ProblemID] = true
+        }
+    }
+    for _, session := range m.interviews {
+        for _, problem := range session.Problems {
+            if session.UserID == filter.ExcludeSolvedBy && problem.Outcome != nil && *problem.Outcome == OutcomeSolved {
+                solved[problem.ProblemID] = true
+            }
+        }
+    }
+
+    ids := []int{}
+    for id, p := range m.problems {
+        switch {
+        case filter.ListID != nil && !inList[id]:
+        case len(filter.Difficulties) > 0 && !containsFold(filter.Difficulties, p.Difficulty):
+        case len(filter.Topics) > 0 && !sharesTopic(p.Topics, filter.Topics):
+        case filter.ExcludePremium && p.IsPremium:
+        case filter.ExcludeSolvedBy != "" && solved[id]:
+        default:
+            ids = append(ids, id)
+        }
+    }
+    sort.Ints(ids)
+    return ids, nil
+}
+
+func (m *memoryService) GetProblemActivity(ctx context.Context, userID string) ([]ProblemActivity, error) {
+    if err := checkContext(ctx, "failed to fetch problem activity"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    activity := make(map[int]*ProblemActivity)
+    get := func(problemID int) *ProblemActivity {
+        if activity[problemID] == nil {
+            activity[problemID] = &ProblemActivity{ProblemID: problemID}
+        }
+        return activity[problemID]
+    }
+    for id, item := range m.items {
+        completedAt, done := m.completions[id][userID]
+        visible := m.canSeeList(m.lists[item.ListID], userID)
+        if !done && !visible {
+            continue
+        }
+        a := get(item.ProblemID)
+        a.OnList = a.OnList || visible
+        if done && (a.CompletedAt == nil || completedAt.After(*a.CompletedAt)) {
+            a.CompletedAt = completedAt
+        }
+    }
+    for _, session := range m.interviews {
+        if session.UserID != userID {
+            continue
+        }
+        for _, problem := range session.Problems {
+            if problem.Outcome == nil {
+                continue
+            }
+            a := get(problem.ProblemID)
+            if a.OutcomeAt == nil || problem.RecordedAt.After(*a.OutcomeAt) {
+                a.Outcome, a.OutcomeAt = problem.Outcome, problem.RecordedAt
+            }
+        }
+    }
+    return sortActivity(activity), nil
+}
+
+func containsFold(values []string, value string) bool {
+    for _, v := range values {
+        if strings.EqualFold(v, value) {
+            return true
+        }
+    }
+    return false
+}
+
+func sharesTopic(topics, wanted []string) bool {
+    for _, topic := range wanted {
+        if slices.Contains(topics, strings.TrimSpace(topic)) {
+            return true
+        }
+    }
+    return false
+}
+
+func (m *memoryService) CreateInterviewSession(ctx context.Context, session *InterviewSession) error {
+    if err := checkContext(ctx, "failed to create interview session"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if _, ok := m.users[session.UserID]; !ok {
+        return validationError("failed to create interview session: references missing or invalid data")
+    }
+    if session.ListID != nil {
+        if _, ok := m.lists[*session.ListID]; !ok {
+            return validationError("failed to create interview session: references missing or invalid data")
+        }
+    }
+    seen := make(map[int]bool)
+    for _, problem := range session.Problems {
+        if _, ok := m.problems[problem.ProblemID]; !ok {
+            return validationError("failed to add interview problem: references missing or invalid data")
+        }
+        if seen[problem.ProblemID] {
+            return conflictError("failed to add interview problem: already exists")
+        }
+        seen[problem.ProblemID] = true
+    }
+    m.nextSessionID++
+    session.ID = m.nextSessionID
+    stored := *session
+    stored.Problems = make([]InterviewProblem, len(session.Problems))
+    for i, problem := range session.Problems {
+        stored.Problems[i] = InterviewProblem{ProblemID: problem.ProblemID}
+    }
+    m.interviews[session.ID] = stored
+    return nil
+}
+
+func (m *memoryService) GetInterviewSession(ctx context.Context, sessionID int, userID string) (*InterviewSession, error) {
+    if err := checkContext(ctx, "failed to fetch interview sessions"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    session, ok := m.interviews[sessionID]
+    if !ok || session.UserID != userID {
+        return nil, notFoundError("interview session %d not found", sessionID)
+    }
+    session = m.withInterviewProblems(session)
+    return &session, nil
+}
+
+func (m *memoryService) GetUserInterviewSessions(ctx context.Context, userID string, limit int) ([]InterviewSession, error) {
+    if err := checkContext(ctx, "failed to fetch interview sessions"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    var sessions []InterviewSession
+    for _, session := range m.interviews {
+        if session.UserID == userID {
+            sessions = append(sessions, m.withInterviewProblems(session))
+        }
+    }
+    sort.Slice(sessions, func(i, j int) bool {
+        a, b := sessions[i], sessions[j]
+        if a.StartedAt.Equal(b.StartedAt) {
+            return a.ID > b.ID
+        }
+        return a.StartedAt.After(b.StartedAt)
+    })
+    if len(sessions) > limit {
+        sessions = sessions[:limit]
+    }
+    return sessions, nil
+}
+
+// withInterviewProblems copies session's problems and fills in the catalog
+// columns interviewSessions joins in SQL.
+func (m *memoryService) withInterviewProblems(session InterviewSession) InterviewSession {
+    problems := make([]InterviewProblem, len(session.Problems))
+    for i, problem := range session.Problems {
+        p := m.problems[problem.ProblemID]
+        problem.ProblemTitle = p.Title
+        problem.ProblemDifficulty = p.Difficulty
+        problem.URL = p.URL
+        problems[i] = problem
+    }
+    session.Problems = problems
+    return session
+}
+
+func (m *memoryService) RecordInterviewOutcome(ctx context.Context, sessionID, problemID int, outcome string, elapsedSeconds int, recordedAt time.Time) error {
+    if err := checkContext(ctx, "failed to record interview outcome"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if !slices.Contains(Outcomes, outcome) || elapsedSeconds < 0 {
+        return validationError("failed to record interview outcome: references missing or invalid data")
+    }
+    session := m.interviews[sessionID]
+    for i, problem := range session.Problems {
+        if problem.ProblemID == problemID {
+            session.Problems[i].Outcome = &outcome
+            session.Problems[i].ElapsedSeconds = &elapsedSeconds
+            session.Problems[i].RecordedAt = &recordedAt
+            return nil
+        }
+    }
+    return notFoundError("problem %d is not in interview session %d", problemID, sessionID)
+}
+
+func (m *memoryService) EndInterviewSession(ctx context.Context, sessionID int, endedAt time.Time) error {
+    if err := checkContext(ctx, "failed to end interview session"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    session, ok := m.interviews[sessionID]
+    if !ok {
+        return notFoundError("interview session %d not found", sessionID)
+    }
+    if session.EndedAt == nil {
+        session.EndedAt = &endedAt
+        m.interviews[sessionID] = session
+    }
+    return nil
+}
+
+func (m *memoryService) SetDailySettings(ctx context.Context, settings *DailySettings) error {
+    if err := checkContext(ctx, "failed to save problem of the day settings"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if _, ok := m.lists[settings.ListID]; !ok || !slices.Contains(DailySources, settings.Source) || settings.NoRepeatDays < 0 {
+        return validationError("failed to save problem of the day settings: references missing or invalid data")
+    }
+    settings.UpdatedAt = m.now().UTC()
+    stored := *settings
+    stored.Difficulties = append([]string{}, settings.Difficulties...)
+    stored.Topics = append([]string{}, settings.Topics...)
+    m.daily[settings.ListID] = stored
+    return nil
+}
+
+func (m *memoryService) GetDailySettings(ctx context.Context, listID int) (*DailySettings, error) {
+    if err := checkContext(ctx, "failed to fetch problem of the day settings"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    settings, ok := m.daily[listID]
+    if !ok {
+        return nil, notFoundError("list %d has no problem of the day", listID)
+    }
+    settings.Difficulties = append([]string{}, settings.Difficulties...)
+    settings.Topics = append([]string{}, settings.Topics...)
+    return &settings, nil
+}
+
+func (m *memoryService) DeleteDailySettings(ctx context.Context, listID int) error {
+    if err := checkContext(ctx, "failed to delete problem of the day settings"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if _, ok := m.daily[listID]; !ok {
+        return notFoundError("list %d has no problem of the day", listID)
+    }
+    delete(m.daily, listID)
+    return nil
+}
+
+func (m *memoryService) PickDailyProblem(ctx context.Context, listID int, date time.Time, problemID int) error {
+    if err := checkContext(ctx, "failed to pick problem of the day"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if _, ok := m.lists[listID]; !ok {
+        return validationError("failed to pick problem of the day: references missing or invalid data")
+    }
+    if _, ok := m.problems[problemID]; !ok {
+        return validationError("failed to pick problem of the day: references missing or invalid data")
+    }
+    if m.dailyPicks[listID] == nil {
+        m.dailyPicks[listID] = make(map[string]int)
+    }
+    day := date.Format(dateLayout)
+    if _, picked := m.dailyPicks[listID][day]; !picked {
+        m.dailyPicks[listID][day] = problemID
+    }
+    return nil
+}
+
+func (m *memoryService) GetDailyProblems(ctx context.Context, listID int, userID string, from, to time.Time) ([]DailyProblem, error) {
+    if err := checkContext(ctx, "failed to fetch problems of the day"); err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    picks := []DailyProblem{}
+    first, last := from.Format(dateLayout), to.Format(dateLayout)
+    for day, problemID := range m.dailyPicks[listID] {
+        if day < first || day > last {
+            continue
+        }
+        date, _ := time.Parse(dateLayout, day)
+        p := m.problems[problemID]
+        pick := DailyProblem{Date: date, ProblemID: problemID, ProblemTitle: p.Title, ProblemDifficulty: p.Difficulty, URL: p.URL}
+        if doneAt, ok := m.dailyDone[listID][day][userID]; ok {
+            pick.Done, pick.DoneAt = true, &doneAt
+        }
+        picks = append(picks, pick)
+    }
+    sort.Slice(picks, func(i, j int) bool { return picks[i].Date.After(picks[j].Date) })
+    return picks, nil
+}
+
+func (m *memoryService) SetDailyProblemDone(ctx context.Context, listID int, date time.Time, userID string, done bool, at time.Time) error {
+    if err := checkContext(ctx, "failed to update problem of the day"); err != nil {
+        return err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    day := date.Format(dateLayout)
+    if _, picked := m.dailyPicks[listID][day]; !picked {
+        return notFoundError("list %d has no problem of the day for %s", listID, day)
+    }
+    if _, ok := m.users[userID]; !ok {
+        return validationError("failed to update problem of the day: references missing or invalid data")
+    }
+    if m.dailyDone[listID] == nil {
+        m.dailyDone[listID] = make(map[string]map[string]time.Time)
+    }
+    if m.dailyDone[listID][day] == nil {
+        m.dailyDone[listID][day] = make(map[string]time.Time)
+    }
+    if !done {
+        delete(m.dailyDone[listID][day], userID)
+    } else if _, ok := m.dailyDone[listID][day][userID]; !ok {
+        m.dailyDone[listID][day][userID] = at
+    }
+    return nil
+}
+
+// account returns the stored settings for userID, or the column defaults.
+func (m *memoryService) account(userID string) Account {
+    if account, ok := m.accounts[userID]; ok {
+        return account
+    }
+    return Account{UserID: userID, ProgressVisibility: VisibilityPrivate}
+}
+
+// withProblem fills in the catalog columns GetListItems joins in Postgres.
+func (m *memoryService) withProblem(item ListItem) ListItem {
+    p := m.problems[item.ProblemID]
+    item.ProblemTitle = p.Title
+    item.ProblemDifficulty = p.Difficulty
+    item.AcceptanceRate = p.AcceptanceRate
+    item.IsPremium = p.IsPremium
+    item.URL = p.URL
+    return item
+}
+
+func checkContext(ctx context.Context, op string) error {
+    if err := ctx.Err(); err != nil {
+        return &CanceledError{Op: op, Err: err}
+    }
+    return nil
+}
+
+// truncateToDate mirrors Postgres CURRENT_DATE: midnight UTC of t's date.
+func truncateToDate(t time.Time) time.Time {
+    y, mo, d := t.Date()
+    return time.Date(y, mo, d, 0, 0, 0, 0, time.UTC)
+}
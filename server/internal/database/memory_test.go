@@ -0,0 +1,9 @@
+package database
+
+import "testing"
+
+func TestMemoryContract(t *testing.T) {
+	runServiceContract(t, func(t *testing.T) Service {
+		return NewMemory()
+	})
+}
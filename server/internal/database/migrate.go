@@ -0,0 +1,266 @@
+package database
+
+import (
+    "context"
+    "database/sql"
+    "embed"
+    "fmt"
+    "io/fs"
+    "path"
+    "regexp"
+    "sort"
+    "strconv"
+    "time"
+
+    "LeetTracker/internal/config"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+// migrationName matches files such as 0002_list_items_unique.up.sql.
+var migrationName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is one numbered schema change with its rollback.
+type Migration struct {
+    Version int
+    Name    string
+    Up      string
+    Down    string
+}
+
+// MigrationStatus describes whether a known migration has been applied.
+type MigrationStatus struct {
+    Version   int
+    Name      string
+    AppliedAt *time.Time
+}
+
+// Migrator applies the migrations embedded in the binary and records them in
+// the schema_migrations table.
+type Migrator struct {
+    conn
+    migrations []Migration
+}
+
+// NewMigrator loads the migrations written for the configured driver.
+func NewMigrator(cfg config.Database) (*Migrator, error) {
+    d, err := dialectFor(cfg.Driver)
+    if err != nil {
+        return nil, err
+    }
+    migrations, err := loadMigrations(d.name)
+    if err != nil {
+        return nil, err
+    }
+    db, err := d.open(cfg)
+    if err != nil {
+        return nil, err
+    }
+    return &Migrator{conn: conn{db: db, dialect: d}, migrations: migrations}, nil
+}
+
+func (m *Migrator) Close() error {
+    return m.db.Close()
+}
+
+// ExpectedSchemaVersion is the version this binary was built against: the
+// highest embedded migration. Every dialect carries the same versions, so the
+// Postgres set stands in for all of them.
+func ExpectedSchemaVersion() int {
+    migrations, err := loadMigrations(postgresDialect.name)
+    if err != nil || len(migrations) == 0 {
+        return 0
+    }
+    return migrations[len(migrations)-1].Version
+}
+
+func loadMigrations(dialect string) ([]Migration, error) {
+    dir := path.Join("migrations", dialect)
+    entries, err := fs.ReadDir(migrationFiles, dir)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read migrations: %v", err)
+    }
+
+    byVersion := make(map[int]*Migration)
+    for _, entry := range entries {
+        match := migrationName.FindStringSubmatch(entry.Name())
+        if match == nil {
+            return nil, fmt.Errorf("unexpected file in migrations: %s", entry.Name())
+        }
+        version, _ := strconv.Atoi(match[1])
+        body, err := fs.ReadFile(migrationFiles, path.Join(dir, entry.Name()))
+        if err != nil {
+            return nil, fmt.Errorf("failed to read migration %s: %v", entry.Name(), err)
+        }
+
+        mig, ok := byVersion[version]
+        if !ok {
+            mig = &Migration{Version: version, Name: match[2]}
+            byVersion[version] = mig
+        } else if mig.Name != match[2] {
+            return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, mig.Name, match[2])
+        }
+        if match[3] == "up" {
+            mig.Up = string(body)
+        } else {
+            mig.Down = string(body)
+        }
+    }
+
+    migrations := make([]Migration, 0, len(byVersion))
+    for _, mig := range byVersion {
+        if mig.Up == "" || mig.Down == "" {
+            return nil, fmt.Errorf("migration %d (%s) needs both an up and a down file", mig.Version, mig.Name)
+        }
+        migrations = append(migrations, *mig)
+    }
+    sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+    for i, mig := range migrations {
+        if mig.Version != i+1 {
+            return nil, fmt.Errorf("migrations must be numbered consecutively from 1, found %d at position %d", mig.Version, i+1)
+        }
+    }
+    return migrations, nil
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+    _, err := m.exec(ctx, `
+        CREATE TABLE IF NOT EXISTS schema_migrations (
+            version INTEGER PRIMARY KEY,
+            name TEXT NOT NULL,
+            applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+        )
+    `)
+    if err != nil {
+        return fmt.Errorf("failed to create schema_migrations: %v", err)
+    }
+    return nil
+}
+
+// Version returns the highest applied migration, or 0 for a fresh database.
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+    return schemaVersion(ctx, m.conn)
+}
+
+func schemaVersion(ctx context.Context, c conn) (int, error) {
+    var exists bool
+    err := c.queryRow(ctx, c.dialect.tableExists, "schema_migrations").Scan(&exists)
+    if err != nil {
+        return 0, fmt.Errorf("failed to look up schema_migrations: %v", err)
+    }
+    if !exists {
+        return 0, nil
+    }
+    var version int
+    if err := c.queryRow(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version); err != nil {
+        return 0, fmt.Errorf("failed to read schema version: %v", err)
+    }
+    return version, nil
+}
+
+// Up applies every pending migration in order, each in its own transaction,
+// and returns the ones it applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+    if err := m.ensureTable(ctx); err != nil {
+        return nil, err
+    }
+    current, err := m.Version(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    var applied []Migration
+    for _, mig := range m.migrations {
+        if mig.Version <= current {
+            continue
+        }
+        err := m.inTx(ctx, func(tx *sql.Tx) error {
+            if _, err := tx.ExecContext(ctx, mig.Up); err != nil {
+                return err
+            }
+            _, err := tx.ExecContext(ctx, m.dialect.rebind("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)"), mig.Version, mig.Name)
+            return err
+        })
+        if err != nil {
+            return applied, fmt.Errorf("migration %d (%s) failed: %v", mig.Version, mig.Name, err)
+        }
+        applied = append(applied, mig)
+    }
+    return applied, nil
+}
+
+// Down rolls back the most recently applied migration. It returns nil if
+// nothing has been applied.
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+    current, err := m.Version(ctx)
+    if err != nil {
+        return nil, err
+    }
+    if current == 0 {
+        return nil, nil
+    }
+    if current > len(m.migrations) {
+        return nil, fmt.Errorf("database is at version %d, newer than this binary knows (%d)", current, len(m.migrations))
+    }
+
+    mig := m.migrations[current-1]
+    err = m.inTx(ctx, func(tx *sql.Tx) error {
+        if _, err := tx.ExecContext(ctx, mig.Down); err != nil {
+            return err
+        }
+        _, err := tx.ExecContext(ctx, m.dialect.rebind("DELETE FROM schema_migrations WHERE version = $1"), mig.Version)
+        return err
+    })
+    if err != nil {
+        return nil, fmt.Errorf("rollback of migration %d (%s) failed: %v", mig.Version, mig.Name, err)
+    }
+    return &mig, nil
+}
+
+// Status lists every embedded migration with the time it was applied, if any.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+    if err := m.ensureTable(ctx); err != nil {
+        return nil, err
+    }
+    rows, err := m.query(ctx, "SELECT version, applied_at FROM schema_migrations")
+    if err != nil {
+        return nil, fmt.Errorf("failed to read schema_migrations: %v", err)
+    }
+    defer rows.Close()
+
+    appliedAt := make(map[int]time.Time)
+    for rows.Next() {
+        var version int
+        var at time.Time
+        if err := rows.Scan(&version, &at); err != nil {
+            return nil, fmt.Errorf("failed to scan schema_migrations: %v", err)
+        }
+        appliedAt[version] = at
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating over schema_migrations: %v", err)
+    }
+
+    statuses := make([]MigrationStatus, 0, len(m.migrations))
+    for _, mig := range m.migrations {
+        status := MigrationStatus{Version: mig.Version, Name: mig.Name}
+        if at, ok := appliedAt[mig.Version]; ok {
+            status.AppliedAt = &at
+        }
+        statuses = append(statuses, status)
+    }
+    return statuses, nil
+}
+
+func (m *Migrator) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+    tx, err := m.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    if err := fn(tx); err != nil {
+        tx.Rollback()
+        return err
+    }
+    return tx.Commit()
+}
@@ -1,12 +1,17 @@
+-- Baseline schema, formerly db/init/DDL.sql. Uses IF NOT EXISTS so databases
+-- created from the old init script can adopt migrations without data loss.
 CREATE TABLE IF NOT EXISTS leetcode_problems (
     frontend_id INTEGER PRIMARY KEY,
     title TEXT NOT NULL,
     difficulty TEXT NOT NULL,
     acceptance_rate REAL,
     is_premium BOOLEAN,
-    url TEXT
+    url TEXT,
+    synced_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
 
+ALTER TABLE leetcode_problems ADD COLUMN IF NOT EXISTS synced_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
 CREATE TABLE IF NOT EXISTS users (
     id TEXT PRIMARY KEY,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
@@ -35,7 +40,7 @@ CREATE TABLE IF NOT EXISTS list_items (
     FOREIGN KEY (problem_id) REFERENCES leetcode_problems(frontend_id)
 );
 
-CREATE TABLE user_progress (
+CREATE TABLE IF NOT EXISTS user_progress (
     id SERIAL PRIMARY KEY,
     username VARCHAR(255) NOT NULL,
     date DATE NOT NULL,
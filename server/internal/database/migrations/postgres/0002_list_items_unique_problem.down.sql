@@ -0,0 +1 @@
+ALTER TABLE list_items DROP CONSTRAINT IF EXISTS list_items_list_id_problem_id_key;
@@ -0,0 +1,10 @@
+-- AddProblemsToList relies on ON CONFLICT (list_id, problem_id), which needs a
+-- matching unique constraint. Drop any duplicates that slipped in without it.
+DELETE FROM list_items a
+USING list_items b
+WHERE a.list_id = b.list_id
+  AND a.problem_id = b.problem_id
+  AND a.id > b.id;
+
+ALTER TABLE list_items
+    ADD CONSTRAINT list_items_list_id_problem_id_key UNIQUE (list_id, problem_id);
@@ -0,0 +1,12 @@
+-- One row per user per problem; resubmitting feedback updates it in place.
+CREATE TABLE problem_feedback (
+    id SERIAL PRIMARY KEY,
+    problem_id INTEGER NOT NULL REFERENCES leetcode_problems(frontend_id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    difficulty_rating INTEGER NOT NULL CHECK (difficulty_rating BETWEEN 1 AND 5),
+    interview_likelihood INTEGER CHECK (interview_likelihood BETWEEN 1 AND 5),
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (problem_id, user_id)
+);
@@ -0,0 +1,6 @@
+-- An account can claim one LeetCode username. progress_visibility decides who
+-- else may read the progress history recorded for that username.
+ALTER TABLE users ADD COLUMN leetcode_username TEXT;
+ALTER TABLE users ADD COLUMN progress_visibility TEXT NOT NULL DEFAULT 'private'
+    CHECK (progress_visibility IN ('private', 'team', 'public'));
+CREATE UNIQUE INDEX users_leetcode_username_key ON users (leetcode_username);
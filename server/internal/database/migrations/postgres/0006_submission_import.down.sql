@@ -0,0 +1,3 @@
+ALTER TABLE list_items DROP COLUMN completed_at;
+DROP INDEX IF EXISTS leetcode_problems_title_slug_idx;
+ALTER TABLE leetcode_problems DROP COLUMN title_slug;
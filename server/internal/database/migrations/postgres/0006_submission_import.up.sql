@@ -0,0 +1,10 @@
+-- Accepted submissions are matched to the catalog by slug, and importing them
+-- records when a list item was actually solved. Existing rows get their slug
+-- back from the problem URL, which has always been built from it.
+ALTER TABLE leetcode_problems ADD COLUMN title_slug TEXT;
+UPDATE leetcode_problems
+SET title_slug = rtrim(replace(url, 'https://leetcode.com/problems/', ''), '/')
+WHERE url LIKE 'https://leetcode.com/problems/%';
+CREATE INDEX leetcode_problems_title_slug_idx ON leetcode_problems (title_slug);
+
+ALTER TABLE list_items ADD COLUMN completed_at TIMESTAMP;
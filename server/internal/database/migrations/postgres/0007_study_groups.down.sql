@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS lists_group_id_idx;
+ALTER TABLE lists DROP COLUMN IF EXISTS group_id;
+DROP TABLE IF EXISTS study_group_members;
+DROP TABLE IF EXISTS study_groups;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS leaderboard_participants;
+DROP TABLE IF EXISTS leaderboards;
@@ -0,0 +1,20 @@
+ALTER TABLE list_items ADD COLUMN completed BOOLEAN DEFAULT FALSE;
+ALTER TABLE list_items ADD COLUMN completed_at TIMESTAMP;
+
+-- Only the list creator's ticks fit back into a single state per item.
+UPDATE list_items
+SET completed = TRUE, completed_at = (
+    SELECT c.completed_at
+    FROM list_item_completions c
+    JOIN lists l ON l.id = list_items.list_id
+    WHERE c.list_item_id = list_items.id AND c.user_id = l.user_id
+)
+WHERE EXISTS (
+    SELECT 1
+    FROM list_item_completions c
+    JOIN lists l ON l.id = list_items.list_id
+    WHERE c.list_item_id = list_items.id AND c.user_id = l.user_id
+);
+
+DROP TABLE IF EXISTS list_item_completions;
+DROP TABLE IF EXISTS list_members;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS study_plan_assignments;
+DROP TABLE IF EXISTS study_plans;
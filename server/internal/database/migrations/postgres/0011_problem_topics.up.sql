@@ -0,0 +1,9 @@
+-- LeetCode's topic tags for each catalog problem, keyed by the problem's
+-- frontend ID with one row per topic slug. A sync replaces a problem's
+-- topics whenever it fetched them.
+CREATE TABLE problem_topics (
+    problem_id INTEGER NOT NULL REFERENCES leetcode_problems(frontend_id) ON DELETE CASCADE,
+    topic TEXT NOT NULL,
+    PRIMARY KEY (problem_id, topic)
+);
+CREATE INDEX problem_topics_topic_idx ON problem_topics (topic);
@@ -0,0 +1,23 @@
+-- A mock interview is a timed session over a few problems picked at random.
+-- A session without ended_at is over once expires_at has passed.
+CREATE TABLE interview_sessions (
+    id SERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    list_id INTEGER REFERENCES lists(id) ON DELETE SET NULL,
+    time_limit_minutes INTEGER NOT NULL CHECK (time_limit_minutes > 0),
+    started_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP
+);
+CREATE INDEX interview_sessions_user_id_idx ON interview_sessions (user_id, started_at);
+
+CREATE TABLE interview_problems (
+    session_id INTEGER NOT NULL REFERENCES interview_sessions(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    problem_id INTEGER NOT NULL REFERENCES leetcode_problems(frontend_id),
+    outcome TEXT CHECK (outcome IN ('solved', 'partial', 'failed', 'skipped')),
+    elapsed_seconds INTEGER CHECK (elapsed_seconds >= 0),
+    recorded_at TIMESTAMP,
+    PRIMARY KEY (session_id, position),
+    UNIQUE (session_id, problem_id)
+);
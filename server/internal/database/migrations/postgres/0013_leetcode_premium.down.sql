@@ -0,0 +1 @@
+ALTER TABLE users DROP COLUMN leetcode_premium;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS daily_problem_completions;
+DROP TABLE IF EXISTS daily_problems;
+DROP TABLE IF EXISTS daily_problem_settings;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS user_progress;
+DROP TABLE IF EXISTS list_items;
+DROP TABLE IF EXISTS lists;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS leetcode_problems;
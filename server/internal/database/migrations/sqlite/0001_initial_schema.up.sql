@@ -0,0 +1,50 @@
+-- SQLite translation of the Postgres baseline. SERIAL becomes an INTEGER
+-- PRIMARY KEY, which SQLite assigns automatically.
+CREATE TABLE IF NOT EXISTS leetcode_problems (
+    frontend_id INTEGER PRIMARY KEY,
+    title TEXT NOT NULL,
+    difficulty TEXT NOT NULL,
+    acceptance_rate REAL,
+    is_premium BOOLEAN,
+    url TEXT,
+    synced_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS lists (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT,
+    tags TEXT,
+    difficulty TEXT,
+    estimated_time TEXT,
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE TABLE IF NOT EXISTS list_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    list_id INTEGER NOT NULL,
+    problem_id INTEGER NOT NULL,
+    added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed BOOLEAN DEFAULT FALSE,
+    FOREIGN KEY (list_id) REFERENCES lists(id) ON DELETE CASCADE,
+    FOREIGN KEY (problem_id) REFERENCES leetcode_problems(frontend_id)
+);
+
+CREATE TABLE IF NOT EXISTS user_progress (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username TEXT NOT NULL,
+    date DATE NOT NULL,
+    total_solved INTEGER NOT NULL,
+    easy_solved INTEGER NOT NULL,
+    medium_solved INTEGER NOT NULL,
+    hard_solved INTEGER NOT NULL,
+    UNIQUE(username, date)
+);
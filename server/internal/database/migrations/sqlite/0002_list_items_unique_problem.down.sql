@@ -0,0 +1 @@
+DROP INDEX IF EXISTS list_items_list_id_problem_id_key;
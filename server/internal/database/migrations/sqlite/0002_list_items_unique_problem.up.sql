@@ -0,0 +1,9 @@
+-- SQLite cannot add a table constraint after creation; a unique index
+-- satisfies ON CONFLICT (list_id, problem_id) just the same.
+DELETE FROM list_items
+WHERE id NOT IN (
+    SELECT MIN(id) FROM list_items GROUP BY list_id, problem_id
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS list_items_list_id_problem_id_key
+    ON list_items (list_id, problem_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS users_leetcode_username_key;
+ALTER TABLE users DROP COLUMN progress_visibility;
+ALTER TABLE users DROP COLUMN leetcode_username;
@@ -0,0 +1,13 @@
+-- Log of background and manual progress polls. username is NULL for runs that
+-- covered every linked account. finished_at stays NULL while a run is in
+-- progress, or if the process stopped before it completed.
+CREATE TABLE poll_runs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind TEXT NOT NULL CHECK (kind IN ('scheduled', 'manual')),
+    username TEXT,
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP,
+    polled INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT ''
+);
@@ -0,0 +1,22 @@
+-- Study groups own lists that every member can see. Members join with the
+-- group's invite code; the owner can rotate it to cut off old invitations.
+CREATE TABLE study_groups (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    invite_code TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE study_group_members (
+    group_id INTEGER NOT NULL REFERENCES study_groups(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('owner', 'admin', 'member')),
+    joined_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (group_id, user_id)
+);
+CREATE INDEX study_group_members_user_id_idx ON study_group_members (user_id);
+
+-- A list with a group_id belongs to that group; user_id records who created it.
+ALTER TABLE lists ADD COLUMN group_id INTEGER REFERENCES study_groups(id) ON DELETE CASCADE;
+CREATE INDEX lists_group_id_idx ON lists (group_id);
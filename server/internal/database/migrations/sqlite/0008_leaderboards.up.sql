@@ -0,0 +1,23 @@
+-- A leaderboard ranks a fixed set of LeetCode usernames by what they solved
+-- in a window. version is bumped on every change so cached standings can be
+-- keyed on it.
+CREATE TABLE leaderboards (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    owner_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    easy_weight INTEGER NOT NULL DEFAULT 1,
+    medium_weight INTEGER NOT NULL DEFAULT 1,
+    hard_weight INTEGER NOT NULL DEFAULT 1,
+    tie_break TEXT NOT NULL DEFAULT 'hardest' CHECK (tie_break IN ('hardest', 'total', 'shared')),
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX leaderboards_owner_id_idx ON leaderboards (owner_id);
+
+CREATE TABLE leaderboard_participants (
+    leaderboard_id INTEGER NOT NULL REFERENCES leaderboards(id) ON DELETE CASCADE,
+    username TEXT NOT NULL,
+    added_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (leaderboard_id, username)
+);
+CREATE INDEX leaderboard_participants_username_idx ON leaderboard_participants (username);
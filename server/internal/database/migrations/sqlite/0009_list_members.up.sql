@@ -0,0 +1,30 @@
+-- A personal list can be shared with editors, who change its items, and
+-- viewers, who only read them. Completion moves out of list_items so that
+-- everyone who can see a list ticks its items off for themselves.
+CREATE TABLE list_members (
+    list_id INTEGER NOT NULL REFERENCES lists(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('editor', 'viewer')),
+    added_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (list_id, user_id)
+);
+CREATE INDEX list_members_user_id_idx ON list_members (user_id);
+
+-- completed_at is NULL for items ticked before solve times were recorded.
+CREATE TABLE list_item_completions (
+    list_item_id INTEGER NOT NULL REFERENCES list_items(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    completed_at TIMESTAMP,
+    PRIMARY KEY (list_item_id, user_id)
+);
+CREATE INDEX list_item_completions_user_id_idx ON list_item_completions (user_id);
+
+-- Existing ticks are credited to whoever created the list.
+INSERT INTO list_item_completions (list_item_id, user_id, completed_at)
+SELECT li.id, l.user_id, li.completed_at
+FROM list_items li
+JOIN lists l ON l.id = li.list_id
+WHERE li.completed;
+
+ALTER TABLE list_items DROP COLUMN completed;
+ALTER TABLE list_items DROP COLUMN completed_at;
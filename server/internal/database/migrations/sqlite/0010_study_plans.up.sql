@@ -0,0 +1,23 @@
+-- A study plan spreads the items of a list over dated study days for one
+-- user. Items keep their assignment when completed; rebalancing moves only
+-- the unfinished ones.
+CREATE TABLE study_plans (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    list_id INTEGER NOT NULL REFERENCES lists(id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    days_per_week INTEGER NOT NULL CHECK (days_per_week BETWEEN 1 AND 7),
+    problems_per_day INTEGER NOT NULL CHECK (problems_per_day > 0),
+    rebalanced_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX study_plans_user_id_idx ON study_plans (user_id);
+CREATE INDEX study_plans_list_id_idx ON study_plans (list_id);
+
+CREATE TABLE study_plan_assignments (
+    plan_id INTEGER NOT NULL REFERENCES study_plans(id) ON DELETE CASCADE,
+    list_item_id INTEGER NOT NULL REFERENCES list_items(id) ON DELETE CASCADE,
+    due_date DATE NOT NULL,
+    PRIMARY KEY (plan_id, list_item_id)
+);
+CREATE INDEX study_plan_assignments_list_item_id_idx ON study_plan_assignments (list_item_id);
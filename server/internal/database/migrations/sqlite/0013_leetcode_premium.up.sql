@@ -0,0 +1,3 @@
+-- Whether the user has LeetCode Premium, so recommendations can include
+-- premium problems.
+ALTER TABLE users ADD COLUMN leetcode_premium BOOLEAN NOT NULL DEFAULT FALSE;
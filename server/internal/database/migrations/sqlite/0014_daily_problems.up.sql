@@ -0,0 +1,29 @@
+-- A list's problem of the day. The settings say where picks come from;
+-- difficulties and topics are comma separated and empty when unfiltered.
+CREATE TABLE daily_problem_settings (
+    list_id INTEGER PRIMARY KEY REFERENCES lists(id) ON DELETE CASCADE,
+    source TEXT NOT NULL CHECK (source IN ('list', 'catalog')),
+    difficulties TEXT NOT NULL DEFAULT '',
+    topics TEXT NOT NULL DEFAULT '',
+    exclude_premium BOOLEAN NOT NULL DEFAULT FALSE,
+    no_repeat_days INTEGER NOT NULL CHECK (no_repeat_days >= 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A day's pick is stored the first time it is asked for, so it stays the
+-- same for everyone even if the list or settings change later that day.
+CREATE TABLE daily_problems (
+    list_id INTEGER NOT NULL REFERENCES lists(id) ON DELETE CASCADE,
+    pick_date DATE NOT NULL,
+    problem_id INTEGER NOT NULL REFERENCES leetcode_problems(frontend_id) ON DELETE CASCADE,
+    PRIMARY KEY (list_id, pick_date)
+);
+
+CREATE TABLE daily_problem_completions (
+    list_id INTEGER NOT NULL,
+    pick_date DATE NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    completed_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (list_id, pick_date, user_id),
+    FOREIGN KEY (list_id, pick_date) REFERENCES daily_problems(list_id, pick_date) ON DELETE CASCADE
+);
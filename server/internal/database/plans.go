@@ -0,0 +1,200 @@
+package database
+
+import (
+    "context"
+    "time"
+)
+
+type StudyPlan struct {
+    ID             int        `json:"id"`
+    UserID         string     `json:"-"`
+    ListID         int        `json:"list_id"`
+    ListName       string     `json:"list_name"`
+    StartDate      time.Time  `json:"start_date"`
+    DaysPerWeek    int        `json:"days_per_week"`
+    ProblemsPerDay int        `json:"problems_per_day"`
+    RebalancedAt   *time.Time `json:"rebalanced_at"`
+    CreatedAt      time.Time  `json:"created_at"`
+}
+
+// Assignment schedules one list item on a study plan. Completed and
+// CompletedAt are the plan owner's own completion state for the item.
+type Assignment struct {
+    ListItemID        int        `json:"list_item_id"`
+    ProblemID         int        `json:"problem_id"`
+    ProblemTitle      string     `json:"problem_title"`
+    ProblemDifficulty string     `json:"problem_difficulty"`
+    URL               string     `json:"url"`
+    DueDate           time.Time  `json:"due_date"`
+    Completed         bool       `json:"completed"`
+    CompletedAt       *time.Time `json:"completed_at"`
+}
+
+// CreateStudyPlan stores plan for plan.UserID with its schedule and fills in
+// the generated fields. Only ListItemID and DueDate are read from schedule.
+func (s *service) CreateStudyPlan(ctx context.Context, plan *StudyPlan, schedule []Assignment) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    tx, err := s.db.BeginTx(ctx, nil)
+    if err != nil {
+        return wrapError(ctx, err, "failed to begin transaction")
+    }
+    defer tx.Rollback()
+
+    err = tx.QueryRowContext(ctx, s.dialect.rebind(`
+        INSERT INTO study_plans (user_id, list_id, start_date, days_per_week, problems_per_day)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, created_at
+    `), plan.UserID, plan.ListID, plan.StartDate.Format(dateLayout), plan.DaysPerWeek, plan.ProblemsPerDay).Scan(&plan.ID, &plan.CreatedAt)
+    if err != nil {
+        return wrapError(ctx, err, "failed to create study plan")
+    }
+    for _, a := range schedule {
+        _, err := tx.ExecContext(ctx, s.dialect.rebind(`
+            INSERT INTO study_plan_assignments (plan_id, list_item_id, due_date) VALUES ($1, $2, $3)
+        `), plan.ID, a.ListItemID, a.DueDate.Format(dateLayout))
+        if err != nil {
+            return wrapError(ctx, err, "failed to schedule study plan item")
+        }
+    }
+    if err := tx.Commit(); err != nil {
+        return wrapError(ctx, err, "failed to commit transaction")
+    }
+    return nil
+}
+
+// GetStudyPlan returns planID if userID created it and can still see its
+// list.
+func (s *service) GetStudyPlan(ctx context.Context, planID int, userID string) (*StudyPlan, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    plans, err := s.studyPlans(ctx, "p.id = $1 AND p.user_id = $2", planID, userID)
+    if err != nil {
+        return nil, err
+    }
+    if len(plans) == 0 {
+        return nil, notFoundError("study plan %d not found", planID)
+    }
+    return &plans[0], nil
+}
+
+func (s *service) GetUserStudyPlans(ctx context.Context, userID string) ([]StudyPlan, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    return s.studyPlans(ctx, "p.user_id = $1", userID)
+}
+
+// studyPlans loads the plans matching where, newest first, leaving out plans
+// on lists their creator can no longer see.
+func (s *service) studyPlans(ctx context.Context, where string, args ...interface{}) ([]StudyPlan, error) {
+    rows, err := s.query(ctx, `
+        SELECT p.id, p.user_id, p.list_id, l.name, p.start_date, p.days_per_week, p.problems_per_day, p.rebalanced_at, p.created_at
+        FROM study_plans p
+        JOIN lists l ON l.id = p.list_id
+        WHERE `+where+` AND `+listVisibleTo("p.user_id")+`
+        ORDER BY p.created_at DESC, p.id DESC
+    `, args...)
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to fetch study plans")
+    }
+    defer rows.Close()
+
+    var plans []StudyPlan
+    for rows.Next() {
+        var plan StudyPlan
+        err := rows.Scan(&plan.ID, &plan.UserID, &plan.ListID, &plan.ListName, &plan.StartDate, &plan.DaysPerWeek, &plan.ProblemsPerDay, &plan.RebalancedAt, &plan.CreatedAt)
+        if err != nil {
+            return nil, wrapError(ctx, err, "failed to scan study plan")
+        }
+        plans = append(plans, plan)
+    }
+    if err = rows.Err(); err != nil {
+        return nil, wrapError(ctx, err, "error iterating over study plans")
+    }
+    return plans, nil
+}
+
+// GetStudyPlanSchedule returns planID's assignments by due date, then in
+// list order.
+func (s *service) GetStudyPlanSchedule(ctx context.Context, planID int) ([]Assignment, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    rows, err := s.query(ctx, `
+        SELECT a.list_item_id, li.problem_id, lp.title, lp.difficulty, lp.url, a.due_date, c.list_item_id IS NOT NULL, c.completed_at
+        FROM study_plan_assignments a
+        JOIN study_plans p ON p.id = a.plan_id
+        JOIN list_items li ON li.id = a.list_item_id
+        JOIN leetcode_problems lp ON lp.frontend_id = li.problem_id
+        LEFT JOIN list_item_completions c ON c.list_item_id = a.list_item_id AND c.user_id = p.user_id
+        WHERE a.plan_id = $1
+        ORDER BY a.due_date, a.list_item_id
+    `, planID)
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to fetch study plan schedule")
+    }
+    defer rows.Close()
+
+    schedule := []Assignment{}
+    for rows.Next() {
+        var a Assignment
+        err := rows.Scan(&a.ListItemID, &a.ProblemID, &a.ProblemTitle, &a.ProblemDifficulty, &a.URL, &a.DueDate, &a.Completed, &a.CompletedAt)
+        if err != nil {
+            return nil, wrapError(ctx, err, "failed to scan study plan assignment")
+        }
+        schedule = append(schedule, a)
+    }
+    if err = rows.Err(); err != nil {
+        return nil, wrapError(ctx, err, "error iterating over study plan schedule")
+    }
+    return schedule, nil
+}
+
+// RescheduleStudyPlan moves the items in schedule to their new due dates,
+// adding any the plan did not have yet, and records when the plan was
+// rebalanced. Assignments left out of schedule keep their dates.
+func (s *service) RescheduleStudyPlan(ctx context.Context, planID int, schedule []Assignment) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    tx, err := s.db.BeginTx(ctx, nil)
+    if err != nil {
+        return wrapError(ctx, err, "failed to begin transaction")
+    }
+    defer tx.Rollback()
+
+    result, err := tx.ExecContext(ctx, s.dialect.rebind("UPDATE study_plans SET rebalanced_at = CURRENT_TIMESTAMP WHERE id = $1"), planID)
+    if err != nil {
+        return wrapError(ctx, err, "failed to update study plan")
+    }
+    if err := expectRow(ctx, result, "study plan %d not found", planID); err != nil {
+        return err
+    }
+    for _, a := range schedule {
+        _, err := tx.ExecContext(ctx, s.dialect.rebind(`
+            INSERT INTO study_plan_assignments (plan_id, list_item_id, due_date) VALUES ($1, $2, $3)
+            ON CONFLICT (plan_id, list_item_id) DO UPDATE SET due_date = EXCLUDED.due_date
+        `), planID, a.ListItemID, a.DueDate.Format(dateLayout))
+        if err != nil {
+            return wrapError(ctx, err, "failed to reschedule study plan item")
+        }
+    }
+    if err := tx.Commit(); err != nil {
+        return wrapError(ctx, err, "failed to commit transaction")
+    }
+    return nil
+}
+
+func (s *service) DeleteStudyPlan(ctx context.Context, planID int, userID string) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    result, err := s.exec(ctx, "DELETE FROM study_plans WHERE id = $1 AND user_id = $2", planID, userID)
+    if err != nil {
+        return wrapError(ctx, err, "failed to delete study plan")
+    }
+    return expectRow(ctx, result, "study plan %d not found", planID)
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+    "context"
+    "database/sql"
+    "time"
+)
+
+// Kinds of poll run.
+const (
+    PollScheduled = "scheduled"
+    PollManual    = "manual"
+)
+
+// PollRun is one entry in the poller's run log. Username is nil for runs
+// that covered every linked account, and FinishedAt is nil until the run ends.
+type PollRun struct {
+    ID         int        `json:"id"`
+    Kind       string     `json:"kind"`
+    Username   *string    `json:"username"`
+    StartedAt  time.Time  `json:"started_at"`
+    FinishedAt *time.Time `json:"finished_at"`
+    Polled     int        `json:"polled"`
+    Failed     int        `json:"failed"`
+    Error      string     `json:"error"`
+}
+
+// GetTrackedLeetCodeUsernames returns every LeetCode username that needs a
+// daily snapshot, in alphabetical order: the linked ones and those on a
+// leaderboard.
+func (s *service) GetTrackedLeetCodeUsernames(ctx context.Context) ([]string, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    rows, err := s.query(ctx, `
+        SELECT leetcode_username FROM users WHERE leetcode_username IS NOT NULL
+        UNION
+        SELECT username FROM leaderboard_participants
+        ORDER BY 1
+    `)
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to fetch linked usernames")
+    }
+    defer rows.Close()
+
+    var usernames []string
+    for rows.Next() {
+        var username string
+        if err := rows.Scan(&username); err != nil {
+            return nil, wrapError(ctx, err, "failed to scan linked username")
+        }
+        usernames = append(usernames, username)
+    }
+    if err = rows.Err(); err != nil {
+        return nil, wrapError(ctx, err, "error iterating over linked usernames")
+    }
+    return usernames, nil
+}
+
+// StartPollRun records the start of a run and fills in its ID and start time.
+func (s *service) StartPollRun(ctx context.Context, run *PollRun) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    err := s.queryRow(ctx, `
+        INSERT INTO poll_runs (kind, username)
+        VALUES ($1, $2)
+        RETURNING id, started_at
+    `, run.Kind, run.Username).Scan(&run.ID, &run.StartedAt)
+    if err != nil {
+        return wrapError(ctx, err, "failed to record poll run")
+    }
+    return nil
+}
+
+// FinishPollRun stores the run's results and fills in its finish time.
+func (s *service) FinishPollRun(ctx context.Context, run *PollRun) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    var finishedAt time.Time
+    err := s.queryRow(ctx, `
+        UPDATE poll_runs SET finished_at = CURRENT_TIMESTAMP, polled = $1, failed = $2, error = $3
+        WHERE id = $4
+        RETURNING finished_at
+    `, run.Polled, run.Failed, run.Error, run.ID).Scan(&finishedAt)
+    if err != nil {
+        if err == sql.ErrNoRows {
+            return notFoundError("poll run %d not found", run.ID)
+        }
+        return wrapError(ctx, err, "failed to record poll run")
+    }
+    run.FinishedAt = &finishedAt
+    return nil
+}
+
+// GetPollRuns returns up to limit runs, most recent first.
+func (s *service) GetPollRuns(ctx context.Context, limit int) ([]PollRun, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    rows, err := s.query(ctx, `
+        SELECT id, kind, username, started_at, finished_at, polled, failed, error
+        FROM poll_runs
+        ORDER BY id DESC
+        LIMIT $1
+    `, limit)
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to fetch poll runs")
+    }
+    defer rows.Close()
+
+    var runs []PollRun
+    for rows.Next() {
+        var run PollRun
+        err := rows.Scan(&run.ID, &run.Kind, &run.Username, &run.StartedAt, &run.FinishedAt, &run.Polled, &run.Failed, &run.Error)
+        if err != nil {
+            return nil, wrapError(ctx, err, "failed to scan poll run")
+        }
+        runs = append(runs, run)
+    }
+    if err = rows.Err(); err != nil {
+        return nil, wrapError(ctx, err, "error iterating over poll runs")
+    }
+    return runs, nil
+}
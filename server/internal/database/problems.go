@@ -0,0 +1,183 @@
+package database
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+)
+
+// ProblemFilter selects problems from the catalog. Zero fields match every
+// problem.
+type ProblemFilter struct {
+    // ListID limits the problems to the items of one list.
+    ListID         *int
+    // Difficulties are matched without regard to case.
+    Difficulties   []string
+    // Topics matches problems with any of these topic slugs.
+    Topics         []string
+    ExcludePremium bool
+    // ExcludeSolvedBy leaves out the problems this user has ticked off on
+    // any list or solved in a mock interview.
+    ExcludeSolvedBy string
+}
+
+// FilterProblems returns the frontend IDs of the problems matching filter,
+// in ascending order.
+func (s *service) FilterProblems(ctx context.Context, filter ProblemFilter) ([]int, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    var conditions []string
+    var args []interface{}
+    bind := func(value interface{}) string {
+        args = append(args, value)
+        return fmt.Sprintf("$%d", len(args))
+    }
+    bindAll := func(values []string, transform func(string) string) string {
+        params := make([]string, len(values))
+        for i, value := range values {
+            params[i] = bind(transform(value))
+        }
+        return strings.Join(params, ", ")
+    }
+
+    if filter.ListID != nil {
+        conditions = append(conditions, "lp.frontend_id IN (SELECT problem_id FROM list_items WHERE list_id = "+bind(*filter.ListID)+")")
+    }
+    if len(filter.Difficulties) > 0 {
+        conditions = append(conditions, "LOWER(lp.difficulty) IN ("+bindAll(filter.Difficulties, strings.ToLower)+")")
+    }
+    if len(filter.Topics) > 0 {
+        conditions = append(conditions, "EXISTS(SELECT 1 FROM problem_topics t WHERE t.problem_id = lp.frontend_id AND t.topic IN ("+bindAll(filter.Topics, strings.TrimSpace)+"))")
+    }
+    if filter.ExcludePremium {
+        conditions = append(conditions, "NOT COALESCE(lp.is_premium, FALSE)")
+    }
+    if filter.ExcludeSolvedBy != "" {
+        user := bind(filter.ExcludeSolvedBy)
+        conditions = append(conditions, `lp.frontend_id NOT IN (
+            SELECT li.problem_id FROM list_item_completions c JOIN list_items li ON li.id = c.list_item_id WHERE c.user_id = `+user+`
+            UNION
+            SELECT p.problem_id FROM interview_problems p JOIN interview_sessions i ON i.id = p.session_id
+            WHERE i.user_id = `+user+` AND p.outcome = 'solved'
+        )`)
+    }
+    where := "TRUE"
+    if len(conditions) > 0 {
+        where = strings.Join(conditions, " AND ")
+    }
+
+    rows, err := s.query(ctx, "SELECT lp.frontend_id FROM leetcode_problems lp WHERE "+where+" ORDER BY lp.frontend_id", args...)
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to filter problems")
+    }
+    defer rows.Close()
+
+    ids := []int{}
+    for rows.Next() {
+        var id int
+        if err := rows.Scan(&id); err != nil {
+            return nil, wrapError(ctx, err, "failed to scan problem")
+        }
+        ids = append(ids, id)
+    }
+    if err = rows.Err(); err != nil {
+        return nil, wrapError(ctx, err, "error iterating over problems")
+    }
+    return ids, nil
+}
+
+// ProblemActivity is what one user has done with one problem.
+type ProblemActivity struct {
+    ProblemID   int
+    // OnList is set if the problem is on a list the user can see.
+    OnList      bool
+    // CompletedAt is when the user last ticked the problem on any list.
+    CompletedAt *time.Time
+    // Outcome and OutcomeAt are the user's latest mock interview result for
+    // the problem.
+    Outcome     *string
+    OutcomeAt   *time.Time
+}
+
+// GetProblemActivity returns userID's activity for every problem they have
+// on a list they can see, have ticked off, or have an interview outcome for,
+// in problem order.
+func (s *service) GetProblemActivity(ctx context.Context, userID string) ([]ProblemActivity, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    activity := make(map[int]*ProblemActivity)
+    get := func(problemID int) *ProblemActivity {
+        if activity[problemID] == nil {
+            activity[problemID] = &ProblemActivity{ProblemID: problemID}
+        }
+        return activity[problemID]
+    }
+
+    rows, err := s.query(ctx, `
+        SELECT li.problem_id, `+listVisibleTo("$1")+`, c.completed_at
+        FROM list_items li
+        JOIN lists l ON l.id = li.list_id
+        LEFT JOIN list_item_completions c ON c.list_item_id = li.id AND c.user_id = $1
+        WHERE c.user_id IS NOT NULL OR `+listVisibleTo("$1")+`
+    `, userID)
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to fetch problem activity")
+    }
+    defer rows.Close()
+    for rows.Next() {
+        var problemID int
+        var onList bool
+        var completedAt *time.Time
+        if err := rows.Scan(&problemID, &onList, &completedAt); err != nil {
+            return nil, wrapError(ctx, err, "failed to scan problem activity")
+        }
+        a := get(problemID)
+        a.OnList = a.OnList || onList
+        if completedAt != nil && (a.CompletedAt == nil || completedAt.After(*a.CompletedAt)) {
+            a.CompletedAt = completedAt
+        }
+    }
+    if err = rows.Err(); err != nil {
+        return nil, wrapError(ctx, err, "error iterating over problem activity")
+    }
+    rows.Close()
+
+    rows, err = s.query(ctx, `
+        SELECT p.problem_id, p.outcome, p.recorded_at
+        FROM interview_problems p
+        JOIN interview_sessions i ON i.id = p.session_id
+        WHERE i.user_id = $1 AND p.outcome IS NOT NULL
+        ORDER BY p.recorded_at
+    `, userID)
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to fetch problem activity")
+    }
+    defer rows.Close()
+    for rows.Next() {
+        var problemID int
+        var outcome string
+        var recordedAt time.Time
+        if err := rows.Scan(&problemID, &outcome, &recordedAt); err != nil {
+            return nil, wrapError(ctx, err, "failed to scan problem activity")
+        }
+        a := get(problemID)
+        a.Outcome, a.OutcomeAt = &outcome, &recordedAt
+    }
+    if err = rows.Err(); err != nil {
+        return nil, wrapError(ctx, err, "error iterating over problem activity")
+    }
+    return sortActivity(activity), nil
+}
+
+func sortActivity(activity map[int]*ProblemActivity) []ProblemActivity {
+    sorted := make([]ProblemActivity, 0, len(activity))
+    for _, a := range activity {
+        sorted = append(sorted, *a)
+    }
+    sort.Slice(sorted, func(i, j int) bool { return sorted[i].ProblemID < sorted[j].ProblemID })
+    return sorted
+}
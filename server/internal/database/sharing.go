@@ -0,0 +1,109 @@
+package database
+
+import (
+    "context"
+    "database/sql"
+    "time"
+)
+
+// List roles for collaborators on a personal list. Editors can change the
+// items and viewers can only read them; everyone keeps their own completion
+// state. The list's creator is reported with RoleOwner.
+const (
+    ListEditor = "editor"
+    ListViewer = "viewer"
+)
+
+type ListMember struct {
+    UserID           string    `json:"user_id"`
+    LeetCodeUsername *string   `json:"leetcode_username"`
+    Role             string    `json:"role"`
+    AddedAt          time.Time `json:"added_at"`
+}
+
+// GetListMembers returns the owner of a personal list followed by its
+// editors and viewers, each in the order they were added. Group lists have
+// no members of their own.
+func (s *service) GetListMembers(ctx context.Context, listID int) ([]ListMember, error) {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    rows, err := s.query(ctx, `
+        SELECT l.user_id, u.leetcode_username, 'owner', l.created_at, 0
+        FROM lists l
+        JOIN users u ON u.id = l.user_id
+        WHERE l.id = $1 AND l.group_id IS NULL
+        UNION ALL
+        SELECT m.user_id, u.leetcode_username, m.role, m.added_at, CASE m.role WHEN 'editor' THEN 1 ELSE 2 END
+        FROM list_members m
+        JOIN users u ON u.id = m.user_id
+        WHERE m.list_id = $1
+        ORDER BY 5, 4, 1
+    `, listID)
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to fetch list members")
+    }
+    defer rows.Close()
+
+    var members []ListMember
+    for rows.Next() {
+        var member ListMember
+        var rank int
+        if err := rows.Scan(&member.UserID, &member.LeetCodeUsername, &member.Role, &member.AddedAt, &rank); err != nil {
+            return nil, wrapError(ctx, err, "failed to scan list member")
+        }
+        members = append(members, member)
+    }
+    if err = rows.Err(); err != nil {
+        return nil, wrapError(ctx, err, "error iterating over list members")
+    }
+    return members, nil
+}
+
+// SetListMember shares a personal list with userID, or changes the role
+// they already have on it. Group lists are shared through their group.
+func (s *service) SetListMember(ctx context.Context, listID int, userID, role string) error {
+    if role != ListEditor && role != ListViewer {
+        return validationError("role must be %s or %s", ListEditor, ListViewer)
+    }
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    var ownerID string
+    var groupID *int
+    err := s.queryRow(ctx, "SELECT user_id, group_id FROM lists WHERE id = $1", listID).Scan(&ownerID, &groupID)
+    if err == sql.ErrNoRows {
+        return notFoundError("list %d not found", listID)
+    }
+    if err != nil {
+        return wrapError(ctx, err, "failed to look up list")
+    }
+    if groupID != nil {
+        return validationError("group lists are shared with the group's members")
+    }
+    if ownerID == userID {
+        return forbiddenError("the list owner cannot be changed or removed")
+    }
+
+    _, err = s.exec(ctx, `
+        INSERT INTO list_members (list_id, user_id, role) VALUES ($1, $2, $3)
+        ON CONFLICT (list_id, user_id) DO UPDATE SET role = EXCLUDED.role
+    `, listID, userID, role)
+    if err != nil {
+        return wrapError(ctx, err, "failed to share list")
+    }
+    return nil
+}
+
+// RemoveListMember stops sharing listID with userID. Their completion state
+// is kept in case the list is shared with them again.
+func (s *service) RemoveListMember(ctx context.Context, listID int, userID string) error {
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    result, err := s.exec(ctx, "DELETE FROM list_members WHERE list_id = $1 AND user_id = $2", listID, userID)
+    if err != nil {
+        return wrapError(ctx, err, "failed to remove list member")
+    }
+    return expectRow(ctx, result, "member not found in list %d", listID)
+}
@@ -0,0 +1,29 @@
+package database
+
+import (
+    "database/sql"
+    "errors"
+    "fmt"
+    "net/url"
+)
+
+// openSQLite opens the database file at path with foreign keys enforced,
+// which SQLite leaves off by default, and WAL so readers do not block the
+// writer. It fails straight away in binaries built without cgo.
+func openSQLite(path string) (*sql.DB, error) {
+    if !sqliteSupported {
+        return nil, errors.New("the sqlite driver needs cgo: rebuild with CGO_ENABLED=1 or set DB_DRIVER=postgres")
+    }
+    params := url.Values{}
+    params.Set("_foreign_keys", "on")
+    params.Set("_busy_timeout", "5000")
+    params.Set("_journal_mode", "WAL")
+    db, err := sql.Open("sqlite3", "file:"+path+"?"+params.Encode())
+    if err != nil {
+        return nil, fmt.Errorf("failed to open database: %v", err)
+    }
+    // SQLite allows a single writer. One connection serialises writes in the
+    // pool instead of failing them with SQLITE_BUSY.
+    db.SetMaxOpenConns(1)
+    return db, nil
+}
@@ -0,0 +1,29 @@
+//go:build cgo
+
+package database
+
+import (
+    "errors"
+
+    "github.com/mattn/go-sqlite3"
+)
+
+// sqliteSupported reports whether this binary was built with cgo, which the
+// SQLite driver needs.
+const sqliteSupported = true
+
+// sqliteConstraintKind maps SQLite constraint violations to domain error
+// kinds, mirroring the Postgres SQLSTATE handling in wrapError.
+func sqliteConstraintKind(err error) error {
+    var sqliteErr sqlite3.Error
+    if !errors.As(err, &sqliteErr) || sqliteErr.Code != sqlite3.ErrConstraint {
+        return nil
+    }
+    switch sqliteErr.ExtendedCode {
+    case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+        return ErrConflict
+    case sqlite3.ErrConstraintForeignKey, sqlite3.ErrConstraintCheck:
+        return ErrValidation
+    }
+    return nil
+}
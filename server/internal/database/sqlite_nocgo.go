@@ -0,0 +1,13 @@
+//go:build !cgo
+
+package database
+
+// sqliteSupported reports whether this binary was built with cgo, which the
+// SQLite driver needs. Without it the driver cannot be linked in, so
+// openSQLite refuses to start.
+const sqliteSupported = false
+
+// sqliteConstraintKind never matches, as no SQLite error can occur.
+func sqliteConstraintKind(err error) error {
+    return nil
+}
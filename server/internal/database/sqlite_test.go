@@ -0,0 +1,103 @@
+package database
+
+import (
+	"LeetTracker/internal/config"
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+// requireSQLite skips tests that need the SQLite driver in builds without
+// cgo.
+func requireSQLite(t *testing.T) {
+	t.Helper()
+	if !sqliteSupported {
+		t.Skip("SQLite driver needs cgo")
+	}
+}
+
+// newSQLite migrates a fresh database file in a temporary directory.
+func newSQLite(t *testing.T) Service {
+	t.Helper()
+	cfg := config.Database{Driver: config.DriverSQLite, Path: filepath.Join(t.TempDir(), "test.db")}
+
+	migrator, err := NewMigrator(cfg)
+	if err != nil {
+		t.Fatalf("NewMigrator() returned error: %v", err)
+	}
+	defer migrator.Close()
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("Up() returned error: %v", err)
+	}
+
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	return srv
+}
+
+func TestSQLiteContract(t *testing.T) {
+	requireSQLite(t)
+	runServiceContract(t, newSQLite)
+}
+
+func TestSQLiteMigrationsRoundTrip(t *testing.T) {
+	requireSQLite(t)
+	ctx := context.Background()
+	cfg := config.Database{Driver: config.DriverSQLite, Path: filepath.Join(t.TempDir(), "test.db")}
+	migrator, err := NewMigrator(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer migrator.Close()
+
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up() returned error: %v", err)
+	}
+	for version := ExpectedSchemaVersion(); version > 0; version-- {
+		if _, err := migrator.Down(ctx); err != nil {
+			t.Fatalf("Down() from version %d returned error: %v", version, err)
+		}
+	}
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up() after full rollback returned error: %v", err)
+	}
+	if len(applied) != ExpectedSchemaVersion() {
+		t.Fatalf("expected %d migrations to be reapplied, got %d", ExpectedSchemaVersion(), len(applied))
+	}
+}
+
+// Both dialects must carry the same numbered migrations so a single
+// ExpectedSchemaVersion holds whichever driver is configured.
+func TestDialectMigrationsInLockstep(t *testing.T) {
+	pg, err := loadMigrations(postgresDialect.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lite, err := loadMigrations(sqliteDialect.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pg) != len(lite) {
+		t.Fatalf("postgres has %d migrations, sqlite has %d", len(pg), len(lite))
+	}
+	for i := range pg {
+		if pg[i].Name != lite[i].Name {
+			t.Errorf("migration %d is %q for postgres but %q for sqlite", pg[i].Version, pg[i].Name, lite[i].Name)
+		}
+	}
+}
+
+func TestRebind(t *testing.T) {
+	query := "UPDATE t SET a = $2 WHERE id = $1 AND b = $12"
+	if got := postgresDialect.rebind(query); got != query {
+		t.Errorf("postgres rebind changed the query: %q", got)
+	}
+	want := "UPDATE t SET a = ?2 WHERE id = ?1 AND b = ?12"
+	if got := sqliteDialect.rebind(query); got != want {
+		t.Errorf("sqlite rebind = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,142 @@
+package database
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+)
+
+// Solve is an accepted LeetCode submission matched to a catalog problem.
+type Solve struct {
+    ProblemID int
+    SolvedAt  time.Time
+}
+
+// SolvedItem is an incomplete list item that an imported solve completes.
+type SolvedItem struct {
+    ListItemID   int       `json:"list_item_id"`
+    ListID       int       `json:"list_id"`
+    ListName     string    `json:"list_name"`
+    ProblemID    int       `json:"problem_id"`
+    ProblemTitle string    `json:"problem_title"`
+    CompletedAt  time.Time `json:"completed_at"`
+}
+
+// GetProblemIDsBySlugs maps each slug found in the catalog to its frontend
+// ID. Unknown slugs are absent from the result.
+func (s *service) GetProblemIDsBySlugs(ctx context.Context, slugs []string) (map[string]int, error) {
+    ids := make(map[string]int)
+    if len(slugs) == 0 {
+        return ids, nil
+    }
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    placeholders := make([]string, len(slugs))
+    args := make([]interface{}, len(slugs))
+    for i, slug := range slugs {
+        placeholders[i] = fmt.Sprintf("$%d", i+1)
+        args[i] = slug
+    }
+    rows, err := s.query(ctx, fmt.Sprintf(
+        "SELECT title_slug, frontend_id FROM leetcode_problems WHERE title_slug IN (%s)",
+        strings.Join(placeholders, ", ")), args...)
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to match problem slugs")
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var slug string
+        var id int
+        if err := rows.Scan(&slug, &id); err != nil {
+            return nil, wrapError(ctx, err, "failed to scan problem slug")
+        }
+        ids[slug] = id
+    }
+    if err = rows.Err(); err != nil {
+        return nil, wrapError(ctx, err, "error iterating over problem slugs")
+    }
+    return ids, nil
+}
+
+// CompleteSolvedItems marks every item userID has not completed, in any list
+// they can see, whose problem appears in solves as completed at the earliest
+// matching solve time. Only userID's own completion state changes. With
+// dryRun set nothing is written; the items that would change are returned
+// either way, in list item order.
+func (s *service) CompleteSolvedItems(ctx context.Context, userID string, solves []Solve, dryRun bool) ([]SolvedItem, error) {
+    solvedAt := earliestSolves(solves)
+    if len(solvedAt) == 0 {
+        return nil, nil
+    }
+    ctx, cancel := s.withTimeout(ctx)
+    defer cancel()
+
+    tx, err := s.db.BeginTx(ctx, nil)
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to begin transaction")
+    }
+    defer tx.Rollback()
+
+    placeholders := make([]string, 0, len(solvedAt))
+    args := []interface{}{userID}
+    for problemID := range solvedAt {
+        args = append(args, problemID)
+        placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+    }
+    rows, err := tx.QueryContext(ctx, s.dialect.rebind(fmt.Sprintf(`
+        SELECT li.id, li.list_id, l.name, li.problem_id, lp.title
+        FROM list_items li
+        JOIN lists l ON li.list_id = l.id
+        JOIN leetcode_problems lp ON li.problem_id = lp.frontend_id
+        WHERE %s AND li.problem_id IN (%s)
+            AND NOT EXISTS(SELECT 1 FROM list_item_completions c WHERE c.list_item_id = li.id AND c.user_id = $1)
+        ORDER BY li.id
+    `, listVisibleTo("$1"), strings.Join(placeholders, ", "))), args...)
+    if err != nil {
+        return nil, wrapError(ctx, err, "failed to find solved list items")
+    }
+    var items []SolvedItem
+    for rows.Next() {
+        var item SolvedItem
+        if err := rows.Scan(&item.ListItemID, &item.ListID, &item.ListName, &item.ProblemID, &item.ProblemTitle); err != nil {
+            rows.Close()
+            return nil, wrapError(ctx, err, "failed to scan solved list item")
+        }
+        item.CompletedAt = solvedAt[item.ProblemID]
+        items = append(items, item)
+    }
+    rows.Close()
+    if err = rows.Err(); err != nil {
+        return nil, wrapError(ctx, err, "error iterating over solved list items")
+    }
+    if dryRun {
+        return items, nil
+    }
+
+    for _, item := range items {
+        _, err := tx.ExecContext(ctx, s.dialect.rebind(`
+            INSERT INTO list_item_completions (list_item_id, user_id, completed_at) VALUES ($1, $2, $3)
+        `), item.ListItemID, userID, item.CompletedAt)
+        if err != nil {
+            return nil, wrapError(ctx, err, "failed to complete list item")
+        }
+    }
+    if err := tx.Commit(); err != nil {
+        return nil, wrapError(ctx, err, "failed to commit transaction")
+    }
+    return items, nil
+}
+
+// earliestSolves keeps the first solve of each problem, in UTC.
+func earliestSolves(solves []Solve) map[int]time.Time {
+    solvedAt := make(map[int]time.Time, len(solves))
+    for _, solve := range solves {
+        if at, ok := solvedAt[solve.ProblemID]; !ok || solve.SolvedAt.Before(at) {
+            solvedAt[solve.ProblemID] = solve.SolvedAt.UTC()
+        }
+    }
+    return solvedAt
+}
@@ -0,0 +1,76 @@
+package poller
+
+import (
+    "context"
+    "errors"
+    "sync"
+    "time"
+)
+
+// ErrRateLimited is returned when the next request slot lies beyond the
+// caller's deadline.
+var ErrRateLimited = errors.New("poller: LeetCode requests are rate limited, try again later")
+
+// limiter spaces requests to LeetCode at least interval apart and backs off
+// exponentially, up to maxBackoff, while requests keep failing.
+type limiter struct {
+    mu         sync.Mutex
+    interval   time.Duration
+    maxBackoff time.Duration
+    backoff    time.Duration
+    next       time.Time
+    now        func() time.Time
+}
+
+func newLimiter(interval, maxBackoff time.Duration, now func() time.Time) *limiter {
+    return &limiter{interval: interval, maxBackoff: maxBackoff, now: now}
+}
+
+// wait blocks until the caller may send a request. A slot that would only
+// open after ctx's deadline is not taken and ErrRateLimited is returned.
+func (l *limiter) wait(ctx context.Context) error {
+    l.mu.Lock()
+    now := l.now()
+    at := l.next
+    if at.Before(now) {
+        at = now
+    }
+    if deadline, ok := ctx.Deadline(); ok && at.After(deadline) {
+        l.mu.Unlock()
+        return ErrRateLimited
+    }
+    l.next = at.Add(l.interval)
+    l.mu.Unlock()
+
+    if !sleep(ctx, at.Sub(now)) {
+        return ctx.Err()
+    }
+    return nil
+}
+
+// failed doubles the backoff and pushes the next slot out by it.
+func (l *limiter) failed() {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    if l.backoff == 0 {
+        l.backoff = 2 * l.interval
+        if l.backoff < time.Second {
+            l.backoff = time.Second
+        }
+    } else {
+        l.backoff *= 2
+    }
+    if l.backoff > l.maxBackoff {
+        l.backoff = l.maxBackoff
+    }
+    if next := l.now().Add(l.backoff); next.After(l.next) {
+        l.next = next
+    }
+}
+
+func (l *limiter) succeeded() {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.backoff = 0
+}
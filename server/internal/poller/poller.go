@@ -0,0 +1,283 @@
+// Package poller records a progress snapshot for every tracked LeetCode
+// username on a schedule, so history no longer depends on users opening the
+// Progress page. Linked usernames and leaderboard participants are tracked.
+// Each run also refreshes the catalog's topic tags.
+package poller
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "math/rand"
+    "strings"
+    "time"
+
+    "LeetTracker/internal/config"
+    "LeetTracker/internal/database"
+    "LeetTracker/internal/utils/leetcode"
+)
+
+// ErrRunning is returned by Begin while another full run is in progress.
+var ErrRunning = errors.New("poller: a poll is already running")
+
+// recentRuns is how far back the run log is searched for the last full run
+// when working out the next scheduled one.
+const recentRuns = 50
+
+// maxErrorLength caps the failure summary stored with each run.
+const maxErrorLength = 1000
+
+// Source is the part of the LeetCode client the poller uses.
+type Source interface {
+    SolvedCounts(ctx context.Context, username string) (*leetcode.SolvedCounts, error)
+    ProblemTopics(ctx context.Context, skip, limit int) (map[int][]string, int, error)
+}
+
+// topicsPageSize is how many problems each topics request covers.
+const topicsPageSize = 100
+
+// FetchError reports that LeetCode could not be queried for Username, as
+// opposed to the snapshot failing to save.
+type FetchError struct {
+    Username string
+    Err      error
+}
+
+func (e *FetchError) Error() string {
+    return fmt.Sprintf("%s: %v", e.Username, e.Err)
+}
+
+func (e *FetchError) Unwrap() error {
+    return e.Err
+}
+
+type Poller struct {
+    cfg     config.Poller
+    db      database.Service
+    source  Source
+    limiter *limiter
+
+    // running holds a token while a full run is in progress.
+    running chan struct{}
+
+    now    func() time.Time
+    jitter func() time.Duration
+}
+
+func New(cfg config.Poller, db database.Service, source Source) *Poller {
+    p := &Poller{
+        cfg:     cfg,
+        db:      db,
+        source:  source,
+        running: make(chan struct{}, 1),
+        now:     time.Now,
+    }
+    p.limiter = newLimiter(cfg.RequestInterval, cfg.MaxBackoff, p.clock)
+    p.jitter = func() time.Duration {
+        if cfg.Jitter <= 0 {
+            return 0
+        }
+        return time.Duration(rand.Int63n(int64(cfg.Jitter)))
+    }
+    return p
+}
+
+// clock lets tests replace now after the limiter has been built.
+func (p *Poller) clock() time.Time {
+    return p.now()
+}
+
+// Run polls every tracked username once per interval until ctx is cancelled.
+// The schedule is anchored to the last full run in the log, so restarting
+// the server does not trigger an extra poll.
+func (p *Poller) Run(ctx context.Context) {
+    for {
+        wait := p.untilNextRun(ctx)
+        log.Printf("Poller: next run in %s", wait.Round(time.Second))
+        if !sleep(ctx, wait) {
+            return
+        }
+
+        run, err := p.Begin(ctx, database.PollScheduled)
+        if errors.Is(err, ErrRunning) {
+            continue
+        }
+        if err != nil {
+            log.Printf("Poller: failed to start run: %v", err)
+            if !sleep(ctx, p.cfg.MaxBackoff) {
+                return
+            }
+            continue
+        }
+        p.Complete(ctx, run)
+    }
+}
+
+func (p *Poller) untilNextRun(ctx context.Context) time.Duration {
+    runs, err := p.db.GetPollRuns(ctx, recentRuns)
+    if err != nil {
+        log.Printf("Poller: failed to read run log: %v", err)
+        return p.cfg.MaxBackoff
+    }
+    for _, run := range runs {
+        if run.Username != nil {
+            continue
+        }
+        wait := run.StartedAt.Add(p.cfg.Interval).Sub(p.now())
+        if wait < 0 {
+            wait = 0
+        }
+        return wait + p.jitter()
+    }
+    return p.jitter()
+}
+
+// Begin claims the poller for a full run and records its start. The caller
+// must pass the run to Complete, which releases the claim.
+func (p *Poller) Begin(ctx context.Context, kind string) (*database.PollRun, error) {
+    select {
+    case p.running <- struct{}{}:
+    default:
+        return nil, ErrRunning
+    }
+
+    run := &database.PollRun{Kind: kind}
+    if err := p.db.StartPollRun(ctx, run); err != nil {
+        <-p.running
+        return nil, err
+    }
+    return run, nil
+}
+
+// Complete polls every tracked username for a run returned by Begin and logs
+// the outcome. Failures for one user do not stop the others; LeetCode errors
+// only slow the rest of the run down.
+func (p *Poller) Complete(ctx context.Context, run *database.PollRun) {
+    defer func() { <-p.running }()
+
+    var failures []string
+    usernames, err := p.db.GetTrackedLeetCodeUsernames(ctx)
+    if err != nil {
+        failures = append(failures, err.Error())
+    }
+    for _, username := range usernames {
+        if _, err := p.poll(ctx, username); err != nil {
+            if ctx.Err() != nil {
+                failures = append(failures, "run interrupted")
+                break
+            }
+            run.Failed++
+            failures = append(failures, err.Error())
+            continue
+        }
+        run.Polled++
+    }
+    if ctx.Err() == nil {
+        if err := p.syncTopics(ctx); err != nil {
+            failures = append(failures, "topics: "+err.Error())
+        }
+    }
+    run.Error = summarize(failures)
+
+    p.finish(ctx, run)
+    log.Printf("Poller: run %d polled %d usernames, %d failed", run.ID, run.Polled, run.Failed)
+}
+
+// Refresh polls one username straight away and logs it as a manual run. It
+// returns ErrRateLimited rather than waiting past ctx's deadline.
+func (p *Poller) Refresh(ctx context.Context, username string) (*leetcode.SolvedCounts, error) {
+    run := &database.PollRun{Kind: database.PollManual, Username: &username}
+    if err := p.db.StartPollRun(ctx, run); err != nil {
+        return nil, err
+    }
+
+    counts, err := p.poll(ctx, username)
+    if err != nil {
+        run.Failed = 1
+        run.Error = summarize([]string{err.Error()})
+    } else {
+        run.Polled = 1
+    }
+    p.finish(ctx, run)
+    return counts, err
+}
+
+// poll fetches and stores one snapshot. LeetCode errors are returned as a
+// *FetchError; every one except an unknown user backs the limiter off.
+func (p *Poller) poll(ctx context.Context, username string) (*leetcode.SolvedCounts, error) {
+    if err := p.limiter.wait(ctx); err != nil {
+        return nil, err
+    }
+    counts, err := p.source.SolvedCounts(ctx, username)
+    if err != nil {
+        if !errors.Is(err, leetcode.ErrUserNotFound) && ctx.Err() == nil {
+            p.limiter.failed()
+        }
+        return nil, &FetchError{Username: username, Err: err}
+    }
+    p.limiter.succeeded()
+
+    if err := p.db.StoreLeetCodeUserProgress(ctx, username, *counts); err != nil {
+        return nil, fmt.Errorf("%s: %w", username, err)
+    }
+    return counts, nil
+}
+
+// syncTopics fetches the topics of the whole catalog a page at a time and
+// stores them once every page has arrived, so a failed sync leaves the
+// stored topics as they were.
+func (p *Poller) syncTopics(ctx context.Context) error {
+    topics := make(map[int][]string)
+    for skip, total := 0, 1; skip < total; skip += topicsPageSize {
+        if err := p.limiter.wait(ctx); err != nil {
+            return err
+        }
+        page, n, err := p.source.ProblemTopics(ctx, skip, topicsPageSize)
+        if err != nil {
+            if ctx.Err() == nil {
+                p.limiter.failed()
+            }
+            return err
+        }
+        p.limiter.succeeded()
+        for id, slugs := range page {
+            topics[id] = slugs
+        }
+        total = n
+    }
+    return p.db.SetProblemTopics(ctx, topics)
+}
+
+// finish records the end of a run. It still writes the log when ctx has been
+// cancelled by shutdown, so interrupted runs are visible afterwards.
+func (p *Poller) finish(ctx context.Context, run *database.PollRun) {
+    ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+    defer cancel()
+    if err := p.db.FinishPollRun(ctx, run); err != nil {
+        log.Printf("Poller: failed to record the end of run %d: %v", run.ID, err)
+    }
+}
+
+func summarize(failures []string) string {
+    summary := strings.Join(failures, "; ")
+    if len(summary) > maxErrorLength {
+        summary = summary[:maxErrorLength-3] + "..."
+    }
+    return summary
+}
+
+// sleep waits for d and reports whether ctx is still live afterwards.
+func sleep(ctx context.Context, d time.Duration) bool {
+    if d <= 0 {
+        return ctx.Err() == nil
+    }
+    timer := time.NewTimer(d)
+    defer timer.Stop()
+    select {
+    case <-timer.C:
+        return true
+    case <-ctx.Done():
+        return false
+    }
+}
@@ -0,0 +1,205 @@
+package poller
+
+import (
+	"LeetTracker/internal/config"
+	"LeetTracker/internal/database"
+	"LeetTracker/internal/utils/leetcode"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSource knows alice, reports bob as unknown and fails for anyone else.
+type fakeSource struct {
+	calls      []string
+	topicPages int
+}
+
+func (f *fakeSource) SolvedCounts(ctx context.Context, username string) (*leetcode.SolvedCounts, error) {
+	f.calls = append(f.calls, username)
+	switch username {
+	case "alice":
+		return &leetcode.SolvedCounts{Total: 5, Easy: 3, Medium: 2}, nil
+	case "bob":
+		return nil, leetcode.ErrUserNotFound
+	}
+	return nil, &leetcode.StatusError{StatusCode: 429}
+}
+
+// ProblemTopics serves 150 problems' topics, two pages at the poller's page
+// size. Problem n has the topic "topic-n".
+func (f *fakeSource) ProblemTopics(ctx context.Context, skip, limit int) (map[int][]string, int, error) {
+	f.topicPages++
+	topics := make(map[int][]string)
+	for id := skip + 1; id <= min(skip+limit, 150); id++ {
+		topics[id] = []string{fmt.Sprintf("topic-%d", id)}
+	}
+	return topics, 150, nil
+}
+
+func newTestPoller(t *testing.T, usernames ...string) (*Poller, database.Service, *fakeSource) {
+	t.Helper()
+	ctx := context.Background()
+	db := database.NewMemory()
+	for _, username := range usernames {
+		name := username
+		userID := "auth0|" + username
+		if err := db.EnsureUserExists(ctx, userID); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.UpdateAccount(ctx, &database.Account{UserID: userID, LeetCodeUsername: &name, ProgressVisibility: database.VisibilityPrivate}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	source := &fakeSource{}
+	cfg := config.Poller{Enabled: true, Interval: 24 * time.Hour, MaxBackoff: 10 * time.Millisecond}
+	return New(cfg, db, source), db, source
+}
+
+func TestCompletePollsEveryLinkedUsername(t *testing.T) {
+	ctx := context.Background()
+	p, db, source := newTestPoller(t, "carol", "alice", "bob")
+
+	run, err := p.Begin(ctx, database.PollScheduled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Begin(ctx, database.PollManual); !errors.Is(err, ErrRunning) {
+		t.Fatalf("expected ErrRunning while a run is in progress, got %v", err)
+	}
+	p.Complete(ctx, run)
+
+	if strings.Join(source.calls, ",") != "alice,bob,carol" {
+		t.Errorf("expected every linked username to be polled once, got %v", source.calls)
+	}
+	if run.Polled != 1 || run.Failed != 2 || !strings.Contains(run.Error, "bob") || !strings.Contains(run.Error, "carol") {
+		t.Errorf("unexpected run outcome %+v", run)
+	}
+	history, err := db.GetUserProgressHistory(ctx, "alice")
+	if err != nil || len(history) != 1 || history[0].TotalSolved != 5 {
+		t.Errorf("expected alice's snapshot to be stored, got %+v (%v)", history, err)
+	}
+	runs, err := db.GetPollRuns(ctx, 10)
+	if err != nil || len(runs) != 1 || runs[0].FinishedAt == nil || runs[0].Failed != 2 {
+		t.Errorf("expected the finished run in the log, got %+v (%v)", runs, err)
+	}
+
+	next, err := p.Begin(ctx, database.PollManual)
+	if err != nil {
+		t.Fatalf("expected the poller to be free after Complete, got %v", err)
+	}
+	p.Complete(ctx, next)
+}
+
+func TestCompleteSyncsTopics(t *testing.T) {
+	ctx := context.Background()
+	p, db, source := newTestPoller(t)
+	catalog := []leetcode.Problem{{FrontendID: 1, Title: "Two Sum"}, {FrontendID: 120, Title: "Triangle"}}
+	if err := db.InsertLeetCodeProblems(ctx, catalog); err != nil {
+		t.Fatal(err)
+	}
+
+	run, err := p.Begin(ctx, database.PollScheduled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Complete(ctx, run)
+
+	if source.topicPages != 2 || run.Error != "" {
+		t.Fatalf("expected both topic pages to be fetched, got %d pages (%q)", source.topicPages, run.Error)
+	}
+	problems, _, err := db.GetLeetCodeProblems(ctx, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 2 || strings.Join(problems[1].Topics, ",") != "topic-120" {
+		t.Errorf("expected the topics to be stored, got %+v", problems)
+	}
+}
+
+func TestRefreshLogsManualRun(t *testing.T) {
+	ctx := context.Background()
+	p, db, _ := newTestPoller(t, "alice")
+
+	counts, err := p.Refresh(ctx, "alice")
+	if err != nil || counts.Total != 5 {
+		t.Fatalf("expected alice's counts, got %+v (%v)", counts, err)
+	}
+	_, err = p.Refresh(ctx, "bob")
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) || !errors.Is(err, leetcode.ErrUserNotFound) {
+		t.Fatalf("expected a FetchError wrapping ErrUserNotFound, got %v", err)
+	}
+
+	runs, err := db.GetPollRuns(ctx, 10)
+	if err != nil || len(runs) != 2 {
+		t.Fatalf("expected two manual runs, got %+v (%v)", runs, err)
+	}
+	if runs[0].Kind != database.PollManual || *runs[0].Username != "bob" || runs[0].Failed != 1 {
+		t.Errorf("unexpected failed refresh %+v", runs[0])
+	}
+	if *runs[1].Username != "alice" || runs[1].Polled != 1 {
+		t.Errorf("unexpected successful refresh %+v", runs[1])
+	}
+}
+
+func TestLimiterBacksOffAndRespectsDeadlines(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	l := newLimiter(time.Second, 10*time.Second, func() time.Time { return now })
+
+	l.failed()
+	if got := l.next.Sub(now); got != 2*time.Second {
+		t.Fatalf("expected first backoff of twice the interval, got %s", got)
+	}
+	for i := 0; i < 5; i++ {
+		l.failed()
+	}
+	if got := l.next.Sub(now); got != 10*time.Second {
+		t.Fatalf("expected backoff to be capped, got %s", got)
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(time.Second))
+	defer cancel()
+	if err := l.wait(ctx); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited before the slot opens, got %v", err)
+	}
+
+	l.succeeded()
+	l.next = time.Time{}
+	l.failed()
+	if got := l.next.Sub(now); got != 2*time.Second {
+		t.Fatalf("expected success to reset the backoff, got %s", got)
+	}
+}
+
+func TestScheduleFollowsLastFullRun(t *testing.T) {
+	ctx := context.Background()
+	p, _, _ := newTestPoller(t)
+	p.jitter = func() time.Duration { return time.Minute }
+
+	if got := p.untilNextRun(ctx); got != time.Minute {
+		t.Fatalf("expected an immediate run plus jitter on an empty log, got %s", got)
+	}
+
+	run, err := p.Begin(ctx, database.PollScheduled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Complete(ctx, run)
+	// Single-user refreshes do not move the schedule.
+	if _, err := p.Refresh(ctx, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	p.now = func() time.Time { return run.StartedAt.Add(time.Hour) }
+	if got := p.untilNextRun(ctx); got != 23*time.Hour+time.Minute {
+		t.Fatalf("expected the next run a day after the last, got %s", got)
+	}
+	p.now = func() time.Time { return run.StartedAt.Add(48 * time.Hour) }
+	if got := p.untilNextRun(ctx); got != time.Minute {
+		t.Fatalf("expected an overdue run to start after the jitter, got %s", got)
+	}
+}
@@ -0,0 +1,117 @@
+package progress
+
+import (
+    "time"
+
+    "LeetTracker/internal/database"
+)
+
+// Comparison lines several users' series up on one date axis. Series[i][j]
+// is user i's bucket for Dates[j], or nil before their first snapshot.
+type Comparison struct {
+    Dates  []time.Time
+    Series [][]*Bucket
+}
+
+// Compare rolls up each history over r and aligns the results. The axis
+// starts at the earliest first snapshot among the users, clipped to r.
+func Compare(histories [][]database.ProgressEntry, r Range, today time.Time) Comparison {
+    c := Comparison{Dates: []time.Time{}, Series: make([][]*Bucket, len(histories))}
+
+    var from time.Time
+    for _, history := range histories {
+        if len(history) > 0 && (from.IsZero() || day(history[0].Date).Before(from)) {
+            from = day(history[0].Date)
+        }
+    }
+    if !r.From.IsZero() && day(r.From).After(from) {
+        from = day(r.From)
+    }
+    to := day(today)
+    if !r.To.IsZero() && day(r.To).Before(to) {
+        to = day(r.To)
+    }
+
+    index := make(map[time.Time]int)
+    if !from.IsZero() {
+        for start := bucketStart(from, r.Granularity); !start.After(to); start = nextBucket(start, r.Granularity) {
+            index[start] = len(c.Dates)
+            if start.Before(from) {
+                c.Dates = append(c.Dates, from)
+            } else {
+                c.Dates = append(c.Dates, start)
+            }
+        }
+    }
+
+    for i, history := range histories {
+        c.Series[i] = make([]*Bucket, len(c.Dates))
+        buckets := Rollup(history, r, today)
+        for j := range buckets {
+            if k, ok := index[bucketStart(buckets[j].Date, r.Granularity)]; ok {
+                c.Series[i][k] = &buckets[j]
+            }
+        }
+    }
+    return c
+}
+
+// Mix is each difficulty's share of the solved problems.
+type Mix struct {
+    Easy   float64 `json:"easy"`
+    Medium float64 `json:"medium"`
+    Hard   float64 `json:"hard"`
+}
+
+// Summary describes a user's latest totals and recent pace.
+type Summary struct {
+    TotalSolved  int        `json:"total_solved"`
+    EasySolved   int        `json:"easy_solved"`
+    MediumSolved int        `json:"medium_solved"`
+    HardSolved   int        `json:"hard_solved"`
+    Last7Days    int        `json:"last_7_days"`
+    Last30Days   int        `json:"last_30_days"`
+    Mix          Mix        `json:"mix"`
+    LastRecorded *time.Time `json:"last_recorded"`
+}
+
+// Summarize reports history's latest totals and how many problems were
+// solved in the 7 and 30 days up to today. Gains before the first snapshot
+// are unknown, so a shorter history counts from its first snapshot.
+func Summarize(history []database.ProgressEntry, today time.Time) Summary {
+    var s Summary
+    if len(history) == 0 {
+        return s
+    }
+    latest := history[len(history)-1]
+    recorded := day(latest.Date)
+    s.LastRecorded = &recorded
+    s.TotalSolved = latest.TotalSolved
+    s.EasySolved = latest.EasySolved
+    s.MediumSolved = latest.MediumSolved
+    s.HardSolved = latest.HardSolved
+    s.Last7Days = latest.TotalSolved - totalOn(history, day(today).AddDate(0, 0, -7))
+    s.Last30Days = latest.TotalSolved - totalOn(history, day(today).AddDate(0, 0, -30))
+
+    if solved := float64(latest.EasySolved + latest.MediumSolved + latest.HardSolved); solved > 0 {
+        s.Mix = Mix{
+            Easy:   float64(latest.EasySolved) / solved,
+            Medium: float64(latest.MediumSolved) / solved,
+            Hard:   float64(latest.HardSolved) / solved,
+        }
+    }
+    return s
+}
+
+// totalOn returns the total carried forward to d, or the first snapshot's
+// when d precedes the history.
+func totalOn(history []database.ProgressEntry, d time.Time) int {
+    total := history[0].TotalSolved
+    for _, entry := range history {
+        if day(entry.Date).After(d) {
+            break
+        }
+        total = entry.TotalSolved
+    }
+    return total
+}
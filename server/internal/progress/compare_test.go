@@ -0,0 +1,57 @@
+package progress
+
+import (
+	"LeetTracker/internal/database"
+	"testing"
+)
+
+func TestCompareAlignsSeries(t *testing.T) {
+	late := []database.ProgressEntry{
+		entry("2024-02-05", 1, 0, 0),
+		entry("2024-02-07", 3, 1, 0),
+	}
+	c := Compare([][]database.ProgressEntry{history, late, nil}, Range{Granularity: Week}, date("2024-02-07"))
+
+	if len(c.Dates) != 2 || !c.Dates[0].Equal(date("2024-01-29")) || !c.Dates[1].Equal(date("2024-02-05")) {
+		t.Fatalf("expected an axis of two weeks from the earliest snapshot, got %v", c.Dates)
+	}
+	if len(c.Series) != 3 {
+		t.Fatalf("expected a series per user, got %d", len(c.Series))
+	}
+	if c.Series[0][0] == nil || c.Series[0][1] == nil || c.Series[0][1].TotalSolved != 22 {
+		t.Errorf("unexpected first series %+v", c.Series[0])
+	}
+	if c.Series[1][0] != nil || c.Series[1][1] == nil || c.Series[1][1].NewSolved != 3 {
+		t.Errorf("expected the late starter to be empty for the first week, got %+v", c.Series[1])
+	}
+	if len(c.Series[2]) != 2 || c.Series[2][0] != nil || c.Series[2][1] != nil {
+		t.Errorf("expected an empty series without history, got %+v", c.Series[2])
+	}
+
+	c = Compare([][]database.ProgressEntry{history}, Range{From: date("2024-02-01"), To: date("2024-02-02"), Granularity: Day}, date("2024-02-07"))
+	if len(c.Dates) != 2 || !c.Dates[0].Equal(date("2024-02-01")) || c.Series[0][1].TotalSolved != 20 {
+		t.Errorf("expected the axis clipped to the range, got %v %+v", c.Dates, c.Series[0])
+	}
+
+	if c := Compare([][]database.ProgressEntry{nil}, Range{Granularity: Day}, date("2024-02-07")); len(c.Dates) != 0 {
+		t.Errorf("expected no axis without any history, got %v", c.Dates)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	s := Summarize(history, date("2024-02-07"))
+	if s.TotalSolved != 22 || s.HardSolved != 2 || s.LastRecorded == nil || !s.LastRecorded.Equal(date("2024-02-06")) {
+		t.Errorf("unexpected latest totals %+v", s)
+	}
+	// A week back is 2024-01-31, carried forward from the 30th.
+	if s.Last7Days != 5 || s.Last30Days != 6 {
+		t.Errorf("expected 5 solved in 7 days and 6 since the first snapshot, got %d and %d", s.Last7Days, s.Last30Days)
+	}
+	if s.Mix.Easy < 0.545 || s.Mix.Easy > 0.546 || s.Mix.Hard < 0.09 || s.Mix.Hard > 0.091 {
+		t.Errorf("unexpected mix %+v", s.Mix)
+	}
+
+	if s := Summarize(nil, date("2024-02-07")); s.LastRecorded != nil || s.TotalSolved != 0 {
+		t.Errorf("expected an empty summary, got %+v", s)
+	}
+}
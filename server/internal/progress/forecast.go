@@ -0,0 +1,192 @@
+package progress
+
+import (
+    "math"
+    "time"
+)
+
+// Metrics a forecast can target.
+const (
+    Total  = "total"
+    Easy   = "easy"
+    Medium = "medium"
+    Hard   = "hard"
+)
+
+var Metrics = []string{Total, Easy, Medium, Hard}
+
+// Forecast models.
+const (
+    Linear = "linear"
+    EWMA   = "ewma"
+)
+
+// bandZ is the normal quantile for the 90% confidence bands.
+const bandZ = 1.645
+
+// minForecastDays is the shortest series, in days, worth fitting.
+const minForecastDays = 3
+
+// Reasons a model gives no projected date.
+const (
+    reasonTooShort   = "not enough history in the window"
+    reasonNoProgress = "no progress in the window"
+)
+
+// Projection is when one model expects the target to be reached. Dates are
+// nil when the model cannot say, with Reason explaining why; LatestDate is
+// also nil when the slowest plausible rate is zero.
+type Projection struct {
+    Model         string     `json:"model"`
+    RatePerDay    float64    `json:"rate_per_day"`
+    RateLow       float64    `json:"rate_low"`
+    RateHigh      float64    `json:"rate_high"`
+    ProjectedDate *time.Time `json:"projected_date"`
+    EarliestDate  *time.Time `json:"earliest_date"`
+    LatestDate    *time.Time `json:"latest_date"`
+    Reason        string     `json:"reason,omitempty"`
+}
+
+// Goal is the outcome of forecasting one metric towards a target.
+type Goal struct {
+    Metric     string       `json:"metric"`
+    Current    int          `json:"current"`
+    Target     int          `json:"target"`
+    Reached    bool         `json:"reached"`
+    AsOf       time.Time    `json:"as_of"`
+    WindowDays int          `json:"window_days"`
+    Forecasts  []Projection `json:"forecasts"`
+}
+
+// ForecastGoal fits the last window days of a daily series from Rollup with
+// each model and projects when metric reaches target. The bands are 90%
+// intervals on the solve rate, so they widen as the rate gets noisier.
+func ForecastGoal(daily []Bucket, metric string, target, window int, today time.Time) Goal {
+    goal := Goal{Metric: metric, Target: target, AsOf: day(today), WindowDays: window, Forecasts: []Projection{}}
+    if len(daily) > 0 {
+        goal.Current = value(daily[len(daily)-1], metric)
+    }
+    goal.Reached = len(daily) > 0 && goal.Current >= target
+    if goal.Reached {
+        return goal
+    }
+
+    // A window of n days spans n+1 daily totals.
+    if len(daily) > window+1 {
+        daily = daily[len(daily)-window-1:]
+    }
+    values := make([]float64, len(daily))
+    for i, b := range daily {
+        values[i] = float64(value(b, metric))
+    }
+
+    remaining := float64(target - goal.Current)
+    for _, model := range []string{Linear, EWMA} {
+        p := Projection{Model: model}
+        if len(values) < minForecastDays {
+            p.Reason = reasonTooShort
+            goal.Forecasts = append(goal.Forecasts, p)
+            continue
+        }
+        var se float64
+        if model == Linear {
+            p.RatePerDay, se = linearRate(values)
+        } else {
+            p.RatePerDay, se = ewmaRate(values, window)
+        }
+        p.RateLow = math.Max(0, p.RatePerDay-bandZ*se)
+        p.RateHigh = math.Max(0, p.RatePerDay+bandZ*se)
+        if p.RatePerDay <= 0 {
+            p.Reason = reasonNoProgress
+        } else {
+            p.ProjectedDate = projectDate(goal.AsOf, remaining, p.RatePerDay)
+            p.EarliestDate = projectDate(goal.AsOf, remaining, p.RateHigh)
+            p.LatestDate = projectDate(goal.AsOf, remaining, p.RateLow)
+        }
+        goal.Forecasts = append(goal.Forecasts, p)
+    }
+    return goal
+}
+
+// linearRate fits a least-squares line to the daily totals and returns its
+// slope with the slope's standard error.
+func linearRate(values []float64) (float64, float64) {
+    n := float64(len(values))
+    var sumX, sumY float64
+    for i, y := range values {
+        sumX += float64(i)
+        sumY += y
+    }
+    meanX, meanY := sumX/n, sumY/n
+
+    var sxx, sxy float64
+    for i, y := range values {
+        dx := float64(i) - meanX
+        sxx += dx * dx
+        sxy += dx * (y - meanY)
+    }
+    slope := sxy / sxx
+
+    var sse float64
+    for i, y := range values {
+        resid := y - (meanY + slope*(float64(i)-meanX))
+        sse += resid * resid
+    }
+    return slope, math.Sqrt(sse / (n - 2) / sxx)
+}
+
+// ewmaRate averages the daily gains with weights that halve every quarter
+// of the window, so recent days count most. The error is that of a weighted
+// mean, using the effective number of days.
+func ewmaRate(values []float64, window int) (float64, float64) {
+    decay := math.Pow(0.5, 4/float64(window))
+    var weights, weightsSq, weighted float64
+    gains := make([]float64, 0, len(values)-1)
+    w := 1.0
+    for i := len(values) - 1; i > 0; i-- {
+        gain := values[i] - values[i-1]
+        gains = append(gains, gain)
+        weights += w
+        weightsSq += w * w
+        weighted += w * gain
+        w *= decay
+    }
+    mean := weighted / weights
+
+    var variance float64
+    w = 1.0
+    for _, gain := range gains {
+        variance += w * (gain - mean) * (gain - mean)
+        w *= decay
+    }
+    variance /= weights
+    effective := weights * weights / weightsSq
+    return mean, math.Sqrt(variance / effective)
+}
+
+// projectDate returns the day remaining problems are solved at rate, or nil
+// when the rate never gets there.
+func projectDate(from time.Time, remaining, rate float64) *time.Time {
+    if rate <= 0 {
+        return nil
+    }
+    days := math.Ceil(remaining / rate)
+    // Beyond this the date is meaningless and AddDate would overflow.
+    if days > 100*365 {
+        return nil
+    }
+    date := from.AddDate(0, 0, int(days))
+    return &date
+}
+
+func value(b Bucket, metric string) int {
+    switch metric {
+    case Easy:
+        return b.EasySolved
+    case Medium:
+        return b.MediumSolved
+    case Hard:
+        return b.HardSolved
+    }
+    return b.TotalSolved
+}
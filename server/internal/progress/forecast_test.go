@@ -0,0 +1,93 @@
+package progress
+
+import "testing"
+
+// steady solves two mediums a day with one easy every other day.
+func steady(days int) []Bucket {
+	series := make([]Bucket, days)
+	start := date("2024-01-01")
+	for i := range series {
+		easy, medium := 10+i/2, 20+2*i
+		series[i] = Bucket{Date: start.AddDate(0, 0, i), TotalSolved: easy + medium, EasySolved: easy, MediumSolved: medium}
+	}
+	return series
+}
+
+func TestForecastGoalProjectsSteadyRate(t *testing.T) {
+	daily := steady(29)
+	today := daily[len(daily)-1].Date
+	goal := ForecastGoal(daily, Medium, 100, 28, today)
+
+	if goal.Current != 76 || goal.Reached || len(goal.Forecasts) != 2 {
+		t.Fatalf("unexpected goal %+v", goal)
+	}
+	for _, p := range goal.Forecasts {
+		if p.RatePerDay < 1.99 || p.RatePerDay > 2.01 {
+			t.Errorf("%s: expected a rate of 2 a day, got %v", p.Model, p.RatePerDay)
+		}
+		if p.ProjectedDate == nil || !p.ProjectedDate.Equal(today.AddDate(0, 0, 12)) {
+			t.Errorf("%s: expected the target in 12 days, got %v", p.Model, p.ProjectedDate)
+		}
+	}
+
+	// Easy alternates between 0 and 1 a day, so the bands open up.
+	goal = ForecastGoal(daily, Easy, 40, 28, today)
+	for _, p := range goal.Forecasts {
+		if p.RateLow >= p.RatePerDay || p.RateHigh <= p.RatePerDay {
+			t.Errorf("%s: expected a band around %v, got %v-%v", p.Model, p.RatePerDay, p.RateLow, p.RateHigh)
+		}
+		if p.EarliestDate == nil || p.LatestDate == nil || p.EarliestDate.After(*p.ProjectedDate) || p.LatestDate.Before(*p.ProjectedDate) {
+			t.Errorf("%s: expected the projection inside its band, got %v < %v < %v", p.Model, p.EarliestDate, p.ProjectedDate, p.LatestDate)
+		}
+	}
+}
+
+func TestForecastGoalWeightsRecentDays(t *testing.T) {
+	// Three quiet weeks followed by a week of three a day.
+	daily := make([]Bucket, 29)
+	for i := range daily {
+		total := 50
+		if i > 21 {
+			total += 3 * (i - 21)
+		}
+		daily[i] = Bucket{Date: date("2024-01-01").AddDate(0, 0, i), TotalSolved: total}
+	}
+	goal := ForecastGoal(daily, Total, 200, 28, daily[28].Date)
+	linear, ewma := goal.Forecasts[0], goal.Forecasts[1]
+	if linear.Model != Linear || ewma.Model != EWMA {
+		t.Fatalf("unexpected model order %+v", goal.Forecasts)
+	}
+	if ewma.RatePerDay <= linear.RatePerDay {
+		t.Errorf("expected the weighted rate %v to favour the recent week over the linear %v", ewma.RatePerDay, linear.RatePerDay)
+	}
+}
+
+func TestForecastGoalWithoutAProjection(t *testing.T) {
+	today := date("2024-02-01")
+	if goal := ForecastGoal(steady(10), Total, 10, 28, today); !goal.Reached || len(goal.Forecasts) != 0 {
+		t.Errorf("expected a reached goal without forecasts, got %+v", goal)
+	}
+
+	goal := ForecastGoal(steady(2), Total, 100, 28, today)
+	if goal.Forecasts[0].Reason != reasonTooShort || goal.Forecasts[0].ProjectedDate != nil {
+		t.Errorf("expected two days to be too short to fit, got %+v", goal.Forecasts[0])
+	}
+
+	flat := steady(10)
+	for i := range flat {
+		flat[i].HardSolved = 4
+	}
+	goal = ForecastGoal(flat, Hard, 5, 28, today)
+	for _, p := range goal.Forecasts {
+		if p.Reason != reasonNoProgress || p.ProjectedDate != nil || p.LatestDate != nil {
+			t.Errorf("%s: expected no projection without progress, got %+v", p.Model, p)
+		}
+	}
+
+	// The window drops the early burst, leaving no progress to project.
+	burst := append(steady(10), flat[9], flat[9], flat[9], flat[9], flat[9], flat[9], flat[9], flat[9])
+	goal = ForecastGoal(burst, Total, 100, 7, today)
+	if goal.WindowDays != 7 || goal.Forecasts[0].Reason != reasonNoProgress {
+		t.Errorf("expected only the last 7 days to be fitted, got %+v", goal)
+	}
+}
@@ -0,0 +1,138 @@
+// Package progress turns the daily snapshots in user_progress into the
+// series the Progress page charts.
+package progress
+
+import (
+    "time"
+
+    "LeetTracker/internal/database"
+)
+
+const (
+    Day   = "day"
+    Week  = "week"
+    Month = "month"
+)
+
+// Granularities lists the bucket sizes Rollup accepts.
+var Granularities = []string{Day, Week, Month}
+
+// Bucket summarises one day, ISO week or calendar month. The totals are the
+// last known counts at the end of the bucket, carried forward from earlier
+// snapshots when none was recorded inside it, and the New fields count what
+// was solved during the bucket.
+type Bucket struct {
+    Date         time.Time `json:"date"`
+    End          time.Time `json:"end"`
+    TotalSolved  int       `json:"totalSolved"`
+    EasySolved   int       `json:"easySolved"`
+    MediumSolved int       `json:"mediumSolved"`
+    HardSolved   int       `json:"hardSolved"`
+    NewSolved    int       `json:"newSolved"`
+    NewEasy      int       `json:"newEasy"`
+    NewMedium    int       `json:"newMedium"`
+    NewHard      int       `json:"newHard"`
+    // Recorded is false for buckets whose totals were all carried forward.
+    Recorded bool `json:"recorded"`
+}
+
+// Range selects the days to roll up. Zero bounds default to the first
+// snapshot and to today; the series never extends past today.
+type Range struct {
+    From        time.Time
+    To          time.Time
+    Granularity string
+}
+
+// Rollup buckets history, which must be in date order, over r. The first and
+// last buckets are clipped to the range. The series starts no earlier than
+// the first snapshot, so it does not begin with days before the user was
+// tracked. The first bucket's deltas are measured from the last snapshot
+// before the range, or from its own first snapshot when there is none.
+func Rollup(history []database.ProgressEntry, r Range, today time.Time) []Bucket {
+    buckets := []Bucket{}
+    if len(history) == 0 {
+        return buckets
+    }
+
+    to := day(today)
+    if !r.To.IsZero() && day(r.To).Before(to) {
+        to = day(r.To)
+    }
+    from := day(history[0].Date)
+    if !r.From.IsZero() && day(r.From).After(from) {
+        from = day(r.From)
+    }
+
+    // Skip to the snapshots in range, remembering the last one before it as
+    // the baseline.
+    i := 0
+    var last *database.ProgressEntry
+    for i < len(history) && day(history[i].Date).Before(from) {
+        last = &history[i]
+        i++
+    }
+
+    for start := bucketStart(from, r.Granularity); !start.After(to); start = nextBucket(start, r.Granularity) {
+        end := nextBucket(start, r.Granularity).AddDate(0, 0, -1)
+        if end.After(to) {
+            end = to
+        }
+        b := Bucket{Date: start, End: end}
+        if start.Before(from) {
+            b.Date = from
+        }
+
+        base := last
+        for i < len(history) && !day(history[i].Date).After(end) {
+            if base == nil {
+                base = &history[i]
+            }
+            last = &history[i]
+            b.Recorded = true
+            i++
+        }
+        if last == nil {
+            continue
+        }
+        b.TotalSolved = last.TotalSolved
+        b.EasySolved = last.EasySolved
+        b.MediumSolved = last.MediumSolved
+        b.HardSolved = last.HardSolved
+        b.NewSolved = last.TotalSolved - base.TotalSolved
+        b.NewEasy = last.EasySolved - base.EasySolved
+        b.NewMedium = last.MediumSolved - base.MediumSolved
+        b.NewHard = last.HardSolved - base.HardSolved
+        buckets = append(buckets, b)
+    }
+    return buckets
+}
+
+// day drops the time of day, keeping dates comparable whatever zone a
+// driver scanned them in.
+func day(t time.Time) time.Time {
+    y, m, d := t.Date()
+    return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// bucketStart returns the first day of the bucket holding d. Weeks start on
+// Monday, as ISO weeks do.
+func bucketStart(d time.Time, granularity string) time.Time {
+    switch granularity {
+    case Week:
+        return d.AddDate(0, 0, -(int(d.Weekday())+6)%7)
+    case Month:
+        return time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, time.UTC)
+    }
+    return d
+}
+
+func nextBucket(start time.Time, granularity string) time.Time {
+    switch granularity {
+    case Week:
+        return start.AddDate(0, 0, 7)
+    case Month:
+        return start.AddDate(0, 1, 0)
+    }
+    return start.AddDate(0, 0, 1)
+}
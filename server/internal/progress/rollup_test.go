@@ -0,0 +1,85 @@
+package progress
+
+import (
+	"LeetTracker/internal/database"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func entry(d string, easy, medium, hard int) database.ProgressEntry {
+	return database.ProgressEntry{Date: date(d), TotalSolved: easy + medium + hard, EasySolved: easy, MediumSolved: medium, HardSolved: hard}
+}
+
+// history has a gap from the 3rd to the 5th and spans a month boundary.
+var history = []database.ProgressEntry{
+	entry("2024-01-29", 10, 5, 1),
+	entry("2024-01-30", 11, 5, 1),
+	entry("2024-02-02", 12, 7, 1),
+	entry("2024-02-06", 12, 8, 2),
+}
+
+func TestRollupFillsDailyGaps(t *testing.T) {
+	buckets := Rollup(history, Range{Granularity: Day}, date("2024-02-07"))
+	if len(buckets) != 10 {
+		t.Fatalf("expected a bucket for every day from the first snapshot to today, got %d", len(buckets))
+	}
+
+	gap := buckets[6] // 2024-02-04
+	if !gap.Date.Equal(date("2024-02-04")) || gap.Recorded || gap.TotalSolved != 20 || gap.NewSolved != 0 {
+		t.Errorf("expected the 4th to carry the 2nd's totals forward, got %+v", gap)
+	}
+	after := buckets[8] // 2024-02-06
+	if !after.Recorded || after.NewSolved != 2 || after.NewMedium != 1 || after.NewHard != 1 {
+		t.Errorf("expected the 6th's deltas to be measured from the carried totals, got %+v", after)
+	}
+	if first := buckets[0]; first.NewSolved != 0 || first.TotalSolved != 16 {
+		t.Errorf("expected the first snapshot to be its own baseline, got %+v", first)
+	}
+	if today := buckets[9]; today.Recorded || today.TotalSolved != 22 {
+		t.Errorf("expected the series to be carried to today, got %+v", today)
+	}
+}
+
+func TestRollupWeeksAndMonths(t *testing.T) {
+	weeks := Rollup(history, Range{Granularity: Week}, date("2024-02-07"))
+	if len(weeks) != 2 {
+		t.Fatalf("expected two ISO weeks, got %+v", weeks)
+	}
+	if !weeks[0].Date.Equal(date("2024-01-29")) || !weeks[0].End.Equal(date("2024-02-04")) || weeks[0].NewSolved != 4 || weeks[0].TotalSolved != 20 {
+		t.Errorf("unexpected first week %+v", weeks[0])
+	}
+	if !weeks[1].Date.Equal(date("2024-02-05")) || !weeks[1].End.Equal(date("2024-02-07")) || weeks[1].NewSolved != 2 {
+		t.Errorf("expected the current week to end today, got %+v", weeks[1])
+	}
+
+	months := Rollup(history, Range{Granularity: Month}, date("2024-02-07"))
+	if len(months) != 2 || months[0].NewSolved != 1 || months[1].NewSolved != 5 || months[1].NewEasy != 1 {
+		t.Errorf("expected February's deltas to start from January's last snapshot, got %+v", months)
+	}
+}
+
+func TestRollupRange(t *testing.T) {
+	buckets := Rollup(history, Range{From: date("2024-02-01"), To: date("2024-02-03"), Granularity: Week}, date("2024-03-01"))
+	if len(buckets) != 1 {
+		t.Fatalf("expected one clipped week, got %+v", buckets)
+	}
+	b := buckets[0]
+	if !b.Date.Equal(date("2024-02-01")) || !b.End.Equal(date("2024-02-03")) || b.NewSolved != 3 || b.TotalSolved != 20 {
+		t.Errorf("expected the week clipped to the range with deltas from the 30th, got %+v", b)
+	}
+
+	if got := Rollup(history, Range{To: date("2024-01-01"), Granularity: Day}, date("2024-03-01")); len(got) != 0 {
+		t.Errorf("expected no buckets before the first snapshot, got %+v", got)
+	}
+	if got := Rollup(nil, Range{Granularity: Month}, date("2024-03-01")); got == nil || len(got) != 0 {
+		t.Errorf("expected an empty, non-nil series without history, got %#v", got)
+	}
+}
@@ -0,0 +1,277 @@
+// Package recommend picks the problems a user should solve next from what
+// they have already done, and records why each one was picked.
+package recommend
+
+import (
+    "fmt"
+    "math"
+    "sort"
+    "time"
+
+    "LeetTracker/internal/database"
+    "LeetTracker/internal/utils/leetcode"
+)
+
+// Reason codes, one for each part of a problem's score.
+const (
+    ReasonReview         = "due_for_review"
+    ReasonOnList         = "on_your_list"
+    ReasonWeakTopic      = "weak_topic"
+    ReasonWeakDifficulty = "weak_difficulty"
+    ReasonLevel          = "at_your_level"
+    ReasonAcceptance     = "acceptance_rate"
+)
+
+// Score weights. A problem's acceptance rate adds up to acceptanceWeight, so
+// it only orders problems that other reasons leave tied, such as problems of
+// one difficulty.
+const (
+    reviewWeight         = 3
+    onListWeight         = 2
+    weakTopicWeight      = 2
+    weakDifficultyWeight = 1
+    levelWeight          = 1
+    acceptanceWeight     = 1
+)
+
+const (
+    // ReviewAfter is how long after its last solve a problem is due for
+    // review.
+    ReviewAfter  = 30 * 24 * time.Hour
+    // minAttempts is how many problems of a topic or difficulty a user must
+    // have tried, by ticking them off or answering them in a mock interview,
+    // before it can count as a weak area.
+    minAttempts  = 3
+    // weakRatio is the share of tried problems below which an area is weak.
+    weakRatio    = 0.5
+    // levelSolves is how many problems of one difficulty a user solves
+    // before the next difficulty becomes their level.
+    levelSolves  = 10
+)
+
+// difficulties are LeetCode's difficulty tiers, easiest first.
+var difficulties = []string{"Easy", "Medium", "Hard"}
+
+// Kinds of weak area.
+const (
+    AreaTopic      = "topic"
+    AreaDifficulty = "difficulty"
+)
+
+// Area is a topic or difficulty with the user's record on it.
+type Area struct {
+    Kind      string `json:"kind"`
+    Name      string `json:"name"`
+    Solved    int    `json:"solved"`
+    Attempted int    `json:"attempted"`
+}
+
+func (a Area) ratio() float64 {
+    return float64(a.Solved) / float64(a.Attempted)
+}
+
+type Reason struct {
+    Code   string  `json:"code"`
+    Detail string  `json:"detail"`
+    Weight float64 `json:"weight"`
+}
+
+type Recommendation struct {
+    ProblemID      int      `json:"problem_id"`
+    Title          string   `json:"title"`
+    TitleSlug      string   `json:"title_slug"`
+    Difficulty     string   `json:"difficulty"`
+    AcceptanceRate float64  `json:"acceptance_rate"`
+    URL            string   `json:"url"`
+    Topics         []string `json:"topics"`
+    // Score is the sum of the weights of Reasons.
+    Score          float64  `json:"score"`
+    Reasons        []Reason `json:"reasons"`
+}
+
+func (r *Recommendation) add(code string, weight float64, format string, args ...interface{}) {
+    weight = math.Round(weight*100) / 100
+    r.Reasons = append(r.Reasons, Reason{Code: code, Detail: fmt.Sprintf(format, args...), Weight: weight})
+    r.Score = math.Round((r.Score+weight)*100) / 100
+}
+
+// Result is a user's recommendations with the record they were based on.
+type Result struct {
+    // Level is the difficulty the user is working at.
+    Level           string           `json:"level"`
+    // WeakAreas are the user's weak topics and difficulties, weakest first.
+    WeakAreas       []Area           `json:"weak_areas"`
+    Recommendations []Recommendation `json:"recommendations"`
+}
+
+// Recommend scores every problem in catalog that the user has not solved
+// or is due to review and returns the limit best, highest score first.
+// Premium problems are left out unless premium is set.
+//
+// A problem is solved once the user ticks it on a list or solves it in a
+// mock interview. It is due for review ReviewAfter its last solve, or when
+// the user's latest interview attempt at it failed or was only partial.
+func Recommend(catalog []leetcode.Problem, activity []database.ProblemActivity, premium bool, now time.Time, limit int) Result {
+    byID := make(map[int]leetcode.Problem, len(catalog))
+    for _, p := range catalog {
+        byID[p.FrontendID] = p
+    }
+
+    done := make(map[int]database.ProblemActivity, len(activity))
+    topics := make(map[string]*Area)
+    tiers := make(map[string]*Area)
+    tally := func(areas map[string]*Area, kind, name string, solved bool) {
+        if areas[name] == nil {
+            areas[name] = &Area{Kind: kind, Name: name}
+        }
+        areas[name].Attempted++
+        if solved {
+            areas[name].Solved++
+        }
+    }
+    for _, a := range activity {
+        p, ok := byID[a.ProblemID]
+        if !ok {
+            continue
+        }
+        done[a.ProblemID] = a
+        if a.CompletedAt == nil && a.Outcome == nil {
+            // Only on a list, so not tried yet.
+            continue
+        }
+        _, solved := lastSolved(a)
+        tally(tiers, AreaDifficulty, p.Difficulty, solved)
+        for _, topic := range p.Topics {
+            tally(topics, AreaTopic, topic, solved)
+        }
+    }
+
+    result := Result{Level: level(tiers), WeakAreas: append(weakAreas(tiers), weakAreas(topics)...), Recommendations: []Recommendation{}}
+    sort.SliceStable(result.WeakAreas, func(i, j int) bool {
+        return result.WeakAreas[i].ratio() < result.WeakAreas[j].ratio()
+    })
+
+    for _, p := range catalog {
+        if p.IsPremium && !premium {
+            continue
+        }
+        r := Recommendation{
+            ProblemID:      p.FrontendID,
+            Title:          p.Title,
+            TitleSlug:      p.TitleSlug,
+            Difficulty:     p.Difficulty,
+            AcceptanceRate: p.AcceptanceRate,
+            URL:            p.URL,
+            Topics:         p.Topics,
+            Reasons:        []Reason{},
+        }
+        if r.Topics == nil {
+            r.Topics = []string{}
+        }
+
+        a, tried := done[p.FrontendID]
+        solvedAt, solved := lastSolved(a)
+        switch {
+        case tried && struggled(a):
+            r.add(ReasonReview, reviewWeight, "your last mock interview attempt on %s was %s", a.OutcomeAt.Format("2006-01-02"), *a.Outcome)
+        case solved && now.Sub(solvedAt) >= ReviewAfter:
+            r.add(ReasonReview, reviewWeight, "last solved %d days ago", int(now.Sub(solvedAt)/(24*time.Hour)))
+        case solved:
+            continue
+        case a.OnList:
+            r.add(ReasonOnList, onListWeight, "on one of your lists and not completed yet")
+        }
+
+        if weakest := weakestTopic(p.Topics, topics); weakest != nil {
+            r.add(ReasonWeakTopic, weakTopicWeight, "you have solved %d of the %d %s problems you tried", weakest.Solved, weakest.Attempted, weakest.Name)
+        }
+        if tier := tiers[p.Difficulty]; isWeak(tier) {
+            r.add(ReasonWeakDifficulty, weakDifficultyWeight, "you have solved %d of the %d %s problems you tried", tier.Solved, tier.Attempted, tier.Name)
+        }
+        if p.Difficulty == result.Level {
+            r.add(ReasonLevel, levelWeight, "%s is your level; you have solved %d %s problems", result.Level, solvedCount(tiers, result.Level), result.Level)
+        }
+        r.add(ReasonAcceptance, acceptanceWeight*p.AcceptanceRate/100, "%.1f%% of submissions are accepted", p.AcceptanceRate)
+        result.Recommendations = append(result.Recommendations, r)
+    }
+
+    sort.SliceStable(result.Recommendations, func(i, j int) bool {
+        a, b := result.Recommendations[i], result.Recommendations[j]
+        if a.Score != b.Score {
+            return a.Score > b.Score
+        }
+        return a.ProblemID < b.ProblemID
+    })
+    if len(result.Recommendations) > limit {
+        result.Recommendations = result.Recommendations[:limit]
+    }
+    return result
+}
+
+// lastSolved returns when a was last solved on a list or in an interview.
+func lastSolved(a database.ProblemActivity) (time.Time, bool) {
+    var at time.Time
+    if a.CompletedAt != nil {
+        at = *a.CompletedAt
+    }
+    if a.Outcome != nil && *a.Outcome == database.OutcomeSolved && a.OutcomeAt.After(at) {
+        at = *a.OutcomeAt
+    }
+    return at, !at.IsZero()
+}
+
+// struggled reports whether the user's latest attempt at a was a failed or
+// partial interview answer that they have not since ticked off.
+func struggled(a database.ProblemActivity) bool {
+    if a.Outcome == nil || (*a.Outcome != database.OutcomeFailed && *a.Outcome != database.OutcomePartial) {
+        return false
+    }
+    return a.CompletedAt == nil || a.OutcomeAt.After(*a.CompletedAt)
+}
+
+func isWeak(area *Area) bool {
+    return area != nil && area.Attempted >= minAttempts && area.ratio() < weakRatio
+}
+
+// weakAreas returns the weak areas among areas by name.
+func weakAreas(areas map[string]*Area) []Area {
+    weak := []Area{}
+    for _, area := range areas {
+        if isWeak(area) {
+            weak = append(weak, *area)
+        }
+    }
+    sort.Slice(weak, func(i, j int) bool { return weak[i].Name < weak[j].Name })
+    return weak
+}
+
+// weakestTopic returns the weak topic among topics with the lowest solve
+// ratio, or nil if none of them is weak.
+func weakestTopic(topics []string, areas map[string]*Area) *Area {
+    var weakest *Area
+    for _, topic := range topics {
+        if area := areas[topic]; isWeak(area) && (weakest == nil || area.ratio() < weakest.ratio()) {
+            weakest = area
+        }
+    }
+    return weakest
+}
+
+// level returns the easiest difficulty the user has solved fewer than
+// levelSolves problems of, or the hardest once they have solved enough of
+// every one.
+func level(tiers map[string]*Area) string {
+    for _, difficulty := range difficulties {
+        if solvedCount(tiers, difficulty) < levelSolves {
+            return difficulty
+        }
+    }
+    return difficulties[len(difficulties)-1]
+}
+
+func solvedCount(tiers map[string]*Area, difficulty string) int {
+    if tier := tiers[difficulty]; tier != nil {
+        return tier.Solved
+    }
+    return 0
+}
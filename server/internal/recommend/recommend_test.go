@@ -0,0 +1,163 @@
+package recommend
+
+import (
+	"LeetTracker/internal/database"
+	"LeetTracker/internal/utils/leetcode"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func problem(id int, difficulty string, acceptance float64, topics ...string) leetcode.Problem {
+	return leetcode.Problem{FrontendID: id, Title: "Problem", Difficulty: difficulty, AcceptanceRate: acceptance, Topics: topics}
+}
+
+func daysAgo(days int) *time.Time {
+	t := now.AddDate(0, 0, -days)
+	return &t
+}
+
+func outcome(o string) *string {
+	return &o
+}
+
+func ids(recs []Recommendation) []int {
+	var got []int
+	for _, r := range recs {
+		got = append(got, r.ProblemID)
+	}
+	return got
+}
+
+func reasons(r Recommendation) []string {
+	var codes []string
+	for _, reason := range r.Reasons {
+		codes = append(codes, reason.Code)
+	}
+	return codes
+}
+
+func expectIDs(t *testing.T, recs []Recommendation, want ...int) {
+	t.Helper()
+	got := ids(recs)
+	if len(got) != len(want) {
+		t.Fatalf("expected problems %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected problems %v, got %v", want, got)
+		}
+	}
+}
+
+func TestNewUserStartsWithApproachableEasyProblems(t *testing.T) {
+	catalog := []leetcode.Problem{
+		problem(1, "Easy", 40),
+		problem(2, "Medium", 70),
+		problem(3, "Easy", 60),
+		{FrontendID: 4, Difficulty: "Easy", AcceptanceRate: 90, IsPremium: true},
+	}
+
+	result := Recommend(catalog, nil, false, now, 10)
+	if result.Level != "Easy" || len(result.WeakAreas) != 0 {
+		t.Fatalf("expected an Easy level without weak areas, got %+v", result)
+	}
+	expectIDs(t, result.Recommendations, 3, 1, 2)
+	if r := result.Recommendations[0]; r.Score != 1.6 || len(r.Reasons) != 2 || r.Reasons[0].Code != ReasonLevel || r.Reasons[1].Code != ReasonAcceptance {
+		t.Errorf("expected level and acceptance reasons, got %+v", r)
+	}
+
+	result = Recommend(catalog, nil, true, now, 2)
+	expectIDs(t, result.Recommendations, 4, 3)
+}
+
+func TestSolvedProblemsComeBackForReview(t *testing.T) {
+	catalog := []leetcode.Problem{problem(1, "Easy", 50), problem(2, "Easy", 50), problem(3, "Easy", 50), problem(4, "Easy", 50)}
+	activity := []database.ProblemActivity{
+		{ProblemID: 1, CompletedAt: daysAgo(5)},
+		{ProblemID: 2, CompletedAt: daysAgo(45)},
+		// Solved again in an interview since, so not due yet.
+		{ProblemID: 3, CompletedAt: daysAgo(45), Outcome: outcome(database.OutcomeSolved), OutcomeAt: daysAgo(2)},
+		// Failed in an interview after being ticked off.
+		{ProblemID: 4, CompletedAt: daysAgo(45), Outcome: outcome(database.OutcomeFailed), OutcomeAt: daysAgo(3)},
+	}
+
+	result := Recommend(catalog, activity, false, now, 10)
+	expectIDs(t, result.Recommendations, 2, 4)
+	if r := result.Recommendations[0]; r.Reasons[0].Code != ReasonReview || r.Reasons[0].Detail != "last solved 45 days ago" {
+		t.Errorf("expected a review reason for problem 2, got %+v", r.Reasons)
+	}
+	if r := result.Recommendations[1]; r.Reasons[0].Detail != "your last mock interview attempt on 2024-05-29 was failed" {
+		t.Errorf("expected the failed interview attempt as the reason, got %+v", r.Reasons)
+	}
+}
+
+func TestWeakAreasRaiseScores(t *testing.T) {
+	catalog := []leetcode.Problem{
+		problem(1, "Medium", 50, "graph"),
+		problem(2, "Medium", 50, "graph"),
+		problem(3, "Medium", 50, "graph", "array"),
+		problem(4, "Medium", 50, "array"),
+		problem(5, "Medium", 30, "graph"),
+		problem(6, "Medium", 60, "array"),
+	}
+	var activity []database.ProblemActivity
+	for _, id := range []int{1, 2, 3} {
+		activity = append(activity, database.ProblemActivity{ProblemID: id, Outcome: outcome(database.OutcomeFailed), OutcomeAt: daysAgo(1)})
+	}
+	activity = append(activity, database.ProblemActivity{ProblemID: 4, OnList: true, CompletedAt: daysAgo(1)})
+
+	result := Recommend(catalog, activity, false, now, 10)
+	if len(result.WeakAreas) != 2 || result.WeakAreas[0].Name != "graph" || result.WeakAreas[1].Name != "Medium" {
+		t.Fatalf("expected graph and Medium to be weak, got %+v", result.WeakAreas)
+	}
+	// Array is 1 of 2 tried, too few to count as weak.
+	expectIDs(t, result.Recommendations, 1, 2, 3, 5, 6)
+	got := reasons(result.Recommendations[0])
+	want := []string{ReasonReview, ReasonWeakTopic, ReasonWeakDifficulty, ReasonAcceptance}
+	if len(got) != len(want) {
+		t.Fatalf("expected reasons %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected reasons %v, got %v", want, got)
+		}
+	}
+	if r := result.Recommendations[3]; r.Score != 3.3 || r.Reasons[0].Detail != "you have solved 0 of the 3 graph problems you tried" {
+		t.Errorf("unexpected scoring for problem 5: %+v", r)
+	}
+}
+
+func TestListedProblemsAreNotWeakAreas(t *testing.T) {
+	var catalog []leetcode.Problem
+	var activity []database.ProblemActivity
+	for id := 1; id <= 10; id++ {
+		catalog = append(catalog, problem(id, "Medium", 50, "graph"))
+		activity = append(activity, database.ProblemActivity{ProblemID: id, OnList: true})
+	}
+
+	result := Recommend(catalog, activity, false, now, 10)
+	if len(result.WeakAreas) != 0 {
+		t.Fatalf("expected a new list to leave no weak areas, got %+v", result.WeakAreas)
+	}
+	if got := reasons(result.Recommendations[0]); len(got) != 2 || got[0] != ReasonOnList || got[1] != ReasonAcceptance {
+		t.Errorf("expected only on-list and acceptance reasons, got %v", got)
+	}
+}
+
+func TestLevelMovesUpWithSolves(t *testing.T) {
+	var catalog []leetcode.Problem
+	var activity []database.ProblemActivity
+	for id := 1; id <= levelSolves; id++ {
+		catalog = append(catalog, problem(id, "Easy", 50))
+		activity = append(activity, database.ProblemActivity{ProblemID: id, CompletedAt: daysAgo(1)})
+	}
+	catalog = append(catalog, problem(100, "Easy", 80), problem(101, "Medium", 40), problem(102, "Hard", 20))
+
+	result := Recommend(catalog, activity, false, now, 10)
+	if result.Level != "Medium" {
+		t.Fatalf("expected Medium to be the level, got %q", result.Level)
+	}
+	expectIDs(t, result.Recommendations, 101, 100, 102)
+}
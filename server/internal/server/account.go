@@ -0,0 +1,157 @@
+package server
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net/http"
+
+    "LeetTracker/auth"
+    "LeetTracker/internal/database"
+)
+
+func (s *Server) GetAccountHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+
+    if err := s.db.EnsureUserExists(r.Context(), userID); err != nil {
+        writeServiceError(w, r, err, "Failed to fetch account")
+        return
+    }
+    account, err := s.db.GetAccount(r.Context(), userID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch account")
+        return
+    }
+
+    writeJSON(w, http.StatusOK, account)
+}
+
+// UpdateAccountHandler links a LeetCode username, sets who may see its
+// progress and records whether the user has LeetCode Premium. A newly linked
+// username is looked up on LeetCode first, so typos are rejected and the
+// stored name uses LeetCode's capitalisation.
+func (s *Server) UpdateAccountHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+
+    var req updateAccountRequest
+    if !decodeJSON(w, r, &req) {
+        return
+    }
+    req.normalize()
+    if errs := req.validate(); len(errs) > 0 {
+        writeValidationErrors(w, r, errs)
+        return
+    }
+
+    if err := s.db.EnsureUserExists(r.Context(), userID); err != nil {
+        writeServiceError(w, r, err, "Failed to update account")
+        return
+    }
+    account, err := s.db.GetAccount(r.Context(), userID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to update account")
+        return
+    }
+
+    account.ProgressVisibility = req.ProgressVisibility
+    if req.LeetCodePremium != nil {
+        account.LeetCodePremium = *req.LeetCodePremium
+    }
+    switch {
+    case req.LeetCodeUsername == "":
+        account.LeetCodeUsername = nil
+    case account.LeetCodeUsername == nil || *account.LeetCodeUsername != req.LeetCodeUsername:
+        profile, err := s.leetcodeClient.UserProfile(r.Context(), req.LeetCodeUsername)
+        if err != nil {
+            writeLeetCodeError(w, r, err)
+            return
+        }
+        account.LeetCodeUsername = &profile.Username
+    }
+
+    if err := s.db.UpdateAccount(r.Context(), account); err != nil {
+        if errors.Is(err, database.ErrConflict) {
+            writeError(w, r, http.StatusConflict, codeConflict, "That LeetCode username is linked to another account", nil)
+            return
+        }
+        writeServiceError(w, r, err, "Failed to update account")
+        return
+    }
+    s.invalidateStandings()
+
+    writeJSON(w, http.StatusOK, account)
+}
+
+// progressUsername resolves whose progress a request may read. An empty
+// username means the caller's own linked username. Otherwise the username
+// must be linked to an account whose visibility admits the caller. On failure
+// the error response has been written and ok is false.
+func (s *Server) progressUsername(w http.ResponseWriter, r *http.Request, username string) (string, bool) {
+    viewerID, _ := r.Context().Value(auth.UserIDKey).(string)
+
+    if username == "" {
+        if viewerID == "" {
+            writeUnauthorized(w, r, "Sign in or pass a username")
+            return "", false
+        }
+        return s.linkedUsername(w, r, viewerID)
+    }
+    if !s.canReadProgress(w, r, username) {
+        return "", false
+    }
+    return username, true
+}
+
+// canReadProgress reports whether the caller may read username's progress,
+// writing a 404 or 403 naming the username when not.
+func (s *Server) canReadProgress(w http.ResponseWriter, r *http.Request, username string) bool {
+    viewerID, _ := r.Context().Value(auth.UserIDKey).(string)
+
+    owner, err := s.db.GetAccountByLeetCodeUsername(r.Context(), username)
+    if errors.Is(err, database.ErrNotFound) {
+        writeError(w, r, http.StatusNotFound, codeNotFound, fmt.Sprintf("No account has linked the LeetCode username %q", username), nil)
+        return false
+    }
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch account")
+        return false
+    }
+    visible, err := s.canViewProgress(r.Context(), owner, viewerID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to check group membership")
+        return false
+    }
+    if !visible {
+        writeError(w, r, http.StatusForbidden, codeForbidden, fmt.Sprintf("%s's progress is not visible to you", username), nil)
+        return false
+    }
+    return true
+}
+
+// linkedUsername returns the LeetCode username userID has linked, writing a
+// 404 when there is none.
+func (s *Server) linkedUsername(w http.ResponseWriter, r *http.Request, userID string) (string, bool) {
+    account, err := s.db.GetAccount(r.Context(), userID)
+    if err != nil && !errors.Is(err, database.ErrNotFound) {
+        writeServiceError(w, r, err, "Failed to fetch account")
+        return "", false
+    }
+    if account == nil || account.LeetCodeUsername == nil {
+        writeError(w, r, http.StatusNotFound, codeNotFound, "No LeetCode username is linked to your account", nil)
+        return "", false
+    }
+    return *account.LeetCodeUsername, true
+}
+
+// canViewProgress applies owner's visibility to viewerID, which is empty for
+// anonymous requests. Team progress is visible to anyone sharing a study
+// group with the owner.
+func (s *Server) canViewProgress(ctx context.Context, owner *database.Account, viewerID string) (bool, error) {
+    switch {
+    case owner.ProgressVisibility == database.VisibilityPublic || owner.UserID == viewerID:
+        return true, nil
+    case owner.ProgressVisibility == database.VisibilityTeam && viewerID != "":
+        return s.db.SharesGroup(ctx, owner.UserID, viewerID)
+    }
+    return false, nil
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+    "net/http"
+    "time"
+
+    "LeetTracker/internal/database"
+    "LeetTracker/internal/progress"
+)
+
+type comparedUser struct {
+    Username string             `json:"username"`
+    Summary  progress.Summary   `json:"summary"`
+    Series   []*progress.Bucket `json:"series"`
+}
+
+type compareResponse struct {
+    Granularity string         `json:"granularity"`
+    Dates       []time.Time    `json:"dates"`
+    Users       []comparedUser `json:"users"`
+}
+
+// CompareProgressHandler returns several users' progress on one date axis
+// with a summary for each. Every username must be visible to the caller;
+// the first that is not fails the whole request.
+func (s *Server) CompareProgressHandler(w http.ResponseWriter, r *http.Request) {
+    query := newCompareQuery(r.URL.Query())
+    if errs := query.validate(); len(errs) > 0 {
+        writeValidationErrors(w, r, errs)
+        return
+    }
+
+    histories := make([][]database.ProgressEntry, len(query.Usernames))
+    for i, username := range query.Usernames {
+        if !s.canReadProgress(w, r, username) {
+            return
+        }
+        history, err := s.db.GetUserProgressHistory(r.Context(), username)
+        if err != nil {
+            writeServiceError(w, r, err, "Failed to fetch user progress history")
+            return
+        }
+        histories[i] = history
+    }
+
+    today := time.Now().UTC()
+    rng := query.history.toRange()
+    comparison := progress.Compare(histories, rng, today)
+    resp := compareResponse{Granularity: rng.Granularity, Dates: comparison.Dates, Users: make([]comparedUser, len(histories))}
+    for i, history := range histories {
+        resp.Users[i] = comparedUser{
+            Username: query.Usernames[i],
+            Summary:  progress.Summarize(history, today),
+            Series:   comparison.Series[i],
+        }
+    }
+
+    writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,226 @@
+package server
+
+import (
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gorilla/mux"
+    "LeetTracker/auth"
+    "LeetTracker/internal/daily"
+    "LeetTracker/internal/database"
+)
+
+// dailyToday returns today's date in UTC, which is the day a pick is for.
+func dailyToday() time.Time {
+    return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+// visibleList loads the list in the {id} route variable, if the caller can
+// see it.
+func (s *Server) visibleList(w http.ResponseWriter, r *http.Request) (*database.List, bool) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+    listID, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, codeBadRequest, "Invalid list ID", nil)
+        return nil, false
+    }
+
+    list, err := s.db.GetListByID(r.Context(), listID, userID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch list")
+        return nil, false
+    }
+    return list, true
+}
+
+// GetDailyProblemHandler returns today's problem of the day for a list the
+// caller can see. The first request of a day makes the pick, and it is kept
+// so everyone sees the same problem even if the list changes later.
+func (s *Server) GetDailyProblemHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+    today := dailyToday()
+    list, ok := s.visibleList(w, r)
+    if !ok {
+        return
+    }
+
+    picks, err := s.db.GetDailyProblems(r.Context(), list.ID, userID, today, today)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch problem of the day")
+        return
+    }
+    if len(picks) == 0 {
+        if !s.pickDailyProblem(w, r, list.ID, today) {
+            return
+        }
+        if picks, err = s.db.GetDailyProblems(r.Context(), list.ID, userID, today, today); err != nil {
+            writeServiceError(w, r, err, "Failed to fetch problem of the day")
+            return
+        }
+    }
+
+    writeJSON(w, http.StatusOK, picks[0])
+}
+
+// pickDailyProblem stores listID's pick for date, skipping the problems
+// picked within the list's no-repeat window.
+func (s *Server) pickDailyProblem(w http.ResponseWriter, r *http.Request, listID int, date time.Time) bool {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+    settings, err := s.db.GetDailySettings(r.Context(), listID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch problem of the day settings")
+        return false
+    }
+    candidates, err := s.db.FilterProblems(r.Context(), settings.Filter())
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to select problems")
+        return false
+    }
+
+    recent := make(map[int]time.Time)
+    if settings.NoRepeatDays > 0 {
+        picks, err := s.db.GetDailyProblems(r.Context(), listID, userID, date.AddDate(0, 0, -settings.NoRepeatDays), date.AddDate(0, 0, -1))
+        if err != nil {
+            writeServiceError(w, r, err, "Failed to fetch problems of the day")
+            return false
+        }
+        for _, pick := range picks {
+            if _, ok := recent[pick.ProblemID]; !ok {
+                recent[pick.ProblemID] = pick.Date
+            }
+        }
+    }
+
+    problemID, ok := daily.Pick(listID, date, candidates, recent)
+    if !ok {
+        writeError(w, r, http.StatusNotFound, codeNotFound, "No problems match the problem of the day settings", nil)
+        return false
+    }
+    if err := s.db.PickDailyProblem(r.Context(), listID, date, problemID); err != nil {
+        writeServiceError(w, r, err, "Failed to pick problem of the day")
+        return false
+    }
+    return true
+}
+
+// GetDailyProblemHistoryHandler returns a list's past picks, newest first,
+// with whether the caller did each one.
+func (s *Server) GetDailyProblemHistoryHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+    today := dailyToday()
+    query := newDailyHistoryQuery(r.URL.Query())
+    if errs := query.validate(); len(errs) > 0 {
+        writeValidationErrors(w, r, errs)
+        return
+    }
+    list, ok := s.visibleList(w, r)
+    if !ok {
+        return
+    }
+
+    picks, err := s.db.GetDailyProblems(r.Context(), list.ID, userID, today.AddDate(0, 0, 1-query.days()), today)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch problems of the day")
+        return
+    }
+    writeJSON(w, http.StatusOK, picks)
+}
+
+// UpdateDailyProblemCompletionHandler marks the caller as having done, or
+// not done, a list's pick for the {date} route variable.
+func (s *Server) UpdateDailyProblemCompletionHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+    date, err := time.Parse(dateLayout, mux.Vars(r)["date"])
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, codeBadRequest, "Invalid date, expected YYYY-MM-DD", nil)
+        return
+    }
+    list, ok := s.visibleList(w, r)
+    if !ok {
+        return
+    }
+
+    var req updateCompletionRequest
+    if !decodeJSON(w, r, &req) {
+        return
+    }
+    if errs := req.validate(); len(errs) > 0 {
+        writeValidationErrors(w, r, errs)
+        return
+    }
+
+    if err := s.db.SetDailyProblemDone(r.Context(), list.ID, date, userID, *req.Completed, time.Now().UTC()); err != nil {
+        writeServiceError(w, r, err, "Failed to update problem of the day")
+        return
+    }
+    picks, err := s.db.GetDailyProblems(r.Context(), list.ID, userID, date, date)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch problem of the day")
+        return
+    }
+    writeJSON(w, http.StatusOK, picks[0])
+}
+
+func (s *Server) GetDailySettingsHandler(w http.ResponseWriter, r *http.Request) {
+    list, ok := s.visibleList(w, r)
+    if !ok {
+        return
+    }
+    settings, err := s.db.GetDailySettings(r.Context(), list.ID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch problem of the day settings")
+        return
+    }
+    writeJSON(w, http.StatusOK, settings)
+}
+
+// UpdateDailySettingsHandler turns on or reconfigures a list's problem of
+// the day. Only those who may edit the list can change it. Picks already
+// made are kept.
+func (s *Server) UpdateDailySettingsHandler(w http.ResponseWriter, r *http.Request) {
+    listID, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, codeBadRequest, "Invalid list ID", nil)
+        return
+    }
+
+    var req dailySettingsRequest
+    if !decodeJSON(w, r, &req) {
+        return
+    }
+    req.normalize()
+    if errs := req.validate(); len(errs) > 0 {
+        writeValidationErrors(w, r, errs)
+        return
+    }
+    if !s.editableList(w, r, listID, "Failed to update problem of the day settings") {
+        return
+    }
+
+    settings := req.toSettings(listID)
+    if err := s.db.SetDailySettings(r.Context(), &settings); err != nil {
+        writeServiceError(w, r, err, "Failed to update problem of the day settings")
+        return
+    }
+    writeJSON(w, http.StatusOK, settings)
+}
+
+// DeleteDailySettingsHandler turns off a list's problem of the day. Past
+// picks stay in its history.
+func (s *Server) DeleteDailySettingsHandler(w http.ResponseWriter, r *http.Request) {
+    listID, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, codeBadRequest, "Invalid list ID", nil)
+        return
+    }
+    if !s.editableList(w, r, listID, "Failed to delete problem of the day settings") {
+        return
+    }
+
+    if err := s.db.DeleteDailySettings(r.Context(), listID); err != nil {
+        writeServiceError(w, r, err, "Failed to delete problem of the day settings")
+        return
+    }
+    writeMessage(w, http.StatusOK, "Problem of the day turned off")
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+    "net/http"
+    "strconv"
+
+    "github.com/gorilla/mux"
+    "LeetTracker/auth"
+    "LeetTracker/internal/database"
+    "LeetTracker/internal/utils/leetcode"
+)
+
+// feedbackPageSize caps how many entries GetProblemFeedbackHandler returns.
+const feedbackPageSize = 50
+
+// catalogProblem is a catalog entry with the community's view of it next to
+// LeetCode's official difficulty. Community is null until someone rates it.
+type catalogProblem struct {
+    leetcode.Problem
+    Community *database.CommunityRating `json:"community"`
+}
+
+type problemFeedbackResponse struct {
+    ProblemID int                        `json:"problem_id"`
+    Community *database.CommunityRating  `json:"community"`
+    Feedback  []database.ProblemFeedback `json:"feedback"`
+}
+
+func (s *Server) GetProblemFeedbackHandler(w http.ResponseWriter, r *http.Request) {
+    problemID, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, codeBadRequest, "Invalid problem ID", nil)
+        return
+    }
+
+    feedback, err := s.db.GetProblemFeedback(r.Context(), problemID, feedbackPageSize)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch problem feedback")
+        return
+    }
+    ratings, err := s.db.GetCommunityRatings(r.Context(), []int{problemID})
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch community ratings")
+        return
+    }
+
+    response := problemFeedbackResponse{ProblemID: problemID, Feedback: feedback}
+    if rating, ok := ratings[problemID]; ok {
+        response.Community = &rating
+    }
+    if response.Feedback == nil {
+        response.Feedback = []database.ProblemFeedback{}
+    }
+    writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) SaveProblemFeedbackHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+    problemID, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, codeBadRequest, "Invalid problem ID", nil)
+        return
+    }
+
+    var req problemFeedbackRequest
+    if !decodeJSON(w, r, &req) {
+        return
+    }
+    req.normalize()
+    if errs := req.validate(); len(errs) > 0 {
+        writeValidationErrors(w, r, errs)
+        return
+    }
+
+    if err := s.db.EnsureUserExists(r.Context(), userID); err != nil {
+        writeServiceError(w, r, err, "Failed to save feedback")
+        return
+    }
+    feedback := req.toFeedback(problemID, userID)
+    if err := s.db.SaveProblemFeedback(r.Context(), &feedback); err != nil {
+        writeServiceError(w, r, err, "Failed to save feedback")
+        return
+    }
+
+    writeJSON(w, http.StatusOK, feedback)
+}
+
+func (s *Server) DeleteProblemFeedbackHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+    problemID, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, codeBadRequest, "Invalid problem ID", nil)
+        return
+    }
+
+    if err := s.db.DeleteProblemFeedback(r.Context(), problemID, userID); err != nil {
+        writeServiceError(w, r, err, "Failed to delete feedback")
+        return
+    }
+
+    writeMessage(w, http.StatusOK, "Feedback deleted")
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+    "net/http"
+    "time"
+
+    "LeetTracker/internal/progress"
+)
+
+// GetProgressForecastHandler projects when a user will reach a target total,
+// or a per-difficulty count, from their recent solve rate. It follows the
+// same visibility rules as the progress history.
+func (s *Server) GetProgressForecastHandler(w http.ResponseWriter, r *http.Request) {
+    query := newForecastQuery(r.URL.Query())
+    if errs := query.validate(); len(errs) > 0 {
+        writeValidationErrors(w, r, errs)
+        return
+    }
+    username, ok := s.progressUsername(w, r, query.Username)
+    if !ok {
+        return
+    }
+
+    history, err := s.db.GetUserProgressHistory(r.Context(), username)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch user progress history")
+        return
+    }
+
+    today := time.Now().UTC()
+    target, metric, window := query.values()
+    daily := progress.Rollup(history, progress.Range{Granularity: progress.Day}, today)
+    writeJSON(w, http.StatusOK, progress.ForecastGoal(daily, metric, target, window, today))
+}
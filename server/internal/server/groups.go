@@ -0,0 +1,398 @@
+package server
+
+import (
+    "crypto/rand"
+    "encoding/base32"
+    "errors"
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gorilla/mux"
+    "LeetTracker/auth"
+    "LeetTracker/internal/database"
+    "LeetTracker/internal/progress"
+)
+
+// inviteCodeBytes gives 48 random bits, which base32 turns into a
+// ten-character code that is easy to read out.
+const inviteCodeBytes = 6
+
+var inviteEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func newInviteCode() (string, error) {
+    b := make([]byte, inviteCodeBytes)
+    if _, err := rand.Read(b); err != nil {
+        return "", err
+    }
+    return inviteEncoding.EncodeToString(b), nil
+}
+
+// groupResponse is a group with its invite link. The code and link are
+// only shown to owners and admins.
+type groupResponse struct {
+    database.Group
+    InviteLink string `json:"invite_link,omitempty"`
+}
+
+type groupDetailResponse struct {
+    groupResponse
+    Members []database.GroupMember `json:"members"`
+}
+
+func (s *Server) groupResponse(group database.Group) groupResponse {
+    if !canManageGroup(group.Role) {
+        group.InviteCode = ""
+        return groupResponse{Group: group}
+    }
+    return groupResponse{Group: group, InviteLink: s.inviteLink(group.InviteCode)}
+}
+
+func (s *Server) inviteLink(code string) string {
+    return s.webURL + "/groups/join/" + code
+}
+
+func canManageGroup(role string) bool {
+    return role == database.RoleOwner || role == database.RoleAdmin
+}
+
+// callerGroup loads the group in the {id} route variable as the caller sees
+// it. Non-members get a 404, and members whose role is not in roles get a
+// 403; no roles admits every member.
+func (s *Server) callerGroup(w http.ResponseWriter, r *http.Request, roles ...string) (*database.Group, bool) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+    groupID, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, codeBadRequest, "Invalid group ID", nil)
+        return nil, false
+    }
+
+    group, err := s.db.GetGroup(r.Context(), groupID, userID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch group")
+        return nil, false
+    }
+    if len(roles) == 0 {
+        return group, true
+    }
+    for _, role := range roles {
+        if group.Role == role {
+            return group, true
+        }
+    }
+    writeError(w, r, http.StatusForbidden, codeForbidden, "Your role in this group does not allow that", nil)
+    return nil, false
+}
+
+func (s *Server) CreateGroupHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+
+    var req createGroupRequest
+    if !decodeJSON(w, r, &req) {
+        return
+    }
+    req.normalize()
+    if errs := req.validate(); len(errs) > 0 {
+        writeValidationErrors(w, r, errs)
+        return
+    }
+
+    if err := s.db.EnsureUserExists(r.Context(), userID); err != nil {
+        writeServiceError(w, r, err, "Failed to create group")
+        return
+    }
+    code, err := newInviteCode()
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to create group")
+        return
+    }
+    group := database.Group{Name: req.Name, Description: req.Description, InviteCode: code}
+    if err := s.db.CreateGroup(r.Context(), userID, &group); err != nil {
+        writeServiceError(w, r, err, "Failed to create group")
+        return
+    }
+
+    writeJSON(w, http.StatusOK, s.groupResponse(group))
+}
+
+func (s *Server) GetUserGroupsHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+
+    groups, err := s.db.GetUserGroups(r.Context(), userID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch groups")
+        return
+    }
+
+    resp := make([]groupResponse, len(groups))
+    for i, group := range groups {
+        resp[i] = s.groupResponse(group)
+    }
+    writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) GetGroupHandler(w http.ResponseWriter, r *http.Request) {
+    group, ok := s.callerGroup(w, r)
+    if !ok {
+        return
+    }
+    members, err := s.db.GetGroupMembers(r.Context(), group.ID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch group members")
+        return
+    }
+
+    writeJSON(w, http.StatusOK, groupDetailResponse{groupResponse: s.groupResponse(*group), Members: members})
+}
+
+// DeleteGroupHandler deletes the group along with its lists. Only the owner
+// may do this.
+func (s *Server) DeleteGroupHandler(w http.ResponseWriter, r *http.Request) {
+    group, ok := s.callerGroup(w, r, database.RoleOwner)
+    if !ok {
+        return
+    }
+    if err := s.db.DeleteGroup(r.Context(), group.ID); err != nil {
+        writeServiceError(w, r, err, "Failed to delete group")
+        return
+    }
+    s.invalidateStandings()
+
+    writeMessage(w, http.StatusOK, "Group deleted")
+}
+
+// RotateInviteCodeHandler replaces the group's invite code, so links that
+// were shared before stop working. Existing members are not affected.
+func (s *Server) RotateInviteCodeHandler(w http.ResponseWriter, r *http.Request) {
+    group, ok := s.callerGroup(w, r, database.RoleOwner, database.RoleAdmin)
+    if !ok {
+        return
+    }
+    code, err := newInviteCode()
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to update invite code")
+        return
+    }
+    if err := s.db.SetGroupInviteCode(r.Context(), group.ID, code); err != nil {
+        writeServiceError(w, r, err, "Failed to update invite code")
+        return
+    }
+
+    writeJSON(w, http.StatusOK, map[string]string{"invite_code": code, "invite_link": s.inviteLink(code)})
+}
+
+func (s *Server) JoinGroupHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+
+    var req joinGroupRequest
+    if !decodeJSON(w, r, &req) {
+        return
+    }
+    req.normalize()
+    if errs := req.validate(); len(errs) > 0 {
+        writeValidationErrors(w, r, errs)
+        return
+    }
+
+    if err := s.db.EnsureUserExists(r.Context(), userID); err != nil {
+        writeServiceError(w, r, err, "Failed to join group")
+        return
+    }
+    group, err := s.db.JoinGroup(r.Context(), req.InviteCode, userID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to join group")
+        return
+    }
+    s.invalidateStandings()
+
+    writeJSON(w, http.StatusOK, s.groupResponse(*group))
+}
+
+// LeaveGroupHandler removes the caller from the group. The owner cannot
+// leave and has to delete the group instead.
+func (s *Server) LeaveGroupHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+
+    group, ok := s.callerGroup(w, r)
+    if !ok {
+        return
+    }
+    if group.Role == database.RoleOwner {
+        writeError(w, r, http.StatusForbidden, codeForbidden, "The owner cannot leave the group; delete it instead", nil)
+        return
+    }
+    if err := s.db.RemoveGroupMember(r.Context(), group.ID, userID); err != nil {
+        writeServiceError(w, r, err, "Failed to leave group")
+        return
+    }
+    s.invalidateStandings()
+
+    writeMessage(w, http.StatusOK, "Left group")
+}
+
+// SetGroupMemberRoleHandler promotes a member to admin or demotes an admin.
+// Only the owner may change roles.
+func (s *Server) SetGroupMemberRoleHandler(w http.ResponseWriter, r *http.Request) {
+    group, ok := s.callerGroup(w, r, database.RoleOwner)
+    if !ok {
+        return
+    }
+
+    var req groupRoleRequest
+    if !decodeJSON(w, r, &req) {
+        return
+    }
+    req.normalize()
+    if errs := req.validate(); len(errs) > 0 {
+        writeValidationErrors(w, r, errs)
+        return
+    }
+
+    if err := s.db.SetGroupMemberRole(r.Context(), group.ID, mux.Vars(r)["userID"], req.Role); err != nil {
+        writeServiceError(w, r, err, "Failed to update member role")
+        return
+    }
+
+    writeMessage(w, http.StatusOK, "Member role updated")
+}
+
+// RemoveGroupMemberHandler removes another member. Owners can remove anyone
+// but themselves; admins can only remove plain members.
+func (s *Server) RemoveGroupMemberHandler(w http.ResponseWriter, r *http.Request) {
+    group, ok := s.callerGroup(w, r, database.RoleOwner, database.RoleAdmin)
+    if !ok {
+        return
+    }
+
+    memberID := mux.Vars(r)["userID"]
+    member, err := s.db.GetGroup(r.Context(), group.ID, memberID)
+    if errors.Is(err, database.ErrNotFound) {
+        writeError(w, r, http.StatusNotFound, codeNotFound, "That user is not a member of this group", nil)
+        return
+    }
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to remove group member")
+        return
+    }
+    if group.Role == database.RoleAdmin && member.Role != database.RoleMember {
+        writeError(w, r, http.StatusForbidden, codeForbidden, "Admins can only remove members", nil)
+        return
+    }
+    if err := s.db.RemoveGroupMember(r.Context(), group.ID, memberID); err != nil {
+        writeServiceError(w, r, err, "Failed to remove group member")
+        return
+    }
+    s.invalidateStandings()
+
+    writeMessage(w, http.StatusOK, "Member removed")
+}
+
+func (s *Server) GetGroupListsHandler(w http.ResponseWriter, r *http.Request) {
+    group, ok := s.callerGroup(w, r)
+    if !ok {
+        return
+    }
+    lists, err := s.db.GetGroupLists(r.Context(), group.ID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch group lists")
+        return
+    }
+
+    writeJSON(w, http.StatusOK, lists)
+}
+
+// CreateGroupListHandler creates a list owned by the group. Every member
+// can see it and tick its items; owners and admins manage it.
+func (s *Server) CreateGroupListHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+
+    group, ok := s.callerGroup(w, r, database.RoleOwner, database.RoleAdmin)
+    if !ok {
+        return
+    }
+
+    var req createListRequest
+    if !decodeJSON(w, r, &req) {
+        return
+    }
+    req.normalize()
+    if errs := req.validate(); len(errs) > 0 {
+        writeValidationErrors(w, r, errs)
+        return
+    }
+
+    list := req.toList(userID)
+    list.GroupID = &group.ID
+    listID, err := s.db.CreateList(r.Context(), userID, &list)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to create list")
+        return
+    }
+
+    writeJSON(w, http.StatusOK, map[string]int{"list_id": listID})
+}
+
+type groupMemberProgress struct {
+    UserID   string             `json:"user_id"`
+    Username string             `json:"username"`
+    Role     string             `json:"role"`
+    Summary  progress.Summary   `json:"summary"`
+    Series   []*progress.Bucket `json:"series"`
+}
+
+type groupProgressResponse struct {
+    Granularity string                `json:"granularity"`
+    Dates       []time.Time           `json:"dates"`
+    Members     []groupMemberProgress `json:"members"`
+    Hidden      int                   `json:"hidden"`
+}
+
+// GetGroupProgressHandler charts every member's progress on one date axis.
+// Members without a linked username, or whose progress is private, are only
+// counted in hidden.
+func (s *Server) GetGroupProgressHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+
+    query := newHistoryQuery(r.URL.Query())
+    if errs := query.validate(); len(errs) > 0 {
+        writeValidationErrors(w, r, errs)
+        return
+    }
+    group, ok := s.callerGroup(w, r)
+    if !ok {
+        return
+    }
+    members, err := s.db.GetGroupMembers(r.Context(), group.ID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch group members")
+        return
+    }
+
+    resp := groupProgressResponse{Members: []groupMemberProgress{}}
+    var histories [][]database.ProgressEntry
+    for _, member := range members {
+        if member.LeetCodeUsername == nil || (member.ProgressVisibility == database.VisibilityPrivate && member.UserID != userID) {
+            resp.Hidden++
+            continue
+        }
+        history, err := s.db.GetUserProgressHistory(r.Context(), *member.LeetCodeUsername)
+        if err != nil {
+            writeServiceError(w, r, err, "Failed to fetch user progress history")
+            return
+        }
+        histories = append(histories, history)
+        resp.Members = append(resp.Members, groupMemberProgress{UserID: member.UserID, Username: *member.LeetCodeUsername, Role: member.Role})
+    }
+
+    today := time.Now().UTC()
+    rng := query.toRange()
+    comparison := progress.Compare(histories, rng, today)
+    resp.Granularity = rng.Granularity
+    resp.Dates = comparison.Dates
+    for i, history := range histories {
+        resp.Members[i].Summary = progress.Summarize(history, today)
+        resp.Members[i].Series = comparison.Series[i]
+    }
+
+    writeJSON(w, http.StatusOK, resp)
+}
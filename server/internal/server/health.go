@@ -0,0 +1,110 @@
+package server
+
+import (
+    "context"
+    "net/http"
+    "sync"
+    "time"
+)
+
+const (
+    // readinessCheckTimeout bounds each dependency check so a hung database
+    // or cache cannot stall the probe past the orchestrator's own timeout.
+    readinessCheckTimeout = 2 * time.Second
+    // catalogStaleAfter is how old the last LeetCode sync may be before the
+    // catalog is reported as stale.
+    catalogStaleAfter = 48 * time.Hour
+)
+
+type dependencyStatus struct {
+    Status    string      `json:"status"`
+    LatencyMS float64     `json:"latency_ms"`
+    Error     string      `json:"error,omitempty"`
+    Details   interface{} `json:"details,omitempty"`
+}
+
+type readinessReport struct {
+    Status string                      `json:"status"`
+    Checks map[string]dependencyStatus `json:"checks"`
+}
+
+// LivenessHandler only reports that the process is serving requests. It never
+// touches dependencies, so a database outage cannot get the process restarted.
+func (s *Server) LivenessHandler(w http.ResponseWriter, r *http.Request) {
+    writeJSON(w, http.StatusOK, map[string]string{"status": "alive"})
+}
+
+// ReadinessHandler checks every dependency concurrently and returns 503 when
+// a required one is down. The catalog is informational: an empty or stale
+// catalog is reported but does not take the instance out of rotation.
+func (s *Server) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
+    checks := map[string]func(ctx context.Context) (dependencyStatus, bool){
+        "database": s.checkDatabase,
+        "cache":    s.checkCache,
+        "catalog":  s.checkCatalog,
+    }
+
+    report := readinessReport{Status: "ready", Checks: make(map[string]dependencyStatus, len(checks))}
+    var mu sync.Mutex
+    var wg sync.WaitGroup
+    for name, check := range checks {
+        wg.Add(1)
+        go func(name string, check func(ctx context.Context) (dependencyStatus, bool)) {
+            defer wg.Done()
+            ctx, cancel := context.WithTimeout(r.Context(), readinessCheckTimeout)
+            defer cancel()
+
+            start := time.Now()
+            status, required := check(ctx)
+            status.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+
+            mu.Lock()
+            defer mu.Unlock()
+            report.Checks[name] = status
+            if required && status.Status != "up" {
+                report.Status = "not_ready"
+            }
+        }(name, check)
+    }
+    wg.Wait()
+
+    code := http.StatusOK
+    if report.Status != "ready" {
+        code = http.StatusServiceUnavailable
+    }
+    writeJSON(w, code, report)
+}
+
+func (s *Server) checkDatabase(ctx context.Context) (dependencyStatus, bool) {
+    if err := s.db.Ping(ctx); err != nil {
+        return dependencyStatus{Status: "down", Error: err.Error()}, true
+    }
+    return dependencyStatus{Status: "up"}, true
+}
+
+func (s *Server) checkCache(ctx context.Context) (dependencyStatus, bool) {
+    if err := s.cache.Ping(ctx); err != nil {
+        return dependencyStatus{Status: "down", Error: err.Error()}, true
+    }
+    return dependencyStatus{Status: "up"}, true
+}
+
+func (s *Server) checkCatalog(ctx context.Context) (dependencyStatus, bool) {
+    catalog, err := s.db.CatalogStatus(ctx)
+    if err != nil {
+        return dependencyStatus{Status: "down", Error: err.Error()}, false
+    }
+    status := dependencyStatus{Status: "up", Details: catalog}
+    switch {
+    case catalog.LastSync == nil:
+        status.Status = "empty"
+    case time.Since(*catalog.LastSync) > catalogStaleAfter:
+        status.Status = "stale"
+    }
+    return status, false
+}
+
+// DatabaseStatsHandler exposes connection pool statistics to administrators.
+func (s *Server) DatabaseStatsHandler(w http.ResponseWriter, r *http.Request) {
+    writeJSON(w, http.StatusOK, s.db.PoolStats())
+}
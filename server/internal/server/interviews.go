@@ -0,0 +1,284 @@
+package server
+
+import (
+    "fmt"
+    "math/rand"
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gorilla/mux"
+    "LeetTracker/auth"
+    "LeetTracker/internal/database"
+)
+
+// interviewHistorySize is how many past sessions GET /interviews returns.
+const interviewHistorySize = 50
+
+// Session states. A session is active until it is ended or its time runs
+// out, whichever comes first.
+const (
+    interviewActive    = "active"
+    interviewCompleted = "completed"
+    interviewExpired   = "expired"
+)
+
+type interviewScore struct {
+    Solved     int     `json:"solved"`
+    Partial    int     `json:"partial"`
+    Failed     int     `json:"failed"`
+    Skipped    int     `json:"skipped"`
+    Unanswered int     `json:"unanswered"`
+    // Percent counts a partial solution as half a problem.
+    Percent    float64 `json:"percent"`
+}
+
+type interviewResponse struct {
+    database.InterviewSession
+    Status           string         `json:"status"`
+    RemainingSeconds int            `json:"remaining_seconds"`
+    Score            interviewScore `json:"score"`
+}
+
+func newInterviewResponse(session database.InterviewSession, now time.Time) interviewResponse {
+    response := interviewResponse{InterviewSession: session, Status: interviewStatus(session, now)}
+    if response.Status == interviewActive {
+        response.RemainingSeconds = int(session.ExpiresAt.Sub(now).Seconds())
+    }
+
+    score := &response.Score
+    for _, problem := range session.Problems {
+        if problem.Outcome == nil {
+            score.Unanswered++
+            continue
+        }
+        switch *problem.Outcome {
+        case database.OutcomeSolved:
+            score.Solved++
+        case database.OutcomePartial:
+            score.Partial++
+        case database.OutcomeFailed:
+            score.Failed++
+        case database.OutcomeSkipped:
+            score.Skipped++
+        }
+    }
+    if n := len(session.Problems); n > 0 {
+        score.Percent = (float64(score.Solved) + float64(score.Partial)/2) * 100 / float64(n)
+    }
+    return response
+}
+
+func interviewStatus(session database.InterviewSession, now time.Time) string {
+    switch {
+    case session.EndedAt != nil && session.EndedAt.Before(session.ExpiresAt):
+        return interviewCompleted
+    case session.EndedAt != nil || !now.Before(session.ExpiresAt):
+        return interviewExpired
+    default:
+        return interviewActive
+    }
+}
+
+// expireInterview ends session at its expiry time if it ran out without
+// being ended, so the stored end time matches what clients were shown.
+func (s *Server) expireInterview(r *http.Request, session *database.InterviewSession, now time.Time) error {
+    if session.EndedAt != nil || now.Before(session.ExpiresAt) {
+        return nil
+    }
+    if err := s.db.EndInterviewSession(r.Context(), session.ID, session.ExpiresAt); err != nil {
+        return err
+    }
+    expiresAt := session.ExpiresAt
+    session.EndedAt = &expiresAt
+    return nil
+}
+
+// ownInterview loads the caller's session in the {id} route variable,
+// expiring it first if its time has run out.
+func (s *Server) ownInterview(w http.ResponseWriter, r *http.Request, now time.Time) (*database.InterviewSession, bool) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+    sessionID, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, codeBadRequest, "Invalid interview session ID", nil)
+        return nil, false
+    }
+
+    session, err := s.db.GetInterviewSession(r.Context(), sessionID, userID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch interview session")
+        return nil, false
+    }
+    if err := s.expireInterview(r, session, now); err != nil {
+        writeServiceError(w, r, err, "Failed to end interview session")
+        return nil, false
+    }
+    return session, true
+}
+
+// StartInterviewHandler starts a timed session with problems picked at
+// random from a list the caller can see or from the catalog. A caller can
+// only have one session running at a time.
+func (s *Server) StartInterviewHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+    now := time.Now().UTC().Truncate(time.Second)
+
+    var req createInterviewRequest
+    if !decodeJSON(w, r, &req) {
+        return
+    }
+    req.normalize()
+    if errs := req.validate(); len(errs) > 0 {
+        writeValidationErrors(w, r, errs)
+        return
+    }
+
+    if err := s.db.EnsureUserExists(r.Context(), userID); err != nil {
+        writeServiceError(w, r, err, "Failed to start interview session")
+        return
+    }
+    if req.ListID != nil {
+        if _, err := s.db.GetListByID(r.Context(), *req.ListID, userID); err != nil {
+            writeServiceError(w, r, err, "Error retrieving list")
+            return
+        }
+    }
+    latest, err := s.db.GetUserInterviewSessions(r.Context(), userID, 1)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch interview sessions")
+        return
+    }
+    if len(latest) > 0 && interviewStatus(latest[0], now) == interviewActive {
+        writeError(w, r, http.StatusConflict, codeConflict, "Another interview session is still running", nil)
+        return
+    }
+
+    candidates, err := s.db.FilterProblems(r.Context(), req.filter(userID))
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to select problems")
+        return
+    }
+    if len(candidates) < req.Count {
+        writeValidationErrors(w, r, []fieldError{{Field: "count", Message: fmt.Sprintf("is more than the %d problems matching the filters", len(candidates))}})
+        return
+    }
+    rand.Shuffle(len(candidates), func(i, j int) {
+        candidates[i], candidates[j] = candidates[j], candidates[i]
+    })
+
+    session := database.InterviewSession{
+        UserID:           userID,
+        ListID:           req.ListID,
+        TimeLimitMinutes: req.TimeLimitMinutes,
+        StartedAt:        now,
+        ExpiresAt:        now.Add(time.Duration(req.TimeLimitMinutes) * time.Minute),
+    }
+    for _, id := range candidates[:req.Count] {
+        session.Problems = append(session.Problems, database.InterviewProblem{ProblemID: id})
+    }
+    if err := s.db.CreateInterviewSession(r.Context(), &session); err != nil {
+        writeServiceError(w, r, err, "Failed to start interview session")
+        return
+    }
+    created, err := s.db.GetInterviewSession(r.Context(), session.ID, userID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch interview session")
+        return
+    }
+
+    writeJSON(w, http.StatusOK, newInterviewResponse(*created, now))
+}
+
+// GetUserInterviewsHandler returns the caller's most recent sessions with
+// their scores, newest first.
+func (s *Server) GetUserInterviewsHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+    now := time.Now().UTC()
+
+    sessions, err := s.db.GetUserInterviewSessions(r.Context(), userID, interviewHistorySize)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch interview sessions")
+        return
+    }
+    response := make([]interviewResponse, len(sessions))
+    for i := range sessions {
+        if err := s.expireInterview(r, &sessions[i], now); err != nil {
+            writeServiceError(w, r, err, "Failed to end interview session")
+            return
+        }
+        response[i] = newInterviewResponse(sessions[i], now)
+    }
+
+    writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) GetInterviewHandler(w http.ResponseWriter, r *http.Request) {
+    now := time.Now().UTC()
+    session, ok := s.ownInterview(w, r, now)
+    if !ok {
+        return
+    }
+    writeJSON(w, http.StatusOK, newInterviewResponse(*session, now))
+}
+
+// RecordInterviewOutcomeHandler records how the caller did on one problem
+// of a running session. Recording again replaces the earlier outcome.
+func (s *Server) RecordInterviewOutcomeHandler(w http.ResponseWriter, r *http.Request) {
+    now := time.Now().UTC().Truncate(time.Second)
+    problemID, err := strconv.Atoi(mux.Vars(r)["problemID"])
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, codeBadRequest, "Invalid problem ID", nil)
+        return
+    }
+    session, ok := s.ownInterview(w, r, now)
+    if !ok {
+        return
+    }
+
+    var req interviewOutcomeRequest
+    if !decodeJSON(w, r, &req) {
+        return
+    }
+    req.normalize()
+    if errs := req.validate(session.TimeLimitMinutes); len(errs) > 0 {
+        writeValidationErrors(w, r, errs)
+        return
+    }
+    if interviewStatus(*session, now) != interviewActive {
+        writeError(w, r, http.StatusConflict, codeConflict, "Interview session is over", nil)
+        return
+    }
+
+    if err := s.db.RecordInterviewOutcome(r.Context(), session.ID, problemID, req.Outcome, req.ElapsedSeconds, now); err != nil {
+        writeServiceError(w, r, err, "Failed to record interview outcome")
+        return
+    }
+    s.writeInterview(w, r, session.ID, now)
+}
+
+// EndInterviewHandler ends a running session early. Ending a session that
+// is already over leaves it as it was.
+func (s *Server) EndInterviewHandler(w http.ResponseWriter, r *http.Request) {
+    now := time.Now().UTC().Truncate(time.Second)
+    session, ok := s.ownInterview(w, r, now)
+    if !ok {
+        return
+    }
+
+    if err := s.db.EndInterviewSession(r.Context(), session.ID, now); err != nil {
+        writeServiceError(w, r, err, "Failed to end interview session")
+        return
+    }
+    s.writeInterview(w, r, session.ID, now)
+}
+
+// writeInterview responds with the caller's session as it is stored now.
+func (s *Server) writeInterview(w http.ResponseWriter, r *http.Request, sessionID int, now time.Time) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+    session, err := s.db.GetInterviewSession(r.Context(), sessionID, userID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch interview session")
+        return
+    }
+    writeJSON(w, http.StatusOK, newInterviewResponse(*session, now))
+}
@@ -0,0 +1,299 @@
+package server
+
+import (
+    "errors"
+    "fmt"
+    "log"
+    "net/http"
+    "slices"
+    "strconv"
+    "time"
+
+    "github.com/gorilla/mux"
+    "LeetTracker/auth"
+    "LeetTracker/internal/database"
+)
+
+// standingsCacheTTL bounds how stale a ranking can be. Cached standings are
+// keyed by the leaderboard's version and by visibilityStampKey, so edits and
+// privacy changes show up at once; only new progress snapshots wait for the
+// entry to expire.
+const standingsCacheTTL = 5 * time.Minute
+
+// visibilityStampKey holds a stamp that changes whenever an account's
+// progress visibility or a study group's members change, either of which can
+// hide a participant from a leaderboard.
+const visibilityStampKey = "leaderboard:visibility"
+
+// visibilityStamp returns the current stamp, or 0 before anything has
+// changed.
+func (s *Server) visibilityStamp() int64 {
+    var stamp int64
+    if err := s.cache.Get(visibilityStampKey, &stamp); err != nil {
+        return 0
+    }
+    return stamp
+}
+
+// invalidateStandings makes every cached ranking stale, so participants who
+// are now hidden drop off at once.
+func (s *Server) invalidateStandings() {
+    if err := s.cache.Set(visibilityStampKey, time.Now().UnixNano(), 0); err != nil {
+        log.Printf("Error invalidating leaderboard standings: %v", err)
+    }
+}
+
+type leaderboardResponse struct {
+    database.Leaderboard
+    Window    string              `json:"window"`
+    From      string              `json:"from"`
+    To        string              `json:"to"`
+    Standings []database.Standing `json:"standings"`
+    // Hidden counts participants whose accounts hide their progress from
+    // the leaderboard's creator.
+    Hidden    int                 `json:"hidden"`
+}
+
+// ownLeaderboard loads the leaderboard in the {id} route variable. Other
+// users' leaderboards are reported as not found.
+func (s *Server) ownLeaderboard(w http.ResponseWriter, r *http.Request) (*database.Leaderboard, bool) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+    boardID, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, codeBadRequest, "Invalid leaderboard ID", nil)
+        return nil, false
+    }
+
+    board, err := s.db.GetLeaderboard(r.Context(), boardID, userID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch leaderboard")
+        return nil, false
+    }
+    return board, true
+}
+
+// participantUsernames resolves usernames for a leaderboard created by the
+// caller. A username linked to an account is only accepted if that account's
+// visibility admits the caller. Any other username must exist on LeetCode,
+// and is stored with LeetCode's capitalisation.
+func (s *Server) participantUsernames(w http.ResponseWriter, r *http.Request, usernames []string) ([]string, bool) {
+    viewerID := r.Context().Value(auth.UserIDKey).(string)
+
+    resolved := make([]string, 0, len(usernames))
+    for _, username := range usernames {
+        account, err := s.db.GetAccountByLeetCodeUsername(r.Context(), username)
+        if errors.Is(err, database.ErrNotFound) {
+            profile, err := s.leetcodeClient.UserProfile(r.Context(), username)
+            if err != nil {
+                writeLeetCodeError(w, r, err)
+                return nil, false
+            }
+            username = profile.Username
+            account, err = s.db.GetAccountByLeetCodeUsername(r.Context(), username)
+        }
+        if err != nil && !errors.Is(err, database.ErrNotFound) {
+            writeServiceError(w, r, err, "Failed to fetch account")
+            return nil, false
+        }
+        if account != nil {
+            visible, err := s.canViewProgress(r.Context(), account, viewerID)
+            if err != nil {
+                writeServiceError(w, r, err, "Failed to check group membership")
+                return nil, false
+            }
+            if !visible {
+                writeError(w, r, http.StatusForbidden, codeForbidden, fmt.Sprintf("%s's progress is not visible to you", username), nil)
+                return nil, false
+            }
+        }
+        resolved = append(resolved, username)
+    }
+    return resolved, true
+}
+
+func (s *Server) CreateLeaderboardHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+
+    var req createLeaderboardRequest
+    if !decodeJSON(w, r, &req) {
+        return
+    }
+    req.normalize()
+    if errs := req.validate(); len(errs) > 0 {
+        writeValidationErrors(w, r, errs)
+        return
+    }
+
+    if err := s.db.EnsureUserExists(r.Context(), userID); err != nil {
+        writeServiceError(w, r, err, "Failed to create leaderboard")
+        return
+    }
+    usernames, ok := s.participantUsernames(w, r, req.Usernames)
+    if !ok {
+        return
+    }
+    board := database.Leaderboard{OwnerID: userID, Participants: usernames}
+    req.apply(&board)
+    if err := s.db.CreateLeaderboard(r.Context(), &board); err != nil {
+        writeServiceError(w, r, err, "Failed to create leaderboard")
+        return
+    }
+
+    writeJSON(w, http.StatusOK, board)
+}
+
+func (s *Server) GetUserLeaderboardsHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+
+    boards, err := s.db.GetUserLeaderboards(r.Context(), userID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch leaderboards")
+        return
+    }
+    if boards == nil {
+        boards = []database.Leaderboard{}
+    }
+
+    writeJSON(w, http.StatusOK, boards)
+}
+
+// GetLeaderboardHandler ranks a leaderboard's participants over the
+// requested window.
+func (s *Server) GetLeaderboardHandler(w http.ResponseWriter, r *http.Request) {
+    query := newLeaderboardQuery(r.URL.Query())
+    if errs := query.validate(); len(errs) > 0 {
+        writeValidationErrors(w, r, errs)
+        return
+    }
+    board, ok := s.ownLeaderboard(w, r)
+    if !ok {
+        return
+    }
+
+    from, to := query.dates(time.Now().UTC())
+    key := fmt.Sprintf("leaderboard:%d:%d:%d:%s:%s", board.ID, board.Version, s.visibilityStamp(), from.Format(dateLayout), to.Format(dateLayout))
+    var standings []database.Standing
+    if err := s.cache.Get(key, &standings); err != nil || standings == nil {
+        standings, err = s.db.RankLeaderboard(r.Context(), board, from, to)
+        if err != nil {
+            writeServiceError(w, r, err, "Failed to rank leaderboard")
+            return
+        }
+        if err := s.cache.Set(key, standings, standingsCacheTTL); err != nil {
+            log.Printf("Error storing leaderboard standings in cache: %v", err)
+        }
+    }
+
+    writeJSON(w, http.StatusOK, leaderboardResponse{
+        Leaderboard: *board,
+        Window:      query.Window,
+        From:        from.Format(dateLayout),
+        To:          to.Format(dateLayout),
+        Standings:   standings,
+        Hidden:      len(board.Participants) - len(standings),
+    })
+}
+
+func (s *Server) UpdateLeaderboardHandler(w http.ResponseWriter, r *http.Request) {
+    var req leaderboardSettings
+    if !decodeJSON(w, r, &req) {
+        return
+    }
+    req.normalize()
+    if errs := req.validate(); len(errs) > 0 {
+        writeValidationErrors(w, r, errs)
+        return
+    }
+    board, ok := s.ownLeaderboard(w, r)
+    if !ok {
+        return
+    }
+
+    req.apply(board)
+    if err := s.db.UpdateLeaderboard(r.Context(), board); err != nil {
+        writeServiceError(w, r, err, "Failed to update leaderboard")
+        return
+    }
+
+    writeJSON(w, http.StatusOK, board)
+}
+
+func (s *Server) DeleteLeaderboardHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+    boardID, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, codeBadRequest, "Invalid leaderboard ID", nil)
+        return
+    }
+
+    if err := s.db.DeleteLeaderboard(r.Context(), boardID, userID); err != nil {
+        writeServiceError(w, r, err, "Failed to delete leaderboard")
+        return
+    }
+
+    writeMessage(w, http.StatusOK, "Leaderboard deleted successfully")
+}
+
+// AddLeaderboardParticipantsHandler adds usernames to a leaderboard.
+// Usernames already on it are skipped and do not count towards the limit.
+func (s *Server) AddLeaderboardParticipantsHandler(w http.ResponseWriter, r *http.Request) {
+    var req leaderboardParticipantsRequest
+    if !decodeJSON(w, r, &req) {
+        return
+    }
+    req.normalize()
+    if errs := req.validate(); len(errs) > 0 {
+        writeValidationErrors(w, r, errs)
+        return
+    }
+    board, ok := s.ownLeaderboard(w, r)
+    if !ok {
+        return
+    }
+    usernames, ok := s.participantUsernames(w, r, req.Usernames)
+    if !ok {
+        return
+    }
+
+    count := len(board.Participants)
+    for _, username := range usernames {
+        if !slices.Contains(board.Participants, username) {
+            count++
+        }
+    }
+    if count > maxLeaderboardParticipants {
+        writeValidationErrors(w, r, []fieldError{{Field: "usernames", Message: fmt.Sprintf("would take the leaderboard past %d participants", maxLeaderboardParticipants)}})
+        return
+    }
+    if err := s.db.AddLeaderboardParticipants(r.Context(), board.ID, usernames); err != nil {
+        writeServiceError(w, r, err, "Failed to add participants")
+        return
+    }
+    s.writeLeaderboard(w, r, board.ID)
+}
+
+func (s *Server) RemoveLeaderboardParticipantHandler(w http.ResponseWriter, r *http.Request) {
+    board, ok := s.ownLeaderboard(w, r)
+    if !ok {
+        return
+    }
+
+    if err := s.db.RemoveLeaderboardParticipant(r.Context(), board.ID, mux.Vars(r)["username"]); err != nil {
+        writeServiceError(w, r, err, "Failed to remove participant")
+        return
+    }
+    s.writeLeaderboard(w, r, board.ID)
+}
+
+// writeLeaderboard responds with the caller's leaderboard as stored now.
+func (s *Server) writeLeaderboard(w http.ResponseWriter, r *http.Request, boardID int) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+
+    board, err := s.db.GetLeaderboard(r.Context(), boardID, userID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch leaderboard")
+        return
+    }
+
+    writeJSON(w, http.StatusOK, board)
+}
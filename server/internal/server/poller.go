@@ -0,0 +1,75 @@
+package server
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "time"
+
+    "LeetTracker/auth"
+    "LeetTracker/internal/database"
+    "LeetTracker/internal/poller"
+)
+
+// refreshTimeout bounds a manual refresh, including any wait for a slot
+// under the poller's LeetCode rate limit.
+const refreshTimeout = 20 * time.Second
+
+// pollRunsPageSize caps how many run log entries GetPollRunsHandler returns.
+const pollRunsPageSize = 50
+
+// RefreshProgressHandler records a snapshot of the caller's linked LeetCode
+// username now instead of waiting for the next scheduled poll.
+func (s *Server) RefreshProgressHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+    username, ok := s.linkedUsername(w, r, userID)
+    if !ok {
+        return
+    }
+
+    ctx, cancel := context.WithTimeout(r.Context(), refreshTimeout)
+    defer cancel()
+    counts, err := s.poller.Refresh(ctx, username)
+    var fetchErr *poller.FetchError
+    switch {
+    case errors.Is(err, poller.ErrRateLimited):
+        writeError(w, r, http.StatusTooManyRequests, codeRateLimited, "LeetCode is being polled too often; try again later", nil)
+        return
+    case errors.As(err, &fetchErr):
+        writeLeetCodeError(w, r, fetchErr.Err)
+        return
+    case err != nil:
+        writeServiceError(w, r, err, "Failed to refresh progress")
+        return
+    }
+
+    writeJSON(w, http.StatusOK, counts)
+}
+
+// TriggerPollHandler starts a poll of every linked username in the
+// background and returns its run log entry straight away.
+func (s *Server) TriggerPollHandler(w http.ResponseWriter, r *http.Request) {
+    run, err := s.poller.Begin(r.Context(), database.PollManual)
+    if errors.Is(err, poller.ErrRunning) {
+        writeError(w, r, http.StatusConflict, codeConflict, "A poll is already running", nil)
+        return
+    }
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to start poll")
+        return
+    }
+
+    s.runInBackground("leetcode-poll", func(ctx context.Context) {
+        s.poller.Complete(ctx, run)
+    })
+    writeJSON(w, http.StatusAccepted, run)
+}
+
+func (s *Server) GetPollRunsHandler(w http.ResponseWriter, r *http.Request) {
+    runs, err := s.db.GetPollRuns(r.Context(), pollRunsPageSize)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch poll runs")
+        return
+    }
+    writeJSON(w, http.StatusOK, runs)
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+    "context"
+    "net/http"
+    "time"
+
+    "LeetTracker/auth"
+    "LeetTracker/internal/recommend"
+    "LeetTracker/internal/utils/leetcode"
+)
+
+// catalogPageSize is how many problems are read at a time when loading the
+// whole catalog.
+const catalogPageSize = 1000
+
+// GetRecommendationsHandler suggests what the caller should solve next from
+// their lists, completions and mock interviews. Premium problems are only
+// suggested to users who have said they have LeetCode Premium.
+func (s *Server) GetRecommendationsHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+    query := newRecommendationsQuery(r.URL.Query())
+    if errs := query.validate(); len(errs) > 0 {
+        writeValidationErrors(w, r, errs)
+        return
+    }
+
+    if err := s.db.EnsureUserExists(r.Context(), userID); err != nil {
+        writeServiceError(w, r, err, "Failed to fetch recommendations")
+        return
+    }
+    account, err := s.db.GetAccount(r.Context(), userID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch account")
+        return
+    }
+    activity, err := s.db.GetProblemActivity(r.Context(), userID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch problem activity")
+        return
+    }
+    catalog, err := s.catalog(r.Context())
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch problems")
+        return
+    }
+
+    writeJSON(w, http.StatusOK, recommend.Recommend(catalog, activity, account.LeetCodePremium, time.Now().UTC(), query.limit()))
+}
+
+// catalog reads every stored problem a page at a time.
+func (s *Server) catalog(ctx context.Context) ([]leetcode.Problem, error) {
+    var catalog []leetcode.Problem
+    for page := 1; ; page++ {
+        problems, total, err := s.db.GetLeetCodeProblems(ctx, page, catalogPageSize)
+        if err != nil {
+            return nil, err
+        }
+        catalog = append(catalog, problems...)
+        if len(problems) < catalogPageSize || len(catalog) >= total {
+            return catalog, nil
+        }
+    }
+}
@@ -0,0 +1,804 @@
+package server
+
+import (
+    "fmt"
+    "net/url"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+
+    "LeetTracker/internal/database"
+    "LeetTracker/internal/progress"
+    "LeetTracker/internal/studyplan"
+)
+
+// Limits applied to client supplied payloads.
+const (
+    maxListNameLength          = 100
+    maxListDescriptionLength   = 1000
+    maxListTagsLength          = 255
+    maxEstimatedTimeLength     = 50
+    maxListNotesLength         = 5000
+    maxProblemsPerRequest      = 100
+    maxFeedbackCommentLength   = 500
+    minRating                  = 1
+    maxRating                  = 5
+    maxGoalTarget              = 10000
+    minForecastWindow          = 7
+    maxForecastWindow          = 365
+    defaultForecastWindow      = 28
+    maxCompareUsernames        = 10
+    maxGroupNameLength         = 100
+    maxGroupDescriptionLength  = 1000
+    maxInviteCodeLength        = 32
+    maxLeaderboardNameLength   = 100
+    maxLeaderboardParticipants = 50
+    maxLeaderboardWeight       = 100
+    maxPlanProblemsPerDay      = 20
+    defaultPlanDaysPerWeek     = 5
+    maxInterviewProblems       = 10
+    defaultInterviewProblems   = 3
+    minInterviewMinutes        = 5
+    maxInterviewMinutes        = 240
+    defaultInterviewMinutes    = 45
+    maxFilterTopics            = 20
+    maxTopicLength             = 50
+    maxRecommendations         = 50
+    defaultRecommendations     = 10
+    maxNoRepeatDays            = 365
+    defaultNoRepeatDays        = 30
+    maxDailyHistoryDays        = 365
+    defaultDailyHistoryDays    = 30
+)
+
+var listDifficulties = []string{"easy", "medium", "hard", "mixed"}
+
+// dateLayout is the format of date query parameters.
+const dateLayout = "2006-01-02"
+
+// leetCodeUsername matches the characters LeetCode allows in usernames.
+var leetCodeUsername = regexp.MustCompile(`^[A-Za-z0-9_-]{1,50}$`)
+
+type createListRequest struct {
+    Name          string `json:"name"`
+    Description   string `json:"description"`
+    Tags          string `json:"tags"`
+    Difficulty    string `json:"difficulty"`
+    EstimatedTime string `json:"estimated_time"`
+    Notes         string `json:"notes"`
+}
+
+func (req *createListRequest) normalize() {
+    req.Name = strings.TrimSpace(req.Name)
+    req.Description = strings.TrimSpace(req.Description)
+    req.Tags = strings.TrimSpace(req.Tags)
+    req.Difficulty = strings.ToLower(strings.TrimSpace(req.Difficulty))
+    req.EstimatedTime = strings.TrimSpace(req.EstimatedTime)
+    req.Notes = strings.TrimSpace(req.Notes)
+}
+
+func (req *createListRequest) validate() []fieldError {
+    var v validator
+    v.required("name", req.Name)
+    v.maxLength("name", req.Name, maxListNameLength)
+    v.maxLength("description", req.Description, maxListDescriptionLength)
+    v.maxLength("tags", req.Tags, maxListTagsLength)
+    if req.Difficulty != "" {
+        v.oneOf("difficulty", req.Difficulty, listDifficulties...)
+    }
+    v.maxLength("estimated_time", req.EstimatedTime, maxEstimatedTimeLength)
+    v.maxLength("notes", req.Notes, maxListNotesLength)
+    return v.errors
+}
+
+func (req *createListRequest) toList(userID string) database.List {
+    return database.List{
+        UserID:        userID,
+        Name:          req.Name,
+        Description:   req.Description,
+        Tags:          req.Tags,
+        Difficulty:    req.Difficulty,
+        EstimatedTime: req.EstimatedTime,
+        Notes:         req.Notes,
+    }
+}
+
+type createGroupRequest struct {
+    Name        string `json:"name"`
+    Description string `json:"description"`
+}
+
+func (req *createGroupRequest) normalize() {
+    req.Name = strings.TrimSpace(req.Name)
+    req.Description = strings.TrimSpace(req.Description)
+}
+
+func (req *createGroupRequest) validate() []fieldError {
+    var v validator
+    v.required("name", req.Name)
+    v.maxLength("name", req.Name, maxGroupNameLength)
+    v.maxLength("description", req.Description, maxGroupDescriptionLength)
+    return v.errors
+}
+
+type joinGroupRequest struct {
+    InviteCode string `json:"invite_code"`
+}
+
+// normalize upper-cases the code, so codes read out loud or typed by hand
+// still match.
+func (req *joinGroupRequest) normalize() {
+    req.InviteCode = strings.ToUpper(strings.TrimSpace(req.InviteCode))
+}
+
+func (req *joinGroupRequest) validate() []fieldError {
+    var v validator
+    v.required("invite_code", req.InviteCode)
+    v.maxLength("invite_code", req.InviteCode, maxInviteCodeLength)
+    return v.errors
+}
+
+type groupRoleRequest struct {
+    Role string `json:"role"`
+}
+
+func (req *groupRoleRequest) normalize() {
+    req.Role = strings.ToLower(strings.TrimSpace(req.Role))
+}
+
+func (req *groupRoleRequest) validate() []fieldError {
+    var v validator
+    v.oneOf("role", req.Role, database.RoleAdmin, database.RoleMember)
+    return v.errors
+}
+
+type listRoleRequest struct {
+    Role string `json:"role"`
+}
+
+func (req *listRoleRequest) normalize() {
+    req.Role = strings.ToLower(strings.TrimSpace(req.Role))
+}
+
+func (req *listRoleRequest) validate() []fieldError {
+    var v validator
+    v.oneOf("role", req.Role, database.ListEditor, database.ListViewer)
+    return v.errors
+}
+
+type addProblemsRequest struct {
+    ListID     int   `json:"list_id"`
+    ProblemIDs []int `json:"problem_ids"`
+}
+
+func (req *addProblemsRequest) validate() []fieldError {
+    var v validator
+    v.check(req.ListID > 0, "list_id", "must be a positive integer")
+    v.check(len(req.ProblemIDs) > 0, "problem_ids", "must contain at least one problem")
+    v.check(len(req.ProblemIDs) <= maxProblemsPerRequest, "problem_ids", "must contain at most %d problems", maxProblemsPerRequest)
+
+    seen := make(map[int]bool, len(req.ProblemIDs))
+    for i, id := range req.ProblemIDs {
+        field := fmt.Sprintf("problem_ids[%d]", i)
+        v.check(id > 0, field, "must be a positive integer")
+        v.check(!seen[id], field, "duplicates problem %d", id)
+        seen[id] = true
+    }
+    return v.errors
+}
+
+type removeProblemRequest struct {
+    ListID    int `json:"list_id"`
+    ProblemID int `json:"problem_id"`
+}
+
+func (req *removeProblemRequest) validate() []fieldError {
+    var v validator
+    v.check(req.ListID > 0, "list_id", "must be a positive integer")
+    v.check(req.ProblemID > 0, "problem_id", "must be a positive integer")
+    return v.errors
+}
+
+type updateCompletionRequest struct {
+    Completed *bool `json:"completed"`
+}
+
+func (req *updateCompletionRequest) validate() []fieldError {
+    var v validator
+    v.check(req.Completed != nil, "completed", "is required")
+    return v.errors
+}
+
+type problemFeedbackRequest struct {
+    DifficultyRating    int    `json:"difficulty_rating"`
+    InterviewLikelihood *int   `json:"interview_likelihood"`
+    Comment             string `json:"comment"`
+}
+
+func (req *problemFeedbackRequest) normalize() {
+    req.Comment = strings.TrimSpace(req.Comment)
+}
+
+func (req *problemFeedbackRequest) validate() []fieldError {
+    var v validator
+    v.check(req.DifficultyRating >= minRating && req.DifficultyRating <= maxRating, "difficulty_rating", "must be between %d and %d", minRating, maxRating)
+    if req.InterviewLikelihood != nil {
+        v.check(*req.InterviewLikelihood >= minRating && *req.InterviewLikelihood <= maxRating, "interview_likelihood", "must be between %d and %d", minRating, maxRating)
+    }
+    v.maxLength("comment", req.Comment, maxFeedbackCommentLength)
+    return v.errors
+}
+
+func (req *problemFeedbackRequest) toFeedback(problemID int, userID string) database.ProblemFeedback {
+    return database.ProblemFeedback{
+        ProblemID:           problemID,
+        UserID:              userID,
+        DifficultyRating:    req.DifficultyRating,
+        InterviewLikelihood: req.InterviewLikelihood,
+        Comment:             req.Comment,
+    }
+}
+
+type leetCodeStatsRequest struct {
+    Username string `json:"username"`
+}
+
+func (req *leetCodeStatsRequest) normalize() {
+    req.Username = strings.TrimSpace(req.Username)
+}
+
+func (req *leetCodeStatsRequest) validate() []fieldError {
+    var v validator
+    v.required("username", req.Username)
+    if req.Username != "" {
+        v.check(leetCodeUsername.MatchString(req.Username), "username", "must be a LeetCode username of at most 50 letters, digits, '_' or '-'")
+    }
+    return v.errors
+}
+
+// updateAccountRequest replaces the caller's account settings. An empty
+// leetcode_username unlinks the current one.
+type updateAccountRequest struct {
+    LeetCodeUsername   string `json:"leetcode_username"`
+    ProgressVisibility string `json:"progress_visibility"`
+    // LeetCodePremium keeps its stored value when left out.
+    LeetCodePremium    *bool  `json:"leetcode_premium"`
+}
+
+func (req *updateAccountRequest) normalize() {
+    req.LeetCodeUsername = strings.TrimSpace(req.LeetCodeUsername)
+    req.ProgressVisibility = strings.ToLower(strings.TrimSpace(req.ProgressVisibility))
+}
+
+func (req *updateAccountRequest) validate() []fieldError {
+    var v validator
+    if req.LeetCodeUsername != "" {
+        v.check(leetCodeUsername.MatchString(req.LeetCodeUsername), "leetcode_username", "must be a LeetCode username of at most 50 letters, digits, '_' or '-'")
+    }
+    v.required("progress_visibility", req.ProgressVisibility)
+    if req.ProgressVisibility != "" {
+        v.oneOf("progress_visibility", req.ProgressVisibility, database.Visibilities...)
+    }
+    return v.errors
+}
+
+// historyQuery holds the query parameters of GET /user-progress-history.
+type historyQuery struct {
+    Username    string
+    From        string
+    To          string
+    Granularity string
+}
+
+func newHistoryQuery(q url.Values) historyQuery {
+    return historyQuery{
+        Username:    strings.TrimSpace(q.Get("username")),
+        From:        strings.TrimSpace(q.Get("from")),
+        To:          strings.TrimSpace(q.Get("to")),
+        Granularity: strings.ToLower(strings.TrimSpace(q.Get("granularity"))),
+    }
+}
+
+func (q *historyQuery) validate() []fieldError {
+    var v validator
+    from, fromErr := time.Parse(dateLayout, q.From)
+    if q.From != "" {
+        v.check(fromErr == nil, "from", "must be a date in YYYY-MM-DD format")
+    }
+    to, toErr := time.Parse(dateLayout, q.To)
+    if q.To != "" {
+        v.check(toErr == nil, "to", "must be a date in YYYY-MM-DD format")
+    }
+    if q.From != "" && q.To != "" && fromErr == nil && toErr == nil {
+        v.check(!to.Before(from), "to", "must not be before from")
+    }
+    if q.Granularity != "" {
+        v.oneOf("granularity", q.Granularity, progress.Granularities...)
+    }
+    return v.errors
+}
+
+// toRange converts a validated query. Missing dates stay zero, which Rollup
+// treats as unbounded.
+func (q *historyQuery) toRange() progress.Range {
+    r := progress.Range{Granularity: q.Granularity}
+    if r.Granularity == "" {
+        r.Granularity = progress.Day
+    }
+    if q.From != "" {
+        r.From, _ = time.Parse(dateLayout, q.From)
+    }
+    if q.To != "" {
+        r.To, _ = time.Parse(dateLayout, q.To)
+    }
+    return r
+}
+
+// forecastQuery holds the query parameters of GET /progress/forecast.
+type forecastQuery struct {
+    Username string
+    Target   string
+    Metric   string
+    Window   string
+}
+
+func newForecastQuery(q url.Values) forecastQuery {
+    return forecastQuery{
+        Username: strings.TrimSpace(q.Get("username")),
+        Target:   strings.TrimSpace(q.Get("target")),
+        Metric:   strings.ToLower(strings.TrimSpace(q.Get("metric"))),
+        Window:   strings.TrimSpace(q.Get("window")),
+    }
+}
+
+func (q *forecastQuery) validate() []fieldError {
+    var v validator
+    v.required("target", q.Target)
+    if q.Target != "" {
+        target, err := strconv.Atoi(q.Target)
+        v.check(err == nil && target > 0 && target <= maxGoalTarget, "target", "must be a whole number between 1 and %d", maxGoalTarget)
+    }
+    if q.Metric != "" {
+        v.oneOf("metric", q.Metric, progress.Metrics...)
+    }
+    if q.Window != "" {
+        window, err := strconv.Atoi(q.Window)
+        v.check(err == nil && window >= minForecastWindow && window <= maxForecastWindow, "window", "must be a number of days between %d and %d", minForecastWindow, maxForecastWindow)
+    }
+    return v.errors
+}
+
+// values returns the validated target, metric and window with defaults
+// applied.
+func (q *forecastQuery) values() (int, string, int) {
+    target, _ := strconv.Atoi(q.Target)
+    metric := q.Metric
+    if metric == "" {
+        metric = progress.Total
+    }
+    window := defaultForecastWindow
+    if q.Window != "" {
+        window, _ = strconv.Atoi(q.Window)
+    }
+    return target, metric, window
+}
+
+// recommendationsQuery holds the query parameters of GET /recommendations.
+type recommendationsQuery struct {
+    Limit string
+}
+
+func newRecommendationsQuery(q url.Values) recommendationsQuery {
+    return recommendationsQuery{Limit: strings.TrimSpace(q.Get("limit"))}
+}
+
+func (q *recommendationsQuery) validate() []fieldError {
+    var v validator
+    if q.Limit != "" {
+        limit, err := strconv.Atoi(q.Limit)
+        v.check(err == nil && limit >= 1 && limit <= maxRecommendations, "limit", "must be a whole number between 1 and %d", maxRecommendations)
+    }
+    return v.errors
+}
+
+// limit returns the validated limit, which defaults to
+// defaultRecommendations.
+func (q *recommendationsQuery) limit() int {
+    if q.Limit == "" {
+        return defaultRecommendations
+    }
+    limit, _ := strconv.Atoi(q.Limit)
+    return limit
+}
+
+// compareQuery holds the query parameters of GET /progress/compare. The
+// range and granularity are validated as for the history.
+type compareQuery struct {
+    Usernames []string
+    history   historyQuery
+}
+
+func newCompareQuery(q url.Values) compareQuery {
+    query := compareQuery{history: newHistoryQuery(q)}
+    for _, username := range strings.Split(q.Get("usernames"), ",") {
+        if username = strings.TrimSpace(username); username != "" {
+            query.Usernames = append(query.Usernames, username)
+        }
+    }
+    return query
+}
+
+func (q *compareQuery) validate() []fieldError {
+    var v validator
+    v.check(len(q.Usernames) > 0, "usernames", "is required")
+    v.usernames(q.Usernames, maxCompareUsernames)
+    return append(v.errors, q.history.validate()...)
+}
+
+// usernames checks a list of at most max LeetCode usernames, which must not
+// repeat in any capitalisation.
+func (v *validator) usernames(usernames []string, max int) {
+    v.check(len(usernames) <= max, "usernames", "must list at most %d usernames", max)
+    seen := make(map[string]bool, len(usernames))
+    for i, username := range usernames {
+        field := fmt.Sprintf("usernames[%d]", i)
+        v.check(leetCodeUsername.MatchString(username), field, "must be a LeetCode username of at most 50 letters, digits, '_' or '-'")
+        v.check(!seen[strings.ToLower(username)], field, "duplicates %s", username)
+        seen[strings.ToLower(username)] = true
+    }
+}
+
+// Leaderboard windows. A week starts on Monday, and both week and month run
+// up to today.
+const (
+    windowWeek   = "week"
+    windowMonth  = "month"
+    windowCustom = "custom"
+)
+
+var leaderboardWindows = []string{windowWeek, windowMonth, windowCustom}
+
+// leaderboardSettings are the fields shared by creating and updating a
+// leaderboard.
+type leaderboardSettings struct {
+    Name     string            `json:"name"`
+    Weights  *database.Weights `json:"weights"`
+    TieBreak string            `json:"tie_break"`
+}
+
+// normalize fills in the defaults: every problem is worth one point, and
+// ties go to whoever solved more hard problems.
+func (req *leaderboardSettings) normalize() {
+    req.Name = strings.TrimSpace(req.Name)
+    req.TieBreak = strings.ToLower(strings.TrimSpace(req.TieBreak))
+    if req.TieBreak == "" {
+        req.TieBreak = database.TieBreakHardest
+    }
+    if req.Weights == nil {
+        req.Weights = &database.Weights{Easy: 1, Medium: 1, Hard: 1}
+    }
+}
+
+func (req *leaderboardSettings) validate() []fieldError {
+    var v validator
+    v.required("name", req.Name)
+    v.maxLength("name", req.Name, maxLeaderboardNameLength)
+    v.oneOf("tie_break", req.TieBreak, database.TieBreaks...)
+    v.weight("weights.easy", req.Weights.Easy)
+    v.weight("weights.medium", req.Weights.Medium)
+    v.weight("weights.hard", req.Weights.Hard)
+    v.check(req.Weights.Easy+req.Weights.Medium+req.Weights.Hard > 0, "weights", "must give points for at least one difficulty")
+    return v.errors
+}
+
+func (v *validator) weight(field string, weight int) {
+    v.check(weight >= 0 && weight <= maxLeaderboardWeight, field, "must be between 0 and %d", maxLeaderboardWeight)
+}
+
+func (req *leaderboardSettings) apply(board *database.Leaderboard) {
+    board.Name = req.Name
+    board.Weights = *req.Weights
+    board.TieBreak = req.TieBreak
+}
+
+type createLeaderboardRequest struct {
+    leaderboardSettings
+    Usernames []string `json:"usernames"`
+}
+
+func (req *createLeaderboardRequest) normalize() {
+    req.leaderboardSettings.normalize()
+    req.Usernames = trimUsernames(req.Usernames)
+}
+
+func (req *createLeaderboardRequest) validate() []fieldError {
+    var v validator
+    v.usernames(req.Usernames, maxLeaderboardParticipants)
+    return append(req.leaderboardSettings.validate(), v.errors...)
+}
+
+type leaderboardParticipantsRequest struct {
+    Usernames []string `json:"usernames"`
+}
+
+func (req *leaderboardParticipantsRequest) normalize() {
+    req.Usernames = trimUsernames(req.Usernames)
+}
+
+func (req *leaderboardParticipantsRequest) validate() []fieldError {
+    var v validator
+    v.check(len(req.Usernames) > 0, "usernames", "is required")
+    v.usernames(req.Usernames, maxLeaderboardParticipants)
+    return v.errors
+}
+
+func trimUsernames(usernames []string) []string {
+    for i, username := range usernames {
+        usernames[i] = strings.TrimSpace(username)
+    }
+    return usernames
+}
+
+// leaderboardQuery holds the query parameters of GET /leaderboards/{id}.
+type leaderboardQuery struct {
+    Window string
+    From   string
+    To     string
+}
+
+func newLeaderboardQuery(q url.Values) leaderboardQuery {
+    query := leaderboardQuery{
+        Window: strings.ToLower(strings.TrimSpace(q.Get("window"))),
+        From:   strings.TrimSpace(q.Get("from")),
+        To:     strings.TrimSpace(q.Get("to")),
+    }
+    if query.Window == "" {
+        query.Window = windowWeek
+    }
+    return query
+}
+
+func (q *leaderboardQuery) validate() []fieldError {
+    var v validator
+    v.oneOf("window", q.Window, leaderboardWindows...)
+    if q.Window != windowCustom {
+        v.check(q.From == "" && q.To == "", "window", "must be %s when from or to is given", windowCustom)
+        return v.errors
+    }
+    v.required("from", q.From)
+    v.required("to", q.To)
+    if q.From == "" || q.To == "" {
+        return v.errors
+    }
+    from, fromErr := time.Parse(dateLayout, q.From)
+    v.check(fromErr == nil, "from", "must be a date in YYYY-MM-DD format")
+    to, toErr := time.Parse(dateLayout, q.To)
+    v.check(toErr == nil, "to", "must be a date in YYYY-MM-DD format")
+    if fromErr == nil && toErr == nil {
+        v.check(!to.Before(from), "to", "must not be before from")
+    }
+    return v.errors
+}
+
+// dates returns the first and last day of a validated query's window.
+func (q *leaderboardQuery) dates(today time.Time) (time.Time, time.Time) {
+    today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+    switch q.Window {
+    case windowMonth:
+        return today.AddDate(0, 0, 1-today.Day()), today
+    case windowCustom:
+        from, _ := time.Parse(dateLayout, q.From)
+        to, _ := time.Parse(dateLayout, q.To)
+        return from, to
+    }
+    return today.AddDate(0, 0, -(int(today.Weekday())+6)%7), today
+}
+
+type createStudyPlanRequest struct {
+    ListID         int    `json:"list_id"`
+    StartDate      string `json:"start_date"`
+    DaysPerWeek    int    `json:"days_per_week"`
+    ProblemsPerDay int    `json:"problems_per_day"`
+}
+
+func (req *createStudyPlanRequest) normalize() {
+    req.StartDate = strings.TrimSpace(req.StartDate)
+    if req.DaysPerWeek == 0 {
+        req.DaysPerWeek = defaultPlanDaysPerWeek
+    }
+    if req.ProblemsPerDay == 0 {
+        req.ProblemsPerDay = 1
+    }
+}
+
+func (req *createStudyPlanRequest) validate() []fieldError {
+    var v validator
+    v.check(req.ListID > 0, "list_id", "must be a positive integer")
+    if req.StartDate != "" {
+        _, err := time.Parse(dateLayout, req.StartDate)
+        v.check(err == nil, "start_date", "must be a date in YYYY-MM-DD format")
+    }
+    v.check(req.DaysPerWeek >= 1 && req.DaysPerWeek <= 7, "days_per_week", "must be between 1 and 7")
+    v.check(req.ProblemsPerDay >= 1 && req.ProblemsPerDay <= maxPlanProblemsPerDay, "problems_per_day", "must be between 1 and %d", maxPlanProblemsPerDay)
+    return v.errors
+}
+
+// start returns a validated request's start date, which defaults to today.
+func (req *createStudyPlanRequest) start(today time.Time) time.Time {
+    if req.StartDate == "" {
+        return studyplan.Day(today)
+    }
+    start, _ := time.Parse(dateLayout, req.StartDate)
+    return start
+}
+
+var problemDifficulties = []string{"easy", "medium", "hard"}
+
+// normalizeProblemFilters lower-cases the difficulty and topic filters of a
+// request in place.
+func normalizeProblemFilters(difficulties, topics []string) {
+    for i, difficulty := range difficulties {
+        difficulties[i] = strings.ToLower(strings.TrimSpace(difficulty))
+    }
+    for i, topic := range topics {
+        topics[i] = strings.ToLower(strings.TrimSpace(topic))
+    }
+}
+
+// problemFilters checks the difficulty and topic filters of a request.
+func (v *validator) problemFilters(difficulties, topics []string) {
+    for i, difficulty := range difficulties {
+        v.oneOf(fmt.Sprintf("difficulties[%d]", i), difficulty, problemDifficulties...)
+    }
+    v.check(len(topics) <= maxFilterTopics, "topics", "must list at most %d topics", maxFilterTopics)
+    for i, topic := range topics {
+        field := fmt.Sprintf("topics[%d]", i)
+        v.required(field, topic)
+        v.maxLength(field, topic, maxTopicLength)
+    }
+}
+
+// createInterviewRequest describes where to draw a mock interview's problems
+// from. Every filter left empty matches the whole catalog.
+type createInterviewRequest struct {
+    ListID           *int     `json:"list_id"`
+    Difficulties     []string `json:"difficulties"`
+    Topics           []string `json:"topics"`
+    ExcludeSolved    bool     `json:"exclude_solved"`
+    ExcludePremium   bool     `json:"exclude_premium"`
+    Count            int      `json:"count"`
+    TimeLimitMinutes int      `json:"time_limit_minutes"`
+}
+
+func (req *createInterviewRequest) normalize() {
+    normalizeProblemFilters(req.Difficulties, req.Topics)
+    if req.Count == 0 {
+        req.Count = defaultInterviewProblems
+    }
+    if req.TimeLimitMinutes == 0 {
+        req.TimeLimitMinutes = defaultInterviewMinutes
+    }
+}
+
+func (req *createInterviewRequest) validate() []fieldError {
+    var v validator
+    if req.ListID != nil {
+        v.check(*req.ListID > 0, "list_id", "must be a positive integer")
+    }
+    v.problemFilters(req.Difficulties, req.Topics)
+    v.check(req.Count >= 1 && req.Count <= maxInterviewProblems, "count", "must be between 1 and %d", maxInterviewProblems)
+    v.check(req.TimeLimitMinutes >= minInterviewMinutes && req.TimeLimitMinutes <= maxInterviewMinutes, "time_limit_minutes", "must be between %d and %d", minInterviewMinutes, maxInterviewMinutes)
+    return v.errors
+}
+
+func (req *createInterviewRequest) filter(userID string) database.ProblemFilter {
+    filter := database.ProblemFilter{
+        ListID:         req.ListID,
+        Difficulties:   req.Difficulties,
+        Topics:         req.Topics,
+        ExcludePremium: req.ExcludePremium,
+    }
+    if req.ExcludeSolved {
+        filter.ExcludeSolvedBy = userID
+    }
+    return filter
+}
+
+type interviewOutcomeRequest struct {
+    Outcome        string `json:"outcome"`
+    ElapsedSeconds int    `json:"elapsed_seconds"`
+}
+
+func (req *interviewOutcomeRequest) normalize() {
+    req.Outcome = strings.ToLower(strings.TrimSpace(req.Outcome))
+}
+
+// validate checks req against a session with the given time limit; no
+// problem can take longer than the whole session.
+func (req *interviewOutcomeRequest) validate(timeLimitMinutes int) []fieldError {
+    var v validator
+    v.oneOf("outcome", req.Outcome, database.Outcomes...)
+    v.check(req.ElapsedSeconds >= 0 && req.ElapsedSeconds <= timeLimitMinutes*60, "elapsed_seconds", "must be between 0 and %d", timeLimitMinutes*60)
+    return v.errors
+}
+
+// dailySettingsRequest configures a list's problem of the day.
+type dailySettingsRequest struct {
+    Source         string   `json:"source"`
+    Difficulties   []string `json:"difficulties"`
+    Topics         []string `json:"topics"`
+    ExcludePremium bool     `json:"exclude_premium"`
+    NoRepeatDays   *int     `json:"no_repeat_days"`
+}
+
+func (req *dailySettingsRequest) normalize() {
+    req.Source = strings.ToLower(strings.TrimSpace(req.Source))
+    if req.Source == "" {
+        req.Source = database.DailySourceList
+    }
+    normalizeProblemFilters(req.Difficulties, req.Topics)
+    if req.NoRepeatDays == nil {
+        days := defaultNoRepeatDays
+        req.NoRepeatDays = &days
+    }
+}
+
+func (req *dailySettingsRequest) validate() []fieldError {
+    var v validator
+    v.oneOf("source", req.Source, database.DailySources...)
+    v.problemFilters(req.Difficulties, req.Topics)
+    // Settings store their topics comma separated.
+    for i, topic := range req.Topics {
+        v.check(!strings.Contains(topic, ","), fmt.Sprintf("topics[%d]", i), "must not contain commas")
+    }
+    v.check(*req.NoRepeatDays >= 0 && *req.NoRepeatDays <= maxNoRepeatDays, "no_repeat_days", "must be between 0 and %d", maxNoRepeatDays)
+    return v.errors
+}
+
+func (req *dailySettingsRequest) toSettings(listID int) database.DailySettings {
+    settings := database.DailySettings{
+        ListID:         listID,
+        Source:         req.Source,
+        Difficulties:   req.Difficulties,
+        Topics:         req.Topics,
+        ExcludePremium: req.ExcludePremium,
+        NoRepeatDays:   *req.NoRepeatDays,
+    }
+    if settings.Difficulties == nil {
+        settings.Difficulties = []string{}
+    }
+    if settings.Topics == nil {
+        settings.Topics = []string{}
+    }
+    return settings
+}
+
+// dailyHistoryQuery holds the query parameters of GET
+// /lists/{id}/daily/history.
+type dailyHistoryQuery struct {
+    Days string
+}
+
+func newDailyHistoryQuery(q url.Values) dailyHistoryQuery {
+    return dailyHistoryQuery{Days: strings.TrimSpace(q.Get("days"))}
+}
+
+func (q *dailyHistoryQuery) validate() []fieldError {
+    var v validator
+    if q.Days != "" {
+        days, err := strconv.Atoi(q.Days)
+        v.check(err == nil && days >= 1 && days <= maxDailyHistoryDays, "days", "must be a number of days between 1 and %d", maxDailyHistoryDays)
+    }
+    return v.errors
+}
+
+// days returns the validated number of days, which defaults to
+// defaultDailyHistoryDays.
+func (q *dailyHistoryQuery) days() int {
+    if q.Days == "" {
+        return defaultDailyHistoryDays
+    }
+    days, _ := strconv.Atoi(q.Days)
+    return days
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "log"
+    "net/http"
+
+    "LeetTracker/internal/database"
+    "LeetTracker/internal/utils/leetcode"
+)
+
+// Error codes returned in the "code" field of the error envelope.
+const (
+    codeBadRequest       = "bad_request"
+    codeUnauthorized     = "unauthorized"
+    codeForbidden        = "forbidden"
+    codeNotFound         = "not_found"
+    codeMethodNotAllowed = "method_not_allowed"
+    codeConflict         = "conflict"
+    codeValidationFailed = "validation_failed"
+    codeBadGateway       = "bad_gateway"
+    codeRateLimited      = "rate_limited"
+    codeClientClosed     = "client_closed_request"
+    codeTimeout          = "timeout"
+    codeInternal         = "internal_error"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// statusClientClosedRequest is nginx's non-standard status for a request the
+// client abandoned before the response was ready.
+const statusClientClosedRequest = 499
+
+type requestIDKey struct{}
+
+type errorBody struct {
+    Code      string      `json:"code"`
+    Message   string      `json:"message"`
+    Details   interface{} `json:"details,omitempty"`
+    RequestID string      `json:"request_id,omitempty"`
+}
+
+type errorEnvelope struct {
+    Error errorBody `json:"error"`
+}
+
+// requestIDMiddleware tags every request with an ID, reusing the caller's
+// X-Request-ID when present, and echoes it back in the response headers.
+func requestIDMiddleware(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        id := r.Header.Get(requestIDHeader)
+        if id == "" || len(id) > 64 {
+            id = newRequestID()
+        }
+        w.Header().Set(requestIDHeader, id)
+        ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+        next.ServeHTTP(w, r.WithContext(ctx))
+    })
+}
+
+func newRequestID() string {
+    b := make([]byte, 8)
+    if _, err := rand.Read(b); err != nil {
+        return ""
+    }
+    return hex.EncodeToString(b)
+}
+
+func requestIDFrom(r *http.Request) string {
+    id, _ := r.Context().Value(requestIDKey{}).(string)
+    return id
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+    payload, err := json.Marshal(v)
+    if err != nil {
+        log.Printf("error handling JSON marshal. Err: %v", err)
+        w.Header().Set("Content-Type", "application/json")
+        w.WriteHeader(http.StatusInternalServerError)
+        _, _ = w.Write([]byte(`{"error":{"code":"internal_error","message":"Internal Server Error"}}`))
+        return
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    _, _ = w.Write(payload)
+}
+
+func writeMessage(w http.ResponseWriter, status int, message string) {
+    writeJSON(w, status, map[string]string{"message": message})
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string, details interface{}) {
+    writeJSON(w, status, errorEnvelope{Error: errorBody{
+        Code:      code,
+        Message:   message,
+        Details:   details,
+        RequestID: requestIDFrom(r),
+    }})
+}
+
+// writeServiceError maps an error returned by database.Service onto the
+// envelope. Domain errors keep their message; anything else is logged and
+// replaced by fallback so driver details never reach the client.
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+    var domainErr *database.Error
+    message := fallback
+    if errors.As(err, &domainErr) {
+        message = domainErr.Message
+    }
+
+    var canceledErr *database.CanceledError
+    switch {
+    case errors.As(err, &canceledErr) && canceledErr.Timeout():
+        log.Printf("[%s] %s: %v", requestIDFrom(r), fallback, err)
+        writeError(w, r, http.StatusServiceUnavailable, codeTimeout, "The database did not respond in time", nil)
+    case errors.As(err, &canceledErr):
+        // The client is gone; the status is only visible in access logs.
+        writeError(w, r, statusClientClosedRequest, codeClientClosed, "Request cancelled by client", nil)
+    case errors.Is(err, database.ErrNotFound):
+        writeError(w, r, http.StatusNotFound, codeNotFound, message, nil)
+    case errors.Is(err, database.ErrForbidden):
+        writeError(w, r, http.StatusForbidden, codeForbidden, message, nil)
+    case errors.Is(err, database.ErrConflict):
+        writeError(w, r, http.StatusConflict, codeConflict, message, nil)
+    case errors.Is(err, database.ErrValidation):
+        writeError(w, r, http.StatusUnprocessableEntity, codeValidationFailed, message, nil)
+    default:
+        log.Printf("[%s] %s: %v", requestIDFrom(r), fallback, err)
+        writeError(w, r, http.StatusInternalServerError, codeInternal, fallback, nil)
+    }
+}
+
+// writeLeetCodeError reports a failed LeetCode API call. Anything other than
+// an unknown user or a departed client is LeetCode's fault, hence 502.
+func writeLeetCodeError(w http.ResponseWriter, r *http.Request, err error) {
+    switch {
+    case errors.Is(err, leetcode.ErrUserNotFound):
+        writeError(w, r, http.StatusNotFound, codeNotFound, "LeetCode user not found", nil)
+    case r.Context().Err() != nil:
+        writeError(w, r, statusClientClosedRequest, codeClientClosed, "Request cancelled by client", nil)
+    default:
+        log.Printf("[%s] LeetCode request failed: %v", requestIDFrom(r), err)
+        writeError(w, r, http.StatusBadGateway, codeBadGateway, "LeetCode could not be reached", nil)
+    }
+}
+
+func writeUnauthorized(w http.ResponseWriter, r *http.Request, message string) {
+    writeError(w, r, http.StatusUnauthorized, codeUnauthorized, message, nil)
+}
+
+func notFoundHandler(w http.ResponseWriter, r *http.Request) {
+    writeError(w, r, http.StatusNotFound, codeNotFound, "Route not found", nil)
+}
+
+func methodNotAllowedHandler(w http.ResponseWriter, r *http.Request) {
+    writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed", nil)
+}
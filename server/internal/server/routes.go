@@ -6,30 +6,94 @@ import (
     "LeetTracker/auth"
 )
 
-func RegisterRoutes(r *mux.Router, s *Server, jwtMiddleware func(http.Handler) http.Handler) {
+func RegisterRoutes(r *mux.Router, s *Server) {
+    requireUser := auth.Middleware(s.authenticator, writeUnauthorized)
+    optionalUser := auth.OptionalMiddleware(s.authenticator, writeUnauthorized)
+    r.NotFoundHandler = requestIDMiddleware(http.HandlerFunc(notFoundHandler))
+    r.MethodNotAllowedHandler = requestIDMiddleware(http.HandlerFunc(methodNotAllowedHandler))
+
     r.Handle("/", http.FileServer(http.Dir("./views/")))
     r.PathPrefix("/static/").Handler(http.StripPrefix("/static/", http.FileServer(http.Dir("./static/"))))
     //testers
-    r.HandleFunc("/hello", HelloWorldHandler).Methods("GET")
-    r.HandleFunc("/health", HealthHandler(s.db)).Methods("GET")
+    r.HandleFunc("/hello", s.HelloWorldHandler).Methods("GET")
+    r.HandleFunc("/healthz", s.LivenessHandler).Methods("GET")
+    r.HandleFunc("/readyz", s.ReadinessHandler).Methods("GET")
+    //admin
+    r.Handle("/admin/db-stats", requireUser(s.adminOnly(http.HandlerFunc(s.DatabaseStatsHandler)))).Methods("GET")
+    r.Handle("/admin/poll-runs", requireUser(s.adminOnly(http.HandlerFunc(s.GetPollRunsHandler)))).Methods("GET")
+    r.Handle("/admin/poll-runs", requireUser(s.adminOnly(http.HandlerFunc(s.TriggerPollHandler)))).Methods("POST")
     //actual routes
-    //r.Handle("/products", jwtMiddleware(http.HandlerFunc(ProductsHandler))).Methods("GET")
-    r.Handle("/products", jwtMiddleware(auth.UserIDMiddleware(http.HandlerFunc(ProductsHandler)))).Methods("GET")
-    r.Handle("/products/{slug}/feedback", jwtMiddleware(http.HandlerFunc(AddFeedbackHandler))).Methods("POST")
     r.HandleFunc("/fetch-leetcode-problems", s.FetchLeetCodeProblemsHandler).Methods("GET")
     //redis
     r.HandleFunc("/invalidate-leetcode-cache", s.InvalidateLeetCodeCacheHandler).Methods("POST")
     //Lists
-    r.Handle("/lists", jwtMiddleware(auth.UserIDMiddleware(http.HandlerFunc(s.CreateListHandler)))).Methods("POST")
-    r.Handle("/getlists", jwtMiddleware(auth.UserIDMiddleware(http.HandlerFunc(s.GetUserListsHandler)))).Methods("GET")
-    r.Handle("/lists/{id}/items", jwtMiddleware(auth.UserIDMiddleware(http.HandlerFunc(s.GetListItemsHandler)))).Methods("GET")
+    r.Handle("/lists", requireUser(http.HandlerFunc(s.CreateListHandler))).Methods("POST")
+    r.Handle("/getlists", requireUser(http.HandlerFunc(s.GetUserListsHandler))).Methods("GET")
+    r.Handle("/lists/{id}/items", requireUser(http.HandlerFunc(s.GetListItemsHandler))).Methods("GET")
+    r.Handle("/lists/{id}/members", requireUser(http.HandlerFunc(s.GetListMembersHandler))).Methods("GET")
+    r.Handle("/lists/{id}/members/{userID}", requireUser(http.HandlerFunc(s.SetListMemberHandler))).Methods("PUT")
+    r.Handle("/lists/{id}/members/{userID}", requireUser(http.HandlerFunc(s.RemoveListMemberHandler))).Methods("DELETE")
     r.HandleFunc("/leetcode-problems", s.GetLeetCodeProblemsHandler).Methods("GET")
+    //Problem feedback
+    r.HandleFunc("/problems/{id}/feedback", s.GetProblemFeedbackHandler).Methods("GET")
+    r.Handle("/problems/{id}/feedback", requireUser(http.HandlerFunc(s.SaveProblemFeedbackHandler))).Methods("PUT")
+    r.Handle("/problems/{id}/feedback", requireUser(http.HandlerFunc(s.DeleteProblemFeedbackHandler))).Methods("DELETE")
     //Add problem to list 
-    r.Handle("/lists/add-problem", jwtMiddleware(auth.UserIDMiddleware(http.HandlerFunc(s.AddProblemToListHandler)))).Methods("POST")
-    r.Handle("/lists/{id}", jwtMiddleware(auth.UserIDMiddleware(http.HandlerFunc(s.DeleteListHandler)))).Methods("DELETE")
+    r.Handle("/lists/add-problem", requireUser(http.HandlerFunc(s.AddProblemToListHandler))).Methods("POST")
+    r.Handle("/lists/{id}", requireUser(http.HandlerFunc(s.DeleteListHandler))).Methods("DELETE")
     //Remove problem from list
-    r.Handle("/lists/remove-problem", jwtMiddleware(auth.UserIDMiddleware(http.HandlerFunc(s.RemoveProblemFromListHandler)))).Methods("POST")
-    r.HandleFunc("/list-items/{id}/completion", s.UpdateProblemCompletionStatusHandler).Methods("PUT")
-    r.HandleFunc("/leetcode-stats", s.LeetCodeStatsProxyHandler).Methods("POST")
-    r.HandleFunc("/user-progress-history", s.GetUserProgressHistoryHandler).Methods("GET")
+    r.Handle("/lists/remove-problem", requireUser(http.HandlerFunc(s.RemoveProblemFromListHandler))).Methods("POST")
+    r.Handle("/list-items/{id}/completion", requireUser(http.HandlerFunc(s.UpdateProblemCompletionStatusHandler))).Methods("PUT")
+    r.HandleFunc("/leetcode-stats", s.LeetCodeStatsHandler).Methods("POST")
+    r.Handle("/user-progress-history", optionalUser(http.HandlerFunc(s.GetUserProgressHistoryHandler))).Methods("GET")
+    r.Handle("/progress/compare", optionalUser(http.HandlerFunc(s.CompareProgressHandler))).Methods("GET")
+    r.Handle("/progress/forecast", optionalUser(http.HandlerFunc(s.GetProgressForecastHandler))).Methods("GET")
+    //Study groups
+    r.Handle("/groups", requireUser(http.HandlerFunc(s.CreateGroupHandler))).Methods("POST")
+    r.Handle("/groups", requireUser(http.HandlerFunc(s.GetUserGroupsHandler))).Methods("GET")
+    r.Handle("/groups/join", requireUser(http.HandlerFunc(s.JoinGroupHandler))).Methods("POST")
+    r.Handle("/groups/{id}", requireUser(http.HandlerFunc(s.GetGroupHandler))).Methods("GET")
+    r.Handle("/groups/{id}", requireUser(http.HandlerFunc(s.DeleteGroupHandler))).Methods("DELETE")
+    r.Handle("/groups/{id}/invite-code", requireUser(http.HandlerFunc(s.RotateInviteCodeHandler))).Methods("POST")
+    r.Handle("/groups/{id}/leave", requireUser(http.HandlerFunc(s.LeaveGroupHandler))).Methods("POST")
+    r.Handle("/groups/{id}/members/{userID}/role", requireUser(http.HandlerFunc(s.SetGroupMemberRoleHandler))).Methods("PUT")
+    r.Handle("/groups/{id}/members/{userID}", requireUser(http.HandlerFunc(s.RemoveGroupMemberHandler))).Methods("DELETE")
+    r.Handle("/groups/{id}/lists", requireUser(http.HandlerFunc(s.GetGroupListsHandler))).Methods("GET")
+    r.Handle("/groups/{id}/lists", requireUser(http.HandlerFunc(s.CreateGroupListHandler))).Methods("POST")
+    r.Handle("/groups/{id}/progress", requireUser(http.HandlerFunc(s.GetGroupProgressHandler))).Methods("GET")
+    //Leaderboards
+    r.Handle("/leaderboards", requireUser(http.HandlerFunc(s.CreateLeaderboardHandler))).Methods("POST")
+    r.Handle("/leaderboards", requireUser(http.HandlerFunc(s.GetUserLeaderboardsHandler))).Methods("GET")
+    r.Handle("/leaderboards/{id}", requireUser(http.HandlerFunc(s.GetLeaderboardHandler))).Methods("GET")
+    r.Handle("/leaderboards/{id}", requireUser(http.HandlerFunc(s.UpdateLeaderboardHandler))).Methods("PUT")
+    r.Handle("/leaderboards/{id}", requireUser(http.HandlerFunc(s.DeleteLeaderboardHandler))).Methods("DELETE")
+    r.Handle("/leaderboards/{id}/participants", requireUser(http.HandlerFunc(s.AddLeaderboardParticipantsHandler))).Methods("POST")
+    r.Handle("/leaderboards/{id}/participants/{username}", requireUser(http.HandlerFunc(s.RemoveLeaderboardParticipantHandler))).Methods("DELETE")
+    //Study plans
+    r.Handle("/plans", requireUser(http.HandlerFunc(s.CreateStudyPlanHandler))).Methods("POST")
+    r.Handle("/plans", requireUser(http.HandlerFunc(s.GetUserStudyPlansHandler))).Methods("GET")
+    r.Handle("/plans/today", requireUser(http.HandlerFunc(s.GetTodaysProblemsHandler))).Methods("GET")
+    r.Handle("/plans/{id}", requireUser(http.HandlerFunc(s.GetStudyPlanHandler))).Methods("GET")
+    r.Handle("/plans/{id}", requireUser(http.HandlerFunc(s.DeleteStudyPlanHandler))).Methods("DELETE")
+    r.Handle("/plans/{id}/rebalance", requireUser(http.HandlerFunc(s.RebalanceStudyPlanHandler))).Methods("POST")
+    //Mock interviews
+    r.Handle("/interviews", requireUser(http.HandlerFunc(s.StartInterviewHandler))).Methods("POST")
+    r.Handle("/interviews", requireUser(http.HandlerFunc(s.GetUserInterviewsHandler))).Methods("GET")
+    r.Handle("/interviews/{id}", requireUser(http.HandlerFunc(s.GetInterviewHandler))).Methods("GET")
+    r.Handle("/interviews/{id}/problems/{problemID}", requireUser(http.HandlerFunc(s.RecordInterviewOutcomeHandler))).Methods("PUT")
+    r.Handle("/interviews/{id}/end", requireUser(http.HandlerFunc(s.EndInterviewHandler))).Methods("POST")
+    //Problem of the day
+    r.Handle("/lists/{id}/daily", requireUser(http.HandlerFunc(s.GetDailyProblemHandler))).Methods("GET")
+    r.Handle("/lists/{id}/daily/history", requireUser(http.HandlerFunc(s.GetDailyProblemHistoryHandler))).Methods("GET")
+    r.Handle("/lists/{id}/daily/settings", requireUser(http.HandlerFunc(s.GetDailySettingsHandler))).Methods("GET")
+    r.Handle("/lists/{id}/daily/settings", requireUser(http.HandlerFunc(s.UpdateDailySettingsHandler))).Methods("PUT")
+    r.Handle("/lists/{id}/daily/settings", requireUser(http.HandlerFunc(s.DeleteDailySettingsHandler))).Methods("DELETE")
+    r.Handle("/lists/{id}/daily/{date}/completion", requireUser(http.HandlerFunc(s.UpdateDailyProblemCompletionHandler))).Methods("PUT")
+    //Recommendations
+    r.Handle("/recommendations", requireUser(http.HandlerFunc(s.GetRecommendationsHandler))).Methods("GET")
+    //Account
+    r.Handle("/me/account", requireUser(http.HandlerFunc(s.GetAccountHandler))).Methods("GET")
+    r.Handle("/me/account", requireUser(http.HandlerFunc(s.UpdateAccountHandler))).Methods("PUT")
+    r.Handle("/me/progress/refresh", requireUser(http.HandlerFunc(s.RefreshProgressHandler))).Methods("POST")
+    r.Handle("/me/submissions/import", requireUser(http.HandlerFunc(s.ImportSubmissionsHandler))).Methods("POST")
 }
@@ -1,53 +1,232 @@
 package server
 
 import (
+    "context"
+    "errors"
     "fmt"
+    "log"
     "net/http"
-    "os"
-    "strconv"
+    "strings"
+    "sync"
     "time"
 
     "github.com/gorilla/mux"
     "github.com/rs/cors"
     "LeetTracker/auth"
+    "LeetTracker/internal/config"
     "LeetTracker/internal/database"
+    "LeetTracker/internal/poller"
+    "LeetTracker/internal/utils/cache"
+    "LeetTracker/internal/utils/leetcode"
 )
 
 type Server struct {
-    port int
-    db   database.Service
+    port               int
+    db                 database.Service
+    cache              cache.Cache
+    problems           ProblemSource
+    authenticator      auth.Authenticator
+    leetcodeClient     *leetcode.Client
+    poller             *poller.Poller
+    pollerEnabled      bool
+    adminUsers         map[string]bool
+    webURL             string
+
+    httpServer *http.Server
+
+    // Background workers run under workerCtx and are waited on by Shutdown.
+    workerCtx   context.Context
+    stopWorkers context.CancelFunc
+    workers     sync.WaitGroup
+}
+
+// ProblemSource supplies the LeetCode problem catalog.
+type ProblemSource interface {
+    FetchLeetCodeProblems() ([]leetcode.Problem, error)
+    InvalidateCache() error
+}
+
+// Dependencies are the collaborators a Server is built around. NewServer
+// wires up the real ones from configuration; tests can pass their own to New.
+type Dependencies struct {
+    DB            database.Service
+    Problems      ProblemSource
+    Cache         cache.Cache
+    Authenticator auth.Authenticator
 }
 
-func NewServer() *http.Server {
-    port, _ := strconv.Atoi(os.Getenv("PORT"))
-    if port == 0 {
-        port = 8080
+// NewServer connects to the configured database and cache and builds a
+// Server around them.
+func NewServer(cfg *config.Config) (*Server, error) {
+    db, err := database.New(cfg.Database)
+    if err != nil {
+        return nil, err
     }
-    s := &Server{
-        port: port,
-        db:   database.New(),
+    var c cache.Cache
+    switch cfg.Cache.Driver {
+    case config.CacheMemory:
+        c = cache.NewMemory()
+    default:
+        c = cache.NewRedis(cfg.Redis.Addr)
     }
+    return New(cfg, Dependencies{
+        DB:            db,
+        Problems:      leetcode.NewFetcher(cfg.LeetCode, c),
+        Cache:         c,
+        Authenticator: auth.NewJWTAuthenticator(cfg.Auth),
+    }), nil
+}
 
-    jwtMiddleware := auth.NewJWTMiddleware()
+// New builds a Server from already constructed dependencies. Only the server,
+// auth, LeetCode and poller sections of cfg are used.
+func New(cfg *config.Config, deps Dependencies) *Server {
+    s := &Server{
+        port:               cfg.Server.Port,
+        db:                 deps.DB,
+        cache:              deps.Cache,
+        problems:           deps.Problems,
+        authenticator:      deps.Authenticator,
+        leetcodeClient:     leetcode.NewClient(cfg.LeetCode),
+        pollerEnabled:      cfg.Poller.Enabled,
+        adminUsers:         make(map[string]bool, len(cfg.Auth.AdminUserIDs)),
+        webURL:             strings.TrimRight(cfg.Server.WebURL, "/"),
+    }
+    s.poller = poller.New(cfg.Poller, s.db, s.leetcodeClient)
+    for _, id := range cfg.Auth.AdminUserIDs {
+        s.adminUsers[id] = true
+    }
+    s.workerCtx, s.stopWorkers = context.WithCancel(context.Background())
 
     r := mux.NewRouter()
-    RegisterRoutes(r, s, jwtMiddleware)
+    r.Use(requestIDMiddleware)
+    RegisterRoutes(r, s)
 
-    // remove -- only in dev
     corsWrapper := cors.New(cors.Options{
-        AllowedOrigins:   []string{"http://localhost:5173"},
+        AllowedOrigins:   cfg.Server.AllowedOrigins,
         AllowedMethods: []string{"GET", "POST", "DELETE", "PUT", "OPTIONS"},
         AllowedHeaders: []string{"Content-Type", "Origin", "Accept", "*"},
+        ExposedHeaders: []string{requestIDHeader},
         AllowCredentials: true,
     })
 
-    srv := &http.Server{
-        Addr:         fmt.Sprintf(":%d", port),
+    s.httpServer = &http.Server{
+        Addr:         fmt.Sprintf(":%d", s.port),
         Handler:      corsWrapper.Handler(r),
         IdleTimeout:  time.Minute,
         ReadTimeout:  10 * time.Second,
         WriteTimeout: 30 * time.Second,
     }
 
-    return srv
+    return s
+}
+
+// Handler returns the fully wrapped router, for use with httptest.
+func (s *Server) Handler() http.Handler {
+    return s.httpServer.Handler
+}
+
+// adminOnly must run after the user ID middleware; it rejects callers whose
+// Auth0 subject is not listed in the admin user IDs.
+func (s *Server) adminOnly(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        userID, _ := r.Context().Value(auth.UserIDKey).(string)
+        if !s.adminUsers[userID] {
+            writeError(w, r, http.StatusForbidden, codeForbidden, "Administrator access required", nil)
+            return
+        }
+        next.ServeHTTP(w, r)
+    })
+}
+
+// CheckDependencies verifies the database and cache are reachable and the
+// schema matches this build, so the process can fail fast at startup instead
+// of on the first request.
+func (s *Server) CheckDependencies(ctx context.Context) error {
+    if err := s.db.Ping(ctx); err != nil {
+        return fmt.Errorf("database unreachable: %v", err)
+    }
+    version, err := s.db.SchemaVersion(ctx)
+    if err != nil {
+        return err
+    }
+    if expected := database.ExpectedSchemaVersion(); version != expected {
+        return fmt.Errorf("database schema is at version %d but this build expects %d; run `make migrate-up`", version, expected)
+    }
+    if err := s.cache.Ping(ctx); err != nil {
+        return fmt.Errorf("cache unreachable: %v", err)
+    }
+    return nil
+}
+
+// ListenAndServe blocks until the server stops. It returns nil when the
+// server was stopped by Shutdown.
+func (s *Server) ListenAndServe() error {
+    log.Printf("Listening on %s", s.httpServer.Addr)
+    err := s.httpServer.ListenAndServe()
+    if errors.Is(err, http.ErrServerClosed) {
+        return nil
+    }
+    return err
+}
+
+// StartBackgroundWorkers starts the scheduled jobs enabled in configuration.
+// It is separate from New so tests and the migrate command never start them.
+func (s *Server) StartBackgroundWorkers() {
+    if s.pollerEnabled {
+        s.runInBackground("leetcode-poller", s.poller.Run)
+    }
+}
+
+// runInBackground starts fn in its own goroutine. fn must return once ctx is
+// cancelled; Shutdown waits for it before closing the database and cache.
+func (s *Server) runInBackground(name string, fn func(ctx context.Context)) {
+    s.workers.Add(1)
+    go func() {
+        defer s.workers.Done()
+        fn(s.workerCtx)
+        log.Printf("Background worker %s stopped", name)
+    }()
+}
+
+// Shutdown stops accepting connections, drains in-flight requests, stops
+// background workers and finally releases the cache and database. Every step
+// runs even if an earlier one fails; the first error is returned.
+func (s *Server) Shutdown(ctx context.Context) error {
+    var firstErr error
+    record := func(step string, err error) {
+        if err != nil {
+            log.Printf("Shutdown: %s: %v", step, err)
+            if firstErr == nil {
+                firstErr = fmt.Errorf("%s: %v", step, err)
+            }
+        }
+    }
+
+    record("draining HTTP requests", s.httpServer.Shutdown(ctx))
+
+    s.stopWorkers()
+    done := make(chan struct{})
+    go func() {
+        s.workers.Wait()
+        close(done)
+    }()
+    select {
+    case <-done:
+    case <-ctx.Done():
+        record("stopping background workers", ctx.Err())
+    }
+
+    record("closing cache", s.cache.Close())
+    record("closing database", s.db.Close())
+    return firstErr
+}
+
+// Close releases dependencies without serving; used when startup fails.
+func (s *Server) Close() error {
+    s.stopWorkers()
+    cacheErr := s.cache.Close()
+    if err := s.db.Close(); err != nil {
+        return err
+    }
+    return cacheErr
 }
@@ -0,0 +1,157 @@
+package server
+
+import (
+    "net/http"
+    "strconv"
+
+    "github.com/gorilla/mux"
+    "LeetTracker/auth"
+    "LeetTracker/internal/database"
+)
+
+// sharedList loads the personal list in the {id} route variable, if the
+// caller can see it. Group lists are shared through their group, so they
+// get a 400.
+func (s *Server) sharedList(w http.ResponseWriter, r *http.Request) (*database.List, bool) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+    listID, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, codeBadRequest, "Invalid list ID", nil)
+        return nil, false
+    }
+
+    list, err := s.db.GetListByID(r.Context(), listID, userID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch list")
+        return nil, false
+    }
+    if list.GroupID != nil {
+        writeError(w, r, http.StatusBadRequest, codeBadRequest, "Group lists are shared with the group's members", nil)
+        return nil, false
+    }
+    return list, true
+}
+
+// editableList checks that the caller may change listID's items. Viewers of
+// a shared list may not, and group lists are edited by the group's owner and
+// admins only.
+func (s *Server) editableList(w http.ResponseWriter, r *http.Request, listID int, fallback string) bool {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+
+    list, err := s.db.GetListByID(r.Context(), listID, userID)
+    if err != nil {
+        writeServiceError(w, r, err, fallback)
+        return false
+    }
+    if list.GroupID == nil {
+        if list.UserID == userID {
+            return true
+        }
+        members, err := s.db.GetListMembers(r.Context(), listID)
+        if err != nil {
+            writeServiceError(w, r, err, fallback)
+            return false
+        }
+        for _, member := range members {
+            if member.UserID == userID && member.Role == database.ListEditor {
+                return true
+            }
+        }
+        writeError(w, r, http.StatusForbidden, codeForbidden, "Viewers cannot edit this list", nil)
+        return false
+    }
+    group, err := s.db.GetGroup(r.Context(), *list.GroupID, userID)
+    if err != nil {
+        writeServiceError(w, r, err, fallback)
+        return false
+    }
+    if !canManageGroup(group.Role) {
+        writeError(w, r, http.StatusForbidden, codeForbidden, "Only group owners and admins can edit group lists", nil)
+        return false
+    }
+    return true
+}
+
+func (s *Server) GetListMembersHandler(w http.ResponseWriter, r *http.Request) {
+    list, ok := s.sharedList(w, r)
+    if !ok {
+        return
+    }
+
+    members, err := s.db.GetListMembers(r.Context(), list.ID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch list members")
+        return
+    }
+
+    writeJSON(w, http.StatusOK, members)
+}
+
+// SetListMemberHandler shares a list with another user as an editor or a
+// viewer, or changes the role they already have. Only the list's owner may
+// do this.
+func (s *Server) SetListMemberHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+
+    var req listRoleRequest
+    if !decodeJSON(w, r, &req) {
+        return
+    }
+    req.normalize()
+    if errs := req.validate(); len(errs) > 0 {
+        writeValidationErrors(w, r, errs)
+        return
+    }
+    list, ok := s.sharedList(w, r)
+    if !ok {
+        return
+    }
+    if list.UserID != userID {
+        writeError(w, r, http.StatusForbidden, codeForbidden, "Only the list's owner can share it", nil)
+        return
+    }
+
+    memberID := mux.Vars(r)["userID"]
+    exists, err := s.db.UserExists(r.Context(), memberID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to share list")
+        return
+    }
+    if !exists {
+        writeError(w, r, http.StatusNotFound, codeNotFound, "User not found", nil)
+        return
+    }
+    if err := s.db.SetListMember(r.Context(), list.ID, memberID, req.Role); err != nil {
+        writeServiceError(w, r, err, "Failed to share list")
+        return
+    }
+
+    members, err := s.db.GetListMembers(r.Context(), list.ID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch list members")
+        return
+    }
+    writeJSON(w, http.StatusOK, members)
+}
+
+// RemoveListMemberHandler stops sharing a list with someone. The owner can
+// remove anyone else, and editors and viewers can remove themselves.
+func (s *Server) RemoveListMemberHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+
+    list, ok := s.sharedList(w, r)
+    if !ok {
+        return
+    }
+    memberID := mux.Vars(r)["userID"]
+    if list.UserID != userID && memberID != userID {
+        writeError(w, r, http.StatusForbidden, codeForbidden, "Only the list's owner can remove other members", nil)
+        return
+    }
+
+    if err := s.db.RemoveListMember(r.Context(), list.ID, memberID); err != nil {
+        writeServiceError(w, r, err, "Failed to remove list member")
+        return
+    }
+    writeMessage(w, http.StatusOK, "Member removed")
+}
@@ -0,0 +1,228 @@
+package server
+
+import (
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gorilla/mux"
+    "LeetTracker/auth"
+    "LeetTracker/internal/database"
+    "LeetTracker/internal/studyplan"
+)
+
+type studyPlanResponse struct {
+    database.StudyPlan
+    Status   studyplan.Status      `json:"status"`
+    Schedule []database.Assignment `json:"schedule"`
+}
+
+// todaysPlan is one plan's share of the caller's problems for today.
+type todaysPlan struct {
+    database.StudyPlan
+    Due     []database.Assignment `json:"due"`
+    Overdue []database.Assignment `json:"overdue"`
+}
+
+type todayResponse struct {
+    Date  string       `json:"date"`
+    Plans []todaysPlan `json:"plans"`
+}
+
+// ownStudyPlan loads the caller's study plan in the {id} route variable.
+func (s *Server) ownStudyPlan(w http.ResponseWriter, r *http.Request) (*database.StudyPlan, bool) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+    planID, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, codeBadRequest, "Invalid study plan ID", nil)
+        return nil, false
+    }
+
+    plan, err := s.db.GetStudyPlan(r.Context(), planID, userID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch study plan")
+        return nil, false
+    }
+    return plan, true
+}
+
+// CreateStudyPlanHandler schedules the caller's unfinished problems on a
+// list they can see, in list order.
+func (s *Server) CreateStudyPlanHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+
+    var req createStudyPlanRequest
+    if !decodeJSON(w, r, &req) {
+        return
+    }
+    req.normalize()
+    if errs := req.validate(); len(errs) > 0 {
+        writeValidationErrors(w, r, errs)
+        return
+    }
+
+    if err := s.db.EnsureUserExists(r.Context(), userID); err != nil {
+        writeServiceError(w, r, err, "Failed to create study plan")
+        return
+    }
+    list, err := s.db.GetListByID(r.Context(), req.ListID, userID)
+    if err != nil {
+        writeServiceError(w, r, err, "Error retrieving list")
+        return
+    }
+    items, err := s.db.GetListItems(r.Context(), list.ID, userID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to get list items")
+        return
+    }
+    var itemIDs []int
+    for _, item := range items {
+        if !item.Completed {
+            itemIDs = append(itemIDs, item.ID)
+        }
+    }
+    if len(itemIDs) == 0 {
+        writeValidationErrors(w, r, []fieldError{{Field: "list_id", Message: "has no unfinished problems to schedule"}})
+        return
+    }
+
+    plan := database.StudyPlan{
+        UserID:         userID,
+        ListID:         list.ID,
+        ListName:       list.Name,
+        StartDate:      req.start(time.Now().UTC()),
+        DaysPerWeek:    req.DaysPerWeek,
+        ProblemsPerDay: req.ProblemsPerDay,
+    }
+    schedule := studyplan.Schedule(itemIDs, plan.StartDate, plan.DaysPerWeek, plan.ProblemsPerDay)
+    if err := s.db.CreateStudyPlan(r.Context(), &plan, schedule); err != nil {
+        writeServiceError(w, r, err, "Failed to create study plan")
+        return
+    }
+    s.writeStudyPlan(w, r, &plan)
+}
+
+func (s *Server) GetUserStudyPlansHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+
+    plans, err := s.db.GetUserStudyPlans(r.Context(), userID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch study plans")
+        return
+    }
+    if plans == nil {
+        plans = []database.StudyPlan{}
+    }
+
+    writeJSON(w, http.StatusOK, plans)
+}
+
+// GetTodaysProblemsHandler lists what is due today and what is overdue on
+// each of the caller's plans. Plans with neither are left out.
+func (s *Server) GetTodaysProblemsHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+    today := studyplan.Day(time.Now().UTC())
+
+    plans, err := s.db.GetUserStudyPlans(r.Context(), userID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch study plans")
+        return
+    }
+    response := todayResponse{Date: today.Format(dateLayout), Plans: []todaysPlan{}}
+    for _, plan := range plans {
+        schedule, err := s.db.GetStudyPlanSchedule(r.Context(), plan.ID)
+        if err != nil {
+            writeServiceError(w, r, err, "Failed to fetch study plan schedule")
+            return
+        }
+        due, overdue := studyplan.Due(schedule, today)
+        if len(due) > 0 || len(overdue) > 0 {
+            response.Plans = append(response.Plans, todaysPlan{StudyPlan: plan, Due: due, Overdue: overdue})
+        }
+    }
+
+    writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) GetStudyPlanHandler(w http.ResponseWriter, r *http.Request) {
+    plan, ok := s.ownStudyPlan(w, r)
+    if !ok {
+        return
+    }
+    s.writeStudyPlan(w, r, plan)
+}
+
+// RebalanceStudyPlanHandler spreads the caller's unfinished problems, and
+// any added to the list since the plan was made, over the study days from
+// today on.
+func (s *Server) RebalanceStudyPlanHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+    plan, ok := s.ownStudyPlan(w, r)
+    if !ok {
+        return
+    }
+
+    schedule, err := s.db.GetStudyPlanSchedule(r.Context(), plan.ID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch study plan schedule")
+        return
+    }
+    items, err := s.db.GetListItems(r.Context(), plan.ListID, userID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to get list items")
+        return
+    }
+    scheduled := make(map[int]bool, len(schedule))
+    for _, a := range schedule {
+        scheduled[a.ListItemID] = true
+    }
+    var unscheduled []int
+    for _, item := range items {
+        if !scheduled[item.ID] && !item.Completed {
+            unscheduled = append(unscheduled, item.ID)
+        }
+    }
+
+    rebalanced := studyplan.Rebalance(*plan, schedule, unscheduled, time.Now().UTC())
+    if err := s.db.RescheduleStudyPlan(r.Context(), plan.ID, rebalanced); err != nil {
+        writeServiceError(w, r, err, "Failed to rebalance study plan")
+        return
+    }
+    plan, err = s.db.GetStudyPlan(r.Context(), plan.ID, userID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch study plan")
+        return
+    }
+    s.writeStudyPlan(w, r, plan)
+}
+
+func (s *Server) DeleteStudyPlanHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+    planID, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, codeBadRequest, "Invalid study plan ID", nil)
+        return
+    }
+
+    if err := s.db.DeleteStudyPlan(r.Context(), planID, userID); err != nil {
+        writeServiceError(w, r, err, "Failed to delete study plan")
+        return
+    }
+
+    writeMessage(w, http.StatusOK, "Study plan deleted successfully")
+}
+
+// writeStudyPlan responds with plan, its schedule and how it stands today.
+func (s *Server) writeStudyPlan(w http.ResponseWriter, r *http.Request, plan *database.StudyPlan) {
+    schedule, err := s.db.GetStudyPlanSchedule(r.Context(), plan.ID)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to fetch study plan schedule")
+        return
+    }
+
+    writeJSON(w, http.StatusOK, studyPlanResponse{
+        StudyPlan: *plan,
+        Status:    studyplan.Track(*plan, schedule, time.Now().UTC()),
+        Schedule:  schedule,
+    })
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+    "net/http"
+    "strconv"
+
+    "LeetTracker/auth"
+    "LeetTracker/internal/database"
+)
+
+// recentSubmissionsLimit is the most accepted submissions LeetCode returns
+// in one request.
+const recentSubmissionsLimit = 20
+
+type importSubmissionsResponse struct {
+    DryRun         bool                  `json:"dry_run"`
+    Submissions    int                   `json:"submissions"`
+    Matched        int                   `json:"matched"`
+    UnmatchedSlugs []string              `json:"unmatched_slugs"`
+    Completed      []database.SolvedItem `json:"completed"`
+}
+
+// ImportSubmissionsHandler completes the caller's list items for problems
+// they have recently solved on LeetCode, dated with the real solve time.
+// With ?dry_run=true it only reports what would change.
+func (s *Server) ImportSubmissionsHandler(w http.ResponseWriter, r *http.Request) {
+    userID := r.Context().Value(auth.UserIDKey).(string)
+
+    dryRun := false
+    if raw := r.URL.Query().Get("dry_run"); raw != "" {
+        var err error
+        if dryRun, err = strconv.ParseBool(raw); err != nil {
+            writeError(w, r, http.StatusBadRequest, codeBadRequest, "dry_run must be true or false", nil)
+            return
+        }
+    }
+
+    username, ok := s.linkedUsername(w, r, userID)
+    if !ok {
+        return
+    }
+    submissions, err := s.leetcodeClient.RecentAcceptedSubmissions(r.Context(), username, recentSubmissionsLimit)
+    if err != nil {
+        writeLeetCodeError(w, r, err)
+        return
+    }
+
+    slugs := make([]string, 0, len(submissions))
+    for _, sub := range submissions {
+        slugs = append(slugs, sub.TitleSlug)
+    }
+    ids, err := s.db.GetProblemIDsBySlugs(r.Context(), slugs)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to match submissions")
+        return
+    }
+
+    resp := importSubmissionsResponse{DryRun: dryRun, Submissions: len(submissions), UnmatchedSlugs: []string{}}
+    var solves []database.Solve
+    for _, sub := range submissions {
+        id, ok := ids[sub.TitleSlug]
+        if !ok {
+            resp.UnmatchedSlugs = append(resp.UnmatchedSlugs, sub.TitleSlug)
+            continue
+        }
+        resp.Matched++
+        solves = append(solves, database.Solve{ProblemID: id, SolvedAt: sub.Timestamp})
+    }
+
+    resp.Completed, err = s.db.CompleteSolvedItems(r.Context(), userID, solves, dryRun)
+    if err != nil {
+        writeServiceError(w, r, err, "Failed to import submissions")
+        return
+    }
+    if resp.Completed == nil {
+        resp.Completed = []database.SolvedItem{}
+    }
+
+    writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "strings"
+    "unicode/utf8"
+)
+
+// maxBodyBytes caps every JSON request body. List payloads are a few hundred
+// bytes; anything near this limit is a client bug or abuse.
+const maxBodyBytes = 64 << 10
+
+const codePayloadTooLarge = "payload_too_large"
+
+// fieldError describes one invalid field in a 422 response.
+type fieldError struct {
+    Field   string `json:"field"`
+    Message string `json:"message"`
+}
+
+// validator accumulates field errors so a single response can report every
+// problem with a payload instead of only the first one.
+type validator struct {
+    errors []fieldError
+}
+
+func (v *validator) add(field, format string, args ...interface{}) {
+    v.errors = append(v.errors, fieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) check(ok bool, field, format string, args ...interface{}) {
+    if !ok {
+        v.add(field, format, args...)
+    }
+}
+
+func (v *validator) required(field, value string) {
+    v.check(strings.TrimSpace(value) != "", field, "is required")
+}
+
+func (v *validator) maxLength(field, value string, max int) {
+    v.check(utf8.RuneCountInString(value) <= max, field, "must be at most %d characters", max)
+}
+
+func (v *validator) oneOf(field, value string, allowed ...string) {
+    for _, a := range allowed {
+        if value == a {
+            return
+        }
+    }
+    v.add(field, "must be one of %s", strings.Join(allowed, ", "))
+}
+
+func (v *validator) valid() bool {
+    return len(v.errors) == 0
+}
+
+// decodeJSON reads a single JSON object from the request body into dst,
+// rejecting unknown fields, trailing data and oversized bodies. On failure it
+// writes the error response itself and returns false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+    r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+    dec := json.NewDecoder(r.Body)
+    dec.DisallowUnknownFields()
+
+    err := dec.Decode(dst)
+    if err == nil {
+        if dec.Decode(&struct{}{}) != io.EOF {
+            writeError(w, r, http.StatusBadRequest, codeBadRequest, "Request body must contain a single JSON object", nil)
+            return false
+        }
+        return true
+    }
+
+    var maxBytesErr *http.MaxBytesError
+    var typeErr *json.UnmarshalTypeError
+    switch {
+    case errors.As(err, &maxBytesErr):
+        writeError(w, r, http.StatusRequestEntityTooLarge, codePayloadTooLarge,
+            fmt.Sprintf("Request body must not exceed %d bytes", maxBodyBytes), nil)
+    case strings.HasPrefix(err.Error(), "json: unknown field "):
+        field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+        writeValidationErrors(w, r, []fieldError{{Field: field, Message: "is not a recognised field"}})
+    case errors.As(err, &typeErr):
+        writeValidationErrors(w, r, []fieldError{{Field: typeErr.Field, Message: fmt.Sprintf("must be of type %s", typeErr.Type)}})
+    case errors.Is(err, io.EOF):
+        writeError(w, r, http.StatusBadRequest, codeBadRequest, "Request body must not be empty", nil)
+    default:
+        writeError(w, r, http.StatusBadRequest, codeBadRequest, "Request body contains malformed JSON", nil)
+    }
+    return false
+}
+
+func writeValidationErrors(w http.ResponseWriter, r *http.Request, errs []fieldError) {
+    writeError(w, r, http.StatusUnprocessableEntity, codeValidationFailed, "Request validation failed", errs)
+}
@@ -0,0 +1,165 @@
+// Package studyplan lays a study plan's problems out over dated study days and
+// measures how far the plan's owner has fallen behind it.
+package studyplan
+
+import (
+    "sort"
+    "time"
+
+    "LeetTracker/internal/database"
+)
+
+// studyWeekdays lists the weekdays studied for each number of days per week,
+// spread so that rest days fall between study days where they can.
+var studyWeekdays = [8][]time.Weekday{
+    1: {time.Monday},
+    2: {time.Monday, time.Thursday},
+    3: {time.Monday, time.Wednesday, time.Friday},
+    4: {time.Monday, time.Tuesday, time.Thursday, time.Friday},
+    5: {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+    6: {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+    7: {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday},
+}
+
+// Day truncates t to midnight UTC, which is how due dates are compared.
+func Day(t time.Time) time.Time {
+    return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// IsStudyDay reports whether d is a study day for a plan with daysPerWeek.
+func IsStudyDay(d time.Time, daysPerWeek int) bool {
+    for _, weekday := range studyWeekdays[daysPerWeek] {
+        if d.Weekday() == weekday {
+            return true
+        }
+    }
+    return false
+}
+
+// Schedule assigns itemIDs in order to the study days from start on, perDay
+// to a day. daysPerWeek must be between 1 and 7 and perDay positive.
+func Schedule(itemIDs []int, start time.Time, daysPerWeek, perDay int) []database.Assignment {
+    return assign(itemIDs, Day(start), daysPerWeek, perDay, nil)
+}
+
+// assign is Schedule with some slots already taken, counted by day.
+func assign(itemIDs []int, start time.Time, daysPerWeek, perDay int, taken map[time.Time]int) []database.Assignment {
+    schedule := make([]database.Assignment, 0, len(itemIDs))
+    day, used := start, taken[start]
+    for _, id := range itemIDs {
+        for !IsStudyDay(day, daysPerWeek) || used >= perDay {
+            day = day.AddDate(0, 0, 1)
+            used = taken[day]
+        }
+        schedule = append(schedule, database.Assignment{ListItemID: id, DueDate: day})
+        used++
+    }
+    return schedule
+}
+
+// Rebalance spreads the unfinished problems in schedule, followed by the
+// unscheduled list items, over the study days from today on, or from the
+// plan's start if it has not begun. Unfinished problems keep their order,
+// and completed ones keep their dates and still fill the slots of the days
+// they are due. It returns only the new assignments.
+func Rebalance(p database.StudyPlan, schedule []database.Assignment, unscheduled []int, today time.Time) []database.Assignment {
+    from := Day(today)
+    if start := Day(p.StartDate); start.After(from) {
+        from = start
+    }
+
+    var pending []database.Assignment
+    taken := make(map[time.Time]int)
+    for _, a := range schedule {
+        switch day := Day(a.DueDate); {
+        case !a.Completed:
+            pending = append(pending, a)
+        case !day.Before(from):
+            taken[day]++
+        }
+    }
+    sort.SliceStable(pending, func(i, j int) bool {
+        a, b := Day(pending[i].DueDate), Day(pending[j].DueDate)
+        if a.Equal(b) {
+            return pending[i].ListItemID < pending[j].ListItemID
+        }
+        return a.Before(b)
+    })
+
+    itemIDs := make([]int, 0, len(pending)+len(unscheduled))
+    for _, a := range pending {
+        itemIDs = append(itemIDs, a.ListItemID)
+    }
+    itemIDs = append(itemIDs, unscheduled...)
+    return assign(itemIDs, from, p.DaysPerWeek, p.ProblemsPerDay, taken)
+}
+
+// Due returns the problems due on today, finished or not, and the unfinished
+// ones that were due before it.
+func Due(schedule []database.Assignment, today time.Time) (due, overdue []database.Assignment) {
+    today = Day(today)
+    due, overdue = []database.Assignment{}, []database.Assignment{}
+    for _, a := range schedule {
+        switch day := Day(a.DueDate); {
+        case day.Equal(today):
+            due = append(due, a)
+        case day.Before(today) && !a.Completed:
+            overdue = append(overdue, a)
+        }
+    }
+    return due, overdue
+}
+
+// Status is how a plan stands on a given day.
+type Status struct {
+    Total        int        `json:"total"`
+    Completed    int        `json:"completed"`
+    DueToday     int        `json:"due_today"`
+    Overdue      int        `json:"overdue"`
+    // MissedDays counts the past study days that still have unfinished
+    // problems.
+    MissedDays   int        `json:"missed_days"`
+    PlannedEnd   *time.Time `json:"planned_end"`
+    // ProjectedEnd is the day the plan would finish if it were rebalanced
+    // today. It is nil once every problem is done.
+    ProjectedEnd *time.Time `json:"projected_end"`
+    // SlipDays is how many days ProjectedEnd falls after PlannedEnd, or
+    // before it when negative.
+    SlipDays     int        `json:"slip_days"`
+}
+
+// Track measures schedule against today.
+func Track(p database.StudyPlan, schedule []database.Assignment, today time.Time) Status {
+    today = Day(today)
+    status := Status{Total: len(schedule)}
+    missed := make(map[time.Time]bool)
+    for _, a := range schedule {
+        day := Day(a.DueDate)
+        if status.PlannedEnd == nil || day.After(*status.PlannedEnd) {
+            end := day
+            status.PlannedEnd = &end
+        }
+        switch {
+        case a.Completed:
+            status.Completed++
+        case day.Before(today):
+            status.Overdue++
+            missed[day] = true
+        }
+        if day.Equal(today) {
+            status.DueToday++
+        }
+    }
+    status.MissedDays = len(missed)
+
+    for _, a := range Rebalance(p, schedule, nil, today) {
+        if status.ProjectedEnd == nil || a.DueDate.After(*status.ProjectedEnd) {
+            end := a.DueDate
+            status.ProjectedEnd = &end
+        }
+    }
+    if status.ProjectedEnd != nil {
+        status.SlipDays = int(status.ProjectedEnd.Sub(*status.PlannedEnd).Hours() / 24)
+    }
+    return status
+}
@@ -0,0 +1,136 @@
+package studyplan
+
+import (
+	"LeetTracker/internal/database"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func dueDates(schedule []database.Assignment) []string {
+	var dates []string
+	for _, a := range schedule {
+		dates = append(dates, a.DueDate.Format("2006-01-02"))
+	}
+	return dates
+}
+
+func expectDates(t *testing.T, schedule []database.Assignment, want ...string) {
+	t.Helper()
+	got := dueDates(schedule)
+	if len(got) != len(want) {
+		t.Fatalf("expected due dates %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected due dates %v, got %v", want, got)
+		}
+	}
+}
+
+func TestScheduleSkipsRestDays(t *testing.T) {
+	// 2024-03-02 is a Saturday, so a weekday plan starts on Monday the 4th.
+	schedule := Schedule([]int{1, 2, 3, 4, 5}, date("2024-03-02"), 3, 2)
+	expectDates(t, schedule, "2024-03-04", "2024-03-04", "2024-03-06", "2024-03-06", "2024-03-08")
+	if schedule[0].ListItemID != 1 || schedule[4].ListItemID != 5 {
+		t.Errorf("expected items to keep their order, got %+v", schedule)
+	}
+
+	expectDates(t, Schedule([]int{1, 2, 3}, date("2024-03-08"), 5, 1), "2024-03-08", "2024-03-11", "2024-03-12")
+	expectDates(t, Schedule([]int{1, 2}, date("2024-03-09"), 7, 1), "2024-03-09", "2024-03-10")
+}
+
+func TestStudyDaysPerWeek(t *testing.T) {
+	monday := date("2024-03-04")
+	for days := 1; days <= 7; days++ {
+		count := 0
+		for i := 0; i < 7; i++ {
+			if IsStudyDay(monday.AddDate(0, 0, i), days) {
+				count++
+			}
+		}
+		if count != days {
+			t.Errorf("expected %d study days a week, got %d", days, count)
+		}
+	}
+}
+
+// plan studies Monday, Wednesday and Friday, one problem a day, starting on
+// Monday 2024-03-04.
+var plan = database.StudyPlan{StartDate: date("2024-03-04"), DaysPerWeek: 3, ProblemsPerDay: 1}
+
+func planSchedule(completed ...int) []database.Assignment {
+	schedule := Schedule([]int{1, 2, 3, 4}, plan.StartDate, plan.DaysPerWeek, plan.ProblemsPerDay)
+	for i := range schedule {
+		for _, id := range completed {
+			if schedule[i].ListItemID == id {
+				schedule[i].Completed = true
+			}
+		}
+	}
+	return schedule
+}
+
+func TestTrackOnSchedule(t *testing.T) {
+	status := Track(plan, planSchedule(1), date("2024-03-06"))
+	if status.Total != 4 || status.Completed != 1 || status.DueToday != 1 || status.Overdue != 0 || status.MissedDays != 0 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if !status.PlannedEnd.Equal(date("2024-03-11")) || !status.ProjectedEnd.Equal(date("2024-03-11")) || status.SlipDays != 0 {
+		t.Fatalf("expected to finish on the 11th as planned, got %+v", status)
+	}
+}
+
+func TestTrackMissedDays(t *testing.T) {
+	// Nothing was done on the 4th or the 6th, so on Friday the 8th four
+	// problems remain: the 8th, 11th, 13th and 15th.
+	status := Track(plan, planSchedule(), date("2024-03-08"))
+	if status.Overdue != 2 || status.MissedDays != 2 {
+		t.Fatalf("expected two overdue problems on two missed days, got %+v", status)
+	}
+	if !status.ProjectedEnd.Equal(date("2024-03-15")) || status.SlipDays != 4 {
+		t.Fatalf("expected to slip four days to the 15th, got %+v", status)
+	}
+
+	done := Track(plan, planSchedule(1, 2, 3, 4), date("2024-03-08"))
+	if done.ProjectedEnd != nil || done.SlipDays != 0 || done.Overdue != 0 {
+		t.Fatalf("expected a finished plan not to slip, got %+v", done)
+	}
+}
+
+func TestDue(t *testing.T) {
+	due, overdue := Due(planSchedule(1), date("2024-03-08"))
+	if len(due) != 1 || due[0].ListItemID != 3 {
+		t.Errorf("expected item 3 due on the 8th, got %+v", due)
+	}
+	if len(overdue) != 1 || overdue[0].ListItemID != 2 {
+		t.Errorf("expected only the unfinished item 2 to be overdue, got %+v", overdue)
+	}
+}
+
+func TestRebalance(t *testing.T) {
+	// Item 3 was done early; 2 was missed. On the 8th, 2 and 4 are moved up
+	// and the new item 5 goes last. Item 3 keeps its slot on the 8th.
+	schedule := Rebalance(plan, planSchedule(1, 3), []int{5}, date("2024-03-08"))
+	var ids []int
+	for _, a := range schedule {
+		ids = append(ids, a.ListItemID)
+	}
+	if len(ids) != 3 || ids[0] != 2 || ids[1] != 4 || ids[2] != 5 {
+		t.Fatalf("expected items 2, 4 and 5 to be rescheduled, got %v", ids)
+	}
+	expectDates(t, schedule, "2024-03-11", "2024-03-13", "2024-03-15")
+
+	// Item 4 was done ahead of its day, the 11th, so item 3 skips past it.
+	expectDates(t, Rebalance(plan, planSchedule(1, 4), nil, date("2024-03-08")), "2024-03-08", "2024-03-13")
+
+	// A plan that has not started is rebalanced from its start.
+	expectDates(t, Rebalance(plan, nil, []int{1}, date("2024-02-01")), "2024-03-04")
+}
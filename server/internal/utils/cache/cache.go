@@ -8,19 +8,28 @@ import (
     "github.com/go-redis/redis/v8"
 )
 
-type Cache struct {
+// Cache stores JSON-encoded values under string keys.
+type Cache interface {
+    Set(key string, value interface{}, expiration time.Duration) error
+    Get(key string, dest interface{}) error
+    Delete(key string) error
+    Ping(ctx context.Context) error
+    Close() error
+}
+
+type redisCache struct {
     client *redis.Client
 }
 
-func NewCache(addr string) *Cache {
-    return &Cache{
+func NewRedis(addr string) Cache {
+    return &redisCache{
         client: redis.NewClient(&redis.Options{
             Addr: addr,
         }),
     }
 }
 
-func (c *Cache) Set(key string, value interface{}, expiration time.Duration) error {
+func (c *redisCache) Set(key string, value interface{}, expiration time.Duration) error {
     json, err := json.Marshal(value)
     if err != nil {
         return err
@@ -29,7 +38,7 @@ func (c *Cache) Set(key string, value interface{}, expiration time.Duration) err
     return c.client.Set(context.Background(), key, json, expiration).Err()
 }
 
-func (c *Cache) Get(key string, dest interface{}) error {
+func (c *redisCache) Get(key string, dest interface{}) error {
     val, err := c.client.Get(context.Background(), key).Result()
     if err != nil {
         return err
@@ -37,3 +46,15 @@ func (c *Cache) Get(key string, dest interface{}) error {
 
     return json.Unmarshal([]byte(val), dest)
 }
+
+func (c *redisCache) Delete(key string) error {
+    return c.client.Del(context.Background(), key).Err()
+}
+
+func (c *redisCache) Ping(ctx context.Context) error {
+    return c.client.Ping(ctx).Err()
+}
+
+func (c *redisCache) Close() error {
+    return c.client.Close()
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "sync"
+    "time"
+)
+
+// ErrMiss is returned by the in-process cache for missing or expired keys.
+var ErrMiss = errors.New("cache: key not found")
+
+type entry struct {
+    value     []byte
+    expiresAt time.Time
+}
+
+// memoryCache keeps values in the process. It is meant for single-instance
+// deployments that do not want to run Redis; nothing survives a restart.
+type memoryCache struct {
+    mu      sync.Mutex
+    entries map[string]entry
+}
+
+func NewMemory() Cache {
+    return &memoryCache{entries: make(map[string]entry)}
+}
+
+func (c *memoryCache) Set(key string, value interface{}, expiration time.Duration) error {
+    data, err := json.Marshal(value)
+    if err != nil {
+        return err
+    }
+
+    e := entry{value: data}
+    if expiration > 0 {
+        e.expiresAt = time.Now().Add(expiration)
+    }
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.entries[key] = e
+    return nil
+}
+
+func (c *memoryCache) Get(key string, dest interface{}) error {
+    c.mu.Lock()
+    e, ok := c.entries[key]
+    if ok && !e.expiresAt.IsZero() && time.Now().After(e.expiresAt) {
+        delete(c.entries, key)
+        ok = false
+    }
+    c.mu.Unlock()
+    if !ok {
+        return ErrMiss
+    }
+
+    return json.Unmarshal(e.value, dest)
+}
+
+func (c *memoryCache) Delete(key string) error {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    delete(c.entries, key)
+    return nil
+}
+
+func (c *memoryCache) Ping(ctx context.Context) error {
+    return ctx.Err()
+}
+
+func (c *memoryCache) Close() error {
+    return nil
+}
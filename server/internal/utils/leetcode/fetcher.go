@@ -8,6 +8,7 @@ import (
     "math"
     "time"
     "fmt"
+    "LeetTracker/internal/config"
     "LeetTracker/internal/utils/cache"
 )
 
@@ -43,33 +44,47 @@ type ProblemStat struct {
 }
 
 type Problem struct {
-    Title          string  `json:"title"`
-    TitleSlug      string  `json:"titleSlug"`
-    Difficulty     string  `json:"difficulty"`
-    AcceptanceRate float64 `json:"acRate"`
-    FrontendID     int     `json:"frontendQuestionId"`
-    IsPremium      bool    `json:"paidOnly"`
-    URL            string  `json:"url"`
+    Title          string   `json:"title"`
+    TitleSlug      string   `json:"titleSlug"`
+    Difficulty     string   `json:"difficulty"`
+    AcceptanceRate float64  `json:"acRate"`
+    FrontendID     int      `json:"frontendQuestionId"`
+    IsPremium      bool     `json:"paidOnly"`
+    URL            string   `json:"url"`
+    // Topics are LeetCode's topic tag slugs, such as "hash-table". The
+    // catalog feed has none, so they are nil there, which leaves the topics
+    // the poller stored alone.
+    Topics         []string `json:"topics,omitempty"`
 }
 
-var cacheClient *cache.Cache
+const problemsCacheKey = "leetcode_problems"
 
-func init() {
-    cacheClient = cache.NewCache("localhost:6379")
+// Fetcher downloads the LeetCode problem catalog, caching it for a day.
+type Fetcher struct {
+    problemsURL string
+    cache       cache.Cache
 }
 
-func FetchLeetCodeProblems() ([]Problem, error) {
+func NewFetcher(cfg config.LeetCode, c cache.Cache) *Fetcher {
+    return &Fetcher{
+        problemsURL: cfg.ProblemsURL,
+        cache:       c,
+    }
+}
+
+func (f *Fetcher) FetchLeetCodeProblems() ([]Problem, error) {
     var problems []Problem
 
     //Hit the cache first
-    err := cacheClient.Get("leetcode_problems", &problems)
-    if err == nil {
+    err := f.cache.Get(problemsCacheKey, &problems)
+    if err == nil && len(problems) > 0 {
         log.Println("Retrieved problems from cache")
         return problems, nil
     }
+    problems = nil
 
     // If not in cache, fetch from LeetCode API
-    url := "https://leetcode.com/api/problems/all/"
+    url := f.problemsURL
     log.Printf("Fetching problems from URL: %s", url)
     
     resp, err := http.Get(url)
@@ -122,7 +137,7 @@ func FetchLeetCodeProblems() ([]Problem, error) {
     log.Printf("Parsed %d problems from response", len(problems))
 
     // Store result in cache so we can hit it later
-    err = cacheClient.Set("leetcode_problems", problems, 24*time.Hour)
+    err = f.cache.Set(problemsCacheKey, problems, 24*time.Hour)
     if err != nil {
         log.Printf("Error storing problems in cache: %v", err)
     }
@@ -130,6 +145,6 @@ func FetchLeetCodeProblems() ([]Problem, error) {
     return problems, nil
 }
 
-func InvalidateCache() error {
-    return cacheClient.Set("leetcode_problems", nil, 0)
+func (f *Fetcher) InvalidateCache() error {
+    return f.cache.Delete(problemsCacheKey)
 }
@@ -0,0 +1,326 @@
+package leetcode
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+
+    "LeetTracker/internal/config"
+)
+
+// ErrUserNotFound is returned when LeetCode has no user with the given name.
+var ErrUserNotFound = errors.New("leetcode: user not found")
+
+// GraphQLError is one entry of the "errors" array in a GraphQL response.
+type GraphQLError struct {
+    Message string        `json:"message"`
+    // Path holds field names and, inside lists, integer indices.
+    Path    []interface{} `json:"path"`
+}
+
+// GraphQLErrors is returned when LeetCode answers a query with errors.
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+    messages := make([]string, len(e))
+    for i, err := range e {
+        messages[i] = err.Message
+    }
+    return "leetcode: " + strings.Join(messages, "; ")
+}
+
+// StatusError reports a non-200 response from the GraphQL endpoint.
+type StatusError struct {
+    StatusCode int
+}
+
+func (e *StatusError) Error() string {
+    return fmt.Sprintf("leetcode: unexpected status %d", e.StatusCode)
+}
+
+// Client queries LeetCode's GraphQL API. It only sends the fixed queries
+// below; callers never supply GraphQL text.
+type Client struct {
+    url  string
+    http *http.Client
+}
+
+func NewClient(cfg config.LeetCode) *Client {
+    return &Client{
+        url:  cfg.GraphQLURL,
+        http: &http.Client{Timeout: 10 * time.Second},
+    }
+}
+
+const userProfileQuery = `
+query userProfile($username: String!) {
+  matchedUser(username: $username) {
+    username
+    profile {
+      realName
+      userAvatar
+      ranking
+    }
+  }
+}`
+
+const solvedCountsQuery = `
+query userSolvedCounts($username: String!) {
+  matchedUser(username: $username) {
+    submitStatsGlobal {
+      acSubmissionNum {
+        difficulty
+        count
+        submissions
+      }
+    }
+  }
+}`
+
+const recentAcSubmissionsQuery = `
+query recentAcSubmissions($username: String!, $limit: Int!) {
+  recentAcSubmissionList(username: $username, limit: $limit) {
+    id
+    title
+    titleSlug
+    timestamp
+  }
+}`
+
+const problemTopicsQuery = `
+query problemTopics($skip: Int!, $limit: Int!) {
+  questionList(categorySlug: "", skip: $skip, limit: $limit, filters: {}) {
+    totalNum
+    data {
+      questionFrontendId
+      topicTags {
+        slug
+      }
+    }
+  }
+}`
+
+type UserProfile struct {
+    Username string `json:"username"`
+    RealName string `json:"realName"`
+    Avatar   string `json:"avatar"`
+    Ranking  int    `json:"ranking"`
+}
+
+// SolvedCounts holds accepted problem counts per difficulty. Submissions is
+// the number of accepted submissions across all difficulties, which can
+// exceed Total when problems were solved more than once.
+type SolvedCounts struct {
+    Total       int `json:"totalSolved"`
+    Easy        int `json:"easySolved"`
+    Medium      int `json:"mediumSolved"`
+    Hard        int `json:"hardSolved"`
+    Submissions int `json:"totalSubmissions"`
+}
+
+type Submission struct {
+    ID        string    `json:"id"`
+    Title     string    `json:"title"`
+    TitleSlug string    `json:"titleSlug"`
+    Timestamp time.Time `json:"timestamp"`
+}
+
+func (c *Client) UserProfile(ctx context.Context, username string) (*UserProfile, error) {
+    var data struct {
+        MatchedUser *struct {
+            Username string `json:"username"`
+            Profile  struct {
+                RealName   string `json:"realName"`
+                UserAvatar string `json:"userAvatar"`
+                Ranking    int    `json:"ranking"`
+            } `json:"profile"`
+        } `json:"matchedUser"`
+    }
+    err := c.do(ctx, "userProfile", userProfileQuery, map[string]interface{}{"username": username}, &data)
+    if err := userResult(data.MatchedUser == nil, err); err != nil {
+        return nil, err
+    }
+
+    u := data.MatchedUser
+    return &UserProfile{
+        Username: u.Username,
+        RealName: u.Profile.RealName,
+        Avatar:   u.Profile.UserAvatar,
+        Ranking:  u.Profile.Ranking,
+    }, nil
+}
+
+func (c *Client) SolvedCounts(ctx context.Context, username string) (*SolvedCounts, error) {
+    var data struct {
+        MatchedUser *struct {
+            SubmitStats struct {
+                AcSubmissionNum []struct {
+                    Difficulty  string `json:"difficulty"`
+                    Count       int    `json:"count"`
+                    Submissions int    `json:"submissions"`
+                } `json:"acSubmissionNum"`
+            } `json:"submitStatsGlobal"`
+        } `json:"matchedUser"`
+    }
+    err := c.do(ctx, "userSolvedCounts", solvedCountsQuery, map[string]interface{}{"username": username}, &data)
+    if err := userResult(data.MatchedUser == nil, err); err != nil {
+        return nil, err
+    }
+
+    var counts SolvedCounts
+    for _, n := range data.MatchedUser.SubmitStats.AcSubmissionNum {
+        switch n.Difficulty {
+        case "All":
+            counts.Total = n.Count
+            counts.Submissions = n.Submissions
+        case "Easy":
+            counts.Easy = n.Count
+        case "Medium":
+            counts.Medium = n.Count
+        case "Hard":
+            counts.Hard = n.Count
+        }
+    }
+    return &counts, nil
+}
+
+// RecentAcceptedSubmissions returns up to limit of the user's most recent
+// accepted submissions, newest first. LeetCode caps limit at 20.
+func (c *Client) RecentAcceptedSubmissions(ctx context.Context, username string, limit int) ([]Submission, error) {
+    var data struct {
+        List []struct {
+            ID        string `json:"id"`
+            Title     string `json:"title"`
+            TitleSlug string `json:"titleSlug"`
+            Timestamp string `json:"timestamp"`
+        } `json:"recentAcSubmissionList"`
+    }
+    vars := map[string]interface{}{"username": username, "limit": limit}
+    if err := c.do(ctx, "recentAcSubmissions", recentAcSubmissionsQuery, vars, &data); err != nil {
+        var gqlErrs GraphQLErrors
+        if errors.As(err, &gqlErrs) && data.List == nil {
+            return nil, ErrUserNotFound
+        }
+        return nil, err
+    }
+
+    submissions := make([]Submission, 0, len(data.List))
+    for _, s := range data.List {
+        seconds, err := strconv.ParseInt(s.Timestamp, 10, 64)
+        if err != nil {
+            return nil, fmt.Errorf("leetcode: invalid submission timestamp %q", s.Timestamp)
+        }
+        submissions = append(submissions, Submission{
+            ID:        s.ID,
+            Title:     s.Title,
+            TitleSlug: s.TitleSlug,
+            Timestamp: time.Unix(seconds, 0).UTC(),
+        })
+    }
+    return submissions, nil
+}
+
+// ProblemTopics returns the topic slugs of up to limit problems in the
+// catalog, skipping the first skip, keyed by frontend ID. It also returns
+// the number of problems in the catalog so callers can page through it.
+func (c *Client) ProblemTopics(ctx context.Context, skip, limit int) (map[int][]string, int, error) {
+    var data struct {
+        QuestionList *struct {
+            TotalNum int `json:"totalNum"`
+            Data     []struct {
+                QuestionFrontendID string `json:"questionFrontendId"`
+                TopicTags          []struct {
+                    Slug string `json:"slug"`
+                } `json:"topicTags"`
+            } `json:"data"`
+        } `json:"questionList"`
+    }
+    vars := map[string]interface{}{"skip": skip, "limit": limit}
+    if err := c.do(ctx, "problemTopics", problemTopicsQuery, vars, &data); err != nil {
+        return nil, 0, err
+    }
+    if data.QuestionList == nil {
+        return nil, 0, errors.New("leetcode: problemTopics returned no question list")
+    }
+
+    topics := make(map[int][]string, len(data.QuestionList.Data))
+    for _, q := range data.QuestionList.Data {
+        id, err := strconv.Atoi(q.QuestionFrontendID)
+        if err != nil {
+            // Contest and LCP problems have IDs like "LCP 01", which the
+            // catalog does not use.
+            continue
+        }
+        slugs := make([]string, 0, len(q.TopicTags))
+        for _, tag := range q.TopicTags {
+            slugs = append(slugs, tag.Slug)
+        }
+        topics[id] = slugs
+    }
+    return topics, data.QuestionList.TotalNum, nil
+}
+
+// userResult interprets a matchedUser query. LeetCode reports an unknown
+// user as a null matchedUser, usually alongside a GraphQL error.
+func userResult(missing bool, err error) error {
+    var gqlErrs GraphQLErrors
+    if missing && (err == nil || errors.As(err, &gqlErrs)) {
+        return ErrUserNotFound
+    }
+    return err
+}
+
+// do posts a named query and decodes its data into dst. GraphQL errors are
+// returned as GraphQLErrors after dst has been filled with any partial data.
+func (c *Client) do(ctx context.Context, operation, query string, variables map[string]interface{}, dst interface{}) error {
+    body, err := json.Marshal(map[string]interface{}{
+        "operationName": operation,
+        "query":         query,
+        "variables":     variables,
+    })
+    if err != nil {
+        return fmt.Errorf("leetcode: failed to encode %s query: %v", operation, err)
+    }
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+    if err != nil {
+        return fmt.Errorf("leetcode: failed to create request: %v", err)
+    }
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("Referer", "https://leetcode.com")
+
+    resp, err := c.http.Do(req)
+    if err != nil {
+        return fmt.Errorf("leetcode: %s request failed: %w", operation, err)
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        io.Copy(io.Discard, resp.Body)
+        return &StatusError{StatusCode: resp.StatusCode}
+    }
+
+    var envelope struct {
+        Data   json.RawMessage `json:"data"`
+        Errors GraphQLErrors   `json:"errors"`
+    }
+    if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+        return fmt.Errorf("leetcode: failed to decode %s response: %v", operation, err)
+    }
+    if len(envelope.Data) > 0 && string(envelope.Data) != "null" {
+        if err := json.Unmarshal(envelope.Data, dst); err != nil {
+            return fmt.Errorf("leetcode: unexpected %s response: %v", operation, err)
+        }
+    }
+    if len(envelope.Errors) > 0 {
+        return envelope.Errors
+    }
+    return nil
+}
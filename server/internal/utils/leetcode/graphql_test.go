@@ -0,0 +1,122 @@
+package leetcode
+
+import (
+	"LeetTracker/internal/config"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestClient serves each request with the response registered for its
+// operation name.
+func newTestClient(t *testing.T, responses map[string]string) *Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			OperationName string                 `json:"operationName"`
+			Query         string                 `json:"query"`
+			Variables     map[string]interface{} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Query == "" {
+			t.Errorf("expected a GraphQL request, got %+v (%v)", req, err)
+		}
+		body, ok := responses[req.OperationName]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return NewClient(config.LeetCode{GraphQLURL: srv.URL})
+}
+
+func TestSolvedCounts(t *testing.T) {
+	c := newTestClient(t, map[string]string{
+		"userSolvedCounts": `{"data":{"matchedUser":{"submitStatsGlobal":{"acSubmissionNum":[
+			{"difficulty":"All","count":10,"submissions":14},{"difficulty":"Easy","count":5,"submissions":6},
+			{"difficulty":"Medium","count":4,"submissions":7},{"difficulty":"Hard","count":1,"submissions":1}]}}}}`,
+	})
+	counts, err := c.SolvedCounts(context.Background(), "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SolvedCounts{Total: 10, Easy: 5, Medium: 4, Hard: 1, Submissions: 14}
+	if *counts != want {
+		t.Fatalf("got %+v, want %+v", *counts, want)
+	}
+}
+
+func TestUnknownUser(t *testing.T) {
+	c := newTestClient(t, map[string]string{
+		"userProfile": `{"data":{"matchedUser":null},"errors":[{"message":"That user does not exist.","path":["matchedUser"]}]}`,
+	})
+	_, err := c.UserProfile(context.Background(), "nobody")
+	if !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	c := newTestClient(t, map[string]string{
+		"userProfile": `{"data":{"matchedUser":{"username":"alice","profile":{"ranking":1}}},"errors":[{"message":"rate limited","path":["matchedUser","badges",0]}]}`,
+	})
+	_, err := c.UserProfile(context.Background(), "alice")
+	var gqlErrs GraphQLErrors
+	if !errors.As(err, &gqlErrs) || gqlErrs[0].Message != "rate limited" {
+		t.Fatalf("expected GraphQLErrors, got %v", err)
+	}
+}
+
+func TestUnexpectedShape(t *testing.T) {
+	c := newTestClient(t, map[string]string{
+		"userProfile": `{"data":{"matchedUser":{"username":42}}}`,
+	})
+	if _, err := c.UserProfile(context.Background(), "alice"); err == nil {
+		t.Fatal("expected an error for a malformed response")
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	c := newTestClient(t, nil)
+	_, err := c.SolvedCounts(context.Background(), "alice")
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected *StatusError, got %v", err)
+	}
+}
+
+func TestRecentAcceptedSubmissions(t *testing.T) {
+	c := newTestClient(t, map[string]string{
+		"recentAcSubmissions": `{"data":{"recentAcSubmissionList":[
+			{"id":"2","title":"Add Two Numbers","titleSlug":"add-two-numbers","timestamp":"1700000100"},
+			{"id":"1","title":"Two Sum","titleSlug":"two-sum","timestamp":"1700000000"}]}}`,
+	})
+	subs, err := c.RecentAcceptedSubmissions(context.Background(), "alice", 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 2 || subs[0].TitleSlug != "add-two-numbers" || !subs[1].Timestamp.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("unexpected submissions %+v", subs)
+	}
+}
+
+func TestProblemTopics(t *testing.T) {
+	c := newTestClient(t, map[string]string{
+		"problemTopics": `{"data":{"questionList":{"totalNum":3,"data":[
+			{"questionFrontendId":"1","topicTags":[{"slug":"array"},{"slug":"hash-table"}]},
+			{"questionFrontendId":"2","topicTags":[]},
+			{"questionFrontendId":"LCP 01","topicTags":[{"slug":"math"}]}]}}}`,
+	})
+	topics, total, err := c.ProblemTopics(context.Background(), 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 3 || len(topics) != 2 || len(topics[1]) != 2 || topics[1][1] != "hash-table" || topics[2] == nil {
+		t.Fatalf("unexpected topics %v (total %d)", topics, total)
+	}
+}
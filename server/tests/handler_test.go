@@ -1,11 +1,14 @@
 package tests
 
 import (
+	"LeetTracker/internal/config"
 	"LeetTracker/internal/server"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestHandler(t *testing.T) {
@@ -30,3 +33,44 @@ func TestHandler(t *testing.T) {
 		t.Errorf("expected response body to be %v; got %v", expected, string(body))
 	}
 }
+
+func TestUnknownRouteReturnsErrorEnvelope(t *testing.T) {
+	srv, err := server.NewServer(testConfig())
+	if err != nil {
+		t.Fatalf("error creating server. Err: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/does-not-exist", nil)
+	req.Header.Set("X-Request-ID", "test-request")
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404; got %d", rec.Code)
+	}
+	var body struct {
+		Error struct {
+			Code      string `json:"code"`
+			Message   string `json:"message"`
+			RequestID string `json:"request_id"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("error decoding response body. Err: %v", err)
+	}
+	if body.Error.Code != "not_found" {
+		t.Errorf("expected code not_found; got %q", body.Error.Code)
+	}
+	if body.Error.RequestID != "test-request" {
+		t.Errorf("expected request_id test-request; got %q", body.Error.RequestID)
+	}
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Server:   config.Server{Port: 8080, AllowedOrigins: []string{"http://localhost:5173"}, StartupTimeout: time.Second, ShutdownTimeout: time.Second, WebURL: "http://localhost:5173/"},
+		Database: config.Database{Host: "localhost", Port: "5432", Name: "test", Username: "test", Schema: "public"},
+		Redis:    config.Redis{Addr: "localhost:6379"},
+		Auth:     config.Auth{Domain: "example.auth0.com"},
+		LeetCode: config.LeetCode{ProblemsURL: "http://localhost/problems", GraphQLURL: "http://localhost/graphql"},
+	}
+}
@@ -0,0 +1,1714 @@
+package tests
+
+import (
+	"LeetTracker/internal/database"
+	"LeetTracker/internal/progress"
+	"LeetTracker/internal/server"
+	"LeetTracker/internal/studyplan"
+	"LeetTracker/internal/utils/cache"
+	"LeetTracker/internal/utils/leetcode"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// headerAuthenticator trusts "Authorization: Bearer <user ID>" so tests can
+// act as any user without minting JWTs.
+type headerAuthenticator struct{}
+
+func (headerAuthenticator) Authenticate(r *http.Request) (string, error) {
+	userID, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || userID == "" {
+		return "", errors.New("Required authorization token not found")
+	}
+	return userID, nil
+}
+
+type fakeProblems struct {
+	problems []leetcode.Problem
+	err      error
+}
+
+func (f *fakeProblems) FetchLeetCodeProblems() ([]leetcode.Problem, error) {
+	return f.problems, f.err
+}
+
+func (f *fakeProblems) InvalidateCache() error {
+	return f.err
+}
+
+// fixture is a server over an in-memory database where alice and bob each
+// own one list holding problem 1, and bob has rated problem 1. Both have
+// linked a LeetCode username with recorded progress: alice's is private and
+// bob's is visible to his team. bob owns a study group that alice has joined
+// as a member, with a group list holding problem 2. alice has a leaderboard
+// of herself and bob, a daily study plan on her list that has problem 1
+// due today, and a 45 minute mock interview on problems 2 and 3 that she
+// has just started. The group list has a problem of the day drawn from the
+// catalog's Medium problems, which picked problem 2 yesterday.
+type fixture struct {
+	handler  http.Handler
+	db       database.Service
+	problems *fakeProblems
+	ids      *strings.Replacer
+}
+
+const (
+	alice = "auth0|alice"
+	bob   = "auth0|bob"
+	admin = "auth0|admin"
+)
+
+const groupInviteCode = "WEEKLYPREP"
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	ctx := context.Background()
+	catalog := []leetcode.Problem{
+		{FrontendID: 1, Title: "Two Sum", TitleSlug: "two-sum", Difficulty: "Easy", AcceptanceRate: 50, URL: "https://leetcode.com/problems/two-sum/", Topics: []string{"array", "hash-table"}},
+		{FrontendID: 2, Title: "Add Two Numbers", TitleSlug: "add-two-numbers", Difficulty: "Medium", AcceptanceRate: 40, URL: "https://leetcode.com/problems/add-two-numbers/", Topics: []string{"linked-list", "math"}},
+		{FrontendID: 3, Title: "Longest Substring Without Repeating Characters", TitleSlug: "longest-substring-without-repeating-characters", Difficulty: "Medium", AcceptanceRate: 35, URL: "https://leetcode.com/problems/longest-substring-without-repeating-characters/", Topics: []string{"hash-table", "sliding-window", "string"}},
+	}
+
+	db := database.NewMemory()
+	must(t, db.InsertLeetCodeProblems(ctx, catalog))
+	lists := make(map[string]int)
+	for _, user := range []string{alice, bob} {
+		must(t, db.EnsureUserExists(ctx, user))
+		id, err := db.CreateList(ctx, user, &database.List{Name: user + "'s list", Difficulty: "easy"})
+		must(t, err)
+		must(t, db.AddProblemsToList(ctx, id, []int{1}))
+		lists[user] = id
+	}
+	must(t, db.SaveProblemFeedback(ctx, &database.ProblemFeedback{ProblemID: 1, UserID: bob, DifficultyRating: 2, Comment: "hash map warm-up"}))
+	for user, visibility := range map[string]string{alice: database.VisibilityPrivate, bob: database.VisibilityTeam} {
+		username := strings.TrimPrefix(user, "auth0|")
+		must(t, db.UpdateAccount(ctx, &database.Account{UserID: user, LeetCodeUsername: &username, ProgressVisibility: visibility}))
+		must(t, db.StoreLeetCodeUserProgress(ctx, username, leetcode.SolvedCounts{Total: 3, Easy: 2, Medium: 1}))
+	}
+	group := &database.Group{Name: "Weekly prep", InviteCode: groupInviteCode}
+	must(t, db.CreateGroup(ctx, bob, group))
+	_, err := db.JoinGroup(ctx, groupInviteCode, alice)
+	must(t, err)
+	groupList, err := db.CreateList(ctx, bob, &database.List{GroupID: &group.ID, Name: "Weekly prep list", Difficulty: "medium"})
+	must(t, err)
+	must(t, db.AddProblemsToList(ctx, groupList, []int{2}))
+	groupItems, err := db.GetListItems(ctx, groupList, bob)
+	must(t, err)
+	leaderboard := &database.Leaderboard{OwnerID: alice, Name: "Friends", Weights: database.Weights{Easy: 1, Medium: 2, Hard: 4},
+		TieBreak: database.TieBreakHardest, Participants: []string{"alice", "bob"}}
+	must(t, db.CreateLeaderboard(ctx, leaderboard))
+	aliceItems, err := db.GetListItems(ctx, lists[alice], alice)
+	must(t, err)
+	bobItems, err := db.GetListItems(ctx, lists[bob], bob)
+	must(t, err)
+	today := studyplan.Day(time.Now().UTC())
+	plan := &database.StudyPlan{UserID: alice, ListID: lists[alice], StartDate: today, DaysPerWeek: 7, ProblemsPerDay: 1}
+	must(t, db.CreateStudyPlan(ctx, plan, studyplan.Schedule([]int{aliceItems[0].ID}, today, 7, 1)))
+	started := time.Now().UTC().Truncate(time.Second)
+	interview := &database.InterviewSession{UserID: alice, TimeLimitMinutes: 45, StartedAt: started, ExpiresAt: started.Add(45 * time.Minute),
+		Problems: []database.InterviewProblem{{ProblemID: 2}, {ProblemID: 3}}}
+	must(t, db.CreateInterviewSession(ctx, interview))
+	must(t, db.SetDailySettings(ctx, &database.DailySettings{ListID: groupList, Source: database.DailySourceCatalog,
+		Difficulties: []string{"medium"}, Topics: []string{}, NoRepeatDays: 30}))
+	must(t, db.PickDailyProblem(ctx, groupList, today.AddDate(0, 0, -1), 2))
+
+	graphql := httptest.NewServer(http.HandlerFunc(fakeGraphQL))
+	t.Cleanup(graphql.Close)
+
+	cfg := testConfig()
+	cfg.Auth.AdminUserIDs = []string{admin}
+	cfg.LeetCode.GraphQLURL = graphql.URL
+
+	problems := &fakeProblems{problems: catalog}
+	srv := server.New(cfg, server.Dependencies{
+		DB:            db,
+		Problems:      problems,
+		Cache:         cache.NewMemory(),
+		Authenticator: headerAuthenticator{},
+	})
+	return &fixture{
+		handler:  srv.Handler(),
+		db:       db,
+		problems: problems,
+		ids: strings.NewReplacer(
+			"{aliceList}", strconv.Itoa(lists[alice]),
+			"{bobList}", strconv.Itoa(lists[bob]),
+			"{aliceItem}", strconv.Itoa(aliceItems[0].ID),
+			"{bobItem}", strconv.Itoa(bobItems[0].ID),
+			"{group}", strconv.Itoa(group.ID),
+			"{groupList}", strconv.Itoa(groupList),
+			"{groupItem}", strconv.Itoa(groupItems[0].ID),
+			"{leaderboard}", strconv.Itoa(leaderboard.ID),
+			"{plan}", strconv.Itoa(plan.ID),
+			"{interview}", strconv.Itoa(interview.ID),
+			"{today}", today.Format("2006-01-02"),
+			"{yesterday}", today.AddDate(0, 0, -1).Format("2006-01-02"),
+		),
+	}
+}
+
+// leetCodeUsers are the users fakeGraphQL knows, by lower-cased name. Only
+// alice has linked her account; NeetCode has not signed up.
+var leetCodeUsers = map[string]string{"alice": "alice", "neetcode": "NeetCode"}
+
+// fakeGraphQL answers the LeetCode client's named queries. It matches
+// usernames case-insensitively like LeetCode, and fails outright for
+// "broken".
+func fakeGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		OperationName string `json:"operationName"`
+		Variables     struct {
+			Username string `json:"username"`
+		} `json:"variables"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case req.Variables.Username == "broken":
+		w.WriteHeader(http.StatusInternalServerError)
+	case leetCodeUsers[strings.ToLower(req.Variables.Username)] == "":
+		w.Write([]byte(`{"data":{"matchedUser":null},"errors":[{"message":"That user does not exist."}]}`))
+	case req.OperationName == "userProfile":
+		fmt.Fprintf(w, `{"data":{"matchedUser":{"username":%q,"profile":{"realName":"","userAvatar":"","ranking":1234}}}}`, leetCodeUsers[strings.ToLower(req.Variables.Username)])
+	case req.OperationName == "userSolvedCounts":
+		w.Write([]byte(`{"data":{"matchedUser":{"submitStatsGlobal":{"acSubmissionNum":[
+			{"difficulty":"All","count":6,"submissions":9},{"difficulty":"Easy","count":3,"submissions":4},
+			{"difficulty":"Medium","count":2,"submissions":4},{"difficulty":"Hard","count":1,"submissions":1}]}}}}`))
+	case req.OperationName == "recentAcSubmissions":
+		w.Write([]byte(`{"data":{"recentAcSubmissionList":[
+			{"id":"3","title":"Design Skiplist","titleSlug":"design-skiplist","timestamp":"1700000200"},
+			{"id":"2","title":"Two Sum","titleSlug":"two-sum","timestamp":"1700000100"},
+			{"id":"1","title":"Two Sum","titleSlug":"two-sum","timestamp":"1700000000"}]}}`))
+	default:
+		w.Write([]byte(`{"data":null,"errors":[{"message":"unexpected operation"}]}`))
+	}
+}
+
+// expectImport checks the counts in a submission import response.
+func expectImport(t *testing.T, rec *httptest.ResponseRecorder, submissions, matched, completed int) {
+	t.Helper()
+	var resp struct {
+		Submissions    int                   `json:"submissions"`
+		Matched        int                   `json:"matched"`
+		UnmatchedSlugs []string              `json:"unmatched_slugs"`
+		Completed      []database.SolvedItem `json:"completed"`
+	}
+	must(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	if resp.Submissions != submissions || resp.Matched != matched || len(resp.Completed) != completed {
+		t.Errorf("expected %d submissions, %d matched and %d completed, got %+v", submissions, matched, completed, resp)
+	}
+}
+
+// aliceItem returns the single item in alice's list.
+func aliceItem(t *testing.T, f *fixture) database.ListItem {
+	t.Helper()
+	lists, err := f.db.GetUserLists(context.Background(), alice)
+	must(t, err)
+	items, err := f.db.GetListItems(context.Background(), lists[0].ID, alice)
+	must(t, err)
+	return items[0]
+}
+
+// shareAliceList returns a setup that shares alice's list with userID.
+func shareAliceList(userID, role string) func(f *fixture) {
+	return func(f *fixture) {
+		lists, err := f.db.GetUserLists(context.Background(), alice)
+		if err == nil {
+			err = f.db.SetListMember(context.Background(), lists[0].ID, userID, role)
+		}
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// studyPlanResponse is the body of the study plan routes that return one
+// plan with its schedule.
+type studyPlanResponse struct {
+	database.StudyPlan
+	Status   studyplan.Status      `json:"status"`
+	Schedule []database.Assignment `json:"schedule"`
+}
+
+// movePlanItem returns a setup that moves the problem on alice's study plan
+// by days.
+func movePlanItem(days int) func(f *fixture) {
+	return func(f *fixture) {
+		plans, err := f.db.GetUserStudyPlans(context.Background(), alice)
+		if err != nil {
+			panic(err)
+		}
+		schedule, err := f.db.GetStudyPlanSchedule(context.Background(), plans[0].ID)
+		if err == nil {
+			schedule[0].DueDate = schedule[0].DueDate.AddDate(0, 0, days)
+			err = f.db.RescheduleStudyPlan(context.Background(), plans[0].ID, schedule)
+		}
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// interviewResponse is the body of the mock interview routes that return
+// one session.
+type interviewResponse struct {
+	database.InterviewSession
+	Status           string `json:"status"`
+	RemainingSeconds int    `json:"remaining_seconds"`
+	Score            struct {
+		Solved     int     `json:"solved"`
+		Unanswered int     `json:"unanswered"`
+		Percent    float64 `json:"percent"`
+	} `json:"score"`
+}
+
+// endAliceInterview is a setup that ends alice's mock interview.
+func endAliceInterview(f *fixture) {
+	sessions, err := f.db.GetUserInterviewSessions(context.Background(), alice, 1)
+	if err == nil {
+		err = f.db.EndInterviewSession(context.Background(), sessions[0].ID, time.Now().UTC())
+	}
+	if err != nil {
+		panic(err)
+	}
+}
+
+// recommendations is the body of GET /recommendations.
+type recommendations struct {
+	Level           string `json:"level"`
+	Recommendations []struct {
+		ProblemID int     `json:"problem_id"`
+		Score     float64 `json:"score"`
+		Reasons   []struct {
+			Code string `json:"code"`
+		} `json:"reasons"`
+	} `json:"recommendations"`
+}
+
+// addPremiumProblem is a setup that adds an approachable premium Easy
+// problem to the catalog.
+func addPremiumProblem(f *fixture) {
+	err := f.db.InsertLeetCodeProblems(context.Background(), []leetcode.Problem{
+		{FrontendID: 4, Title: "Premium Warm-up", TitleSlug: "premium-warm-up", Difficulty: "Easy", AcceptanceRate: 90, IsPremium: true, URL: "https://leetcode.com/problems/premium-warm-up/"},
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// giveAlicePremium is a setup that records that alice has LeetCode Premium.
+func giveAlicePremium(f *fixture) {
+	username := "alice"
+	err := f.db.UpdateAccount(context.Background(), &database.Account{UserID: alice, LeetCodeUsername: &username, ProgressVisibility: database.VisibilityPrivate, LeetCodePremium: true})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// serve sends a request to the fixture's server as user.
+func serve(f *fixture, method, path, user, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if user != "" {
+		req.Header.Set("Authorization", "Bearer "+user)
+	}
+	rec := httptest.NewRecorder()
+	f.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// groupID returns the ID of bob's study group.
+func groupID(t *testing.T, f *fixture) int {
+	t.Helper()
+	groups, err := f.db.GetUserGroups(context.Background(), bob)
+	must(t, err)
+	return groups[0].ID
+}
+
+// groupListID returns the ID of the group list.
+func groupListID(f *fixture) int {
+	groups, err := f.db.GetUserGroups(context.Background(), bob)
+	if err != nil {
+		panic(err)
+	}
+	lists, err := f.db.GetGroupLists(context.Background(), groups[0].ID)
+	if err != nil {
+		panic(err)
+	}
+	return lists[0].ID
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+type routeCase struct {
+	name string
+	// route is the template registered in RegisterRoutes; path is what is
+	// requested and may use the fixture's {aliceList}-style placeholders.
+	route  string
+	method string
+	path   string
+	user   string
+	body   string
+	setup  func(f *fixture)
+	status int
+	// code is the expected error code for failures.
+	code  string
+	check func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder)
+}
+
+func routeCases() []routeCase {
+	return []routeCase{
+		{name: "hello", route: "/hello", method: "GET", path: "/hello", status: 200},
+		{name: "liveness", route: "/healthz", method: "GET", path: "/healthz", status: 200},
+		{name: "readiness", route: "/readyz", method: "GET", path: "/readyz", status: 200},
+
+		{name: "db stats as admin", route: "/admin/db-stats", method: "GET", path: "/admin/db-stats", user: admin, status: 200},
+		{name: "db stats unauthenticated", route: "/admin/db-stats", method: "GET", path: "/admin/db-stats", status: 401, code: "unauthorized"},
+		{name: "db stats as non-admin", route: "/admin/db-stats", method: "GET", path: "/admin/db-stats", user: alice, status: 403, code: "forbidden"},
+
+		{name: "fetch problems", route: "/fetch-leetcode-problems", method: "GET", path: "/fetch-leetcode-problems", status: 200},
+		{name: "fetch problems upstream failure", route: "/fetch-leetcode-problems", method: "GET", path: "/fetch-leetcode-problems", status: 502, code: "bad_gateway",
+			setup: func(f *fixture) { f.problems.err = errors.New("leetcode is down") }},
+		{name: "invalidate cache", route: "/invalidate-leetcode-cache", method: "POST", path: "/invalidate-leetcode-cache", status: 200},
+		{name: "invalidate cache failure", route: "/invalidate-leetcode-cache", method: "POST", path: "/invalidate-leetcode-cache", status: 500, code: "internal_error",
+			setup: func(f *fixture) { f.problems.err = errors.New("cache is down") }},
+
+		{name: "create list", route: "/lists", method: "POST", path: "/lists", user: alice, status: 200,
+			body: `{"name":"Graphs","difficulty":"medium","tags":"bfs"}`},
+		{name: "create list for new user", route: "/lists", method: "POST", path: "/lists", user: "auth0|carol", status: 200,
+			body: `{"name":"First list","difficulty":"easy"}`},
+		{name: "create list unauthenticated", route: "/lists", method: "POST", path: "/lists", status: 401, code: "unauthorized",
+			body: `{"name":"Graphs","difficulty":"medium"}`},
+		{name: "create list malformed", route: "/lists", method: "POST", path: "/lists", user: alice, status: 400, code: "bad_request",
+			body: `{"name":`},
+		{name: "create list invalid", route: "/lists", method: "POST", path: "/lists", user: alice, status: 422, code: "validation_failed",
+			body: `{"name":"","difficulty":"impossible"}`},
+
+		{name: "get lists", route: "/getlists", method: "GET", path: "/getlists", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var lists []database.List
+				decode(t, rec, &lists)
+				if len(lists) != 2 || lists[0].Name != alice+"'s list" || lists[1].Name != "Weekly prep list" || lists[1].GroupID == nil {
+					t.Errorf("expected alice's list, then her group's, got %+v", lists)
+				}
+			}},
+		{name: "get lists unauthenticated", route: "/getlists", method: "GET", path: "/getlists", status: 401, code: "unauthorized"},
+
+		{name: "list items", route: "/lists/{id}/items", method: "GET", path: "/lists/{aliceList}/items", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var items []database.ListItem
+				decode(t, rec, &items)
+				if len(items) != 1 || items[0].ProblemTitle != "Two Sum" {
+					t.Errorf("unexpected items %+v", items)
+				}
+			}},
+		{name: "list items of another user", route: "/lists/{id}/items", method: "GET", path: "/lists/{bobList}/items", user: alice, status: 404, code: "not_found"},
+		{name: "list items bad id", route: "/lists/{id}/items", method: "GET", path: "/lists/abc/items", user: alice, status: 400, code: "bad_request"},
+		{name: "list items unauthenticated", route: "/lists/{id}/items", method: "GET", path: "/lists/{aliceList}/items", status: 401, code: "unauthorized"},
+
+		{name: "catalog page", route: "/leetcode-problems", method: "GET", path: "/leetcode-problems?page=1&pageSize=2", status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var page struct {
+					Problems []struct {
+						FrontendID int                       `json:"frontendQuestionId"`
+						Difficulty string                    `json:"difficulty"`
+						Community  *database.CommunityRating `json:"community"`
+					} `json:"problems"`
+					TotalPages int `json:"totalPages"`
+				}
+				decode(t, rec, &page)
+				if len(page.Problems) != 2 || page.TotalPages != 2 {
+					t.Fatalf("unexpected page %+v", page)
+				}
+				rated, unrated := page.Problems[0], page.Problems[1]
+				if rated.Difficulty != "Easy" || rated.Community == nil || rated.Community.AvgDifficulty != 2 {
+					t.Errorf("expected official difficulty and community rating for problem 1, got %+v", rated)
+				}
+				if unrated.Community != nil {
+					t.Errorf("expected no community rating for problem 2, got %+v", unrated.Community)
+				}
+			}},
+		{name: "catalog malformed paging falls back to defaults", route: "/leetcode-problems", method: "GET", path: "/leetcode-problems?page=x&pageSize=-1", status: 200},
+
+		{name: "problem feedback", route: "/problems/{id}/feedback", method: "GET", path: "/problems/1/feedback", status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var body struct {
+					Community *database.CommunityRating  `json:"community"`
+					Feedback  []database.ProblemFeedback `json:"feedback"`
+				}
+				decode(t, rec, &body)
+				if body.Community == nil || body.Community.Ratings != 1 || len(body.Feedback) != 1 || body.Feedback[0].Comment != "hash map warm-up" {
+					t.Errorf("unexpected feedback %+v", body)
+				}
+				if strings.Contains(rec.Body.String(), bob) {
+					t.Errorf("feedback must not expose user IDs: %s", rec.Body.String())
+				}
+			}},
+		{name: "problem feedback unknown problem", route: "/problems/{id}/feedback", method: "GET", path: "/problems/999/feedback", status: 404, code: "not_found"},
+		{name: "problem feedback bad id", route: "/problems/{id}/feedback", method: "GET", path: "/problems/abc/feedback", status: 400, code: "bad_request"},
+		{name: "save feedback", route: "/problems/{id}/feedback", method: "PUT", path: "/problems/1/feedback", user: alice, status: 200,
+			body: `{"difficulty_rating":4,"interview_likelihood":5,"comment":"asked at my onsite"}`,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				ratings, err := f.db.GetCommunityRatings(context.Background(), []int{1})
+				if err != nil || ratings[1].Ratings != 2 || ratings[1].AvgDifficulty != 3 {
+					t.Errorf("expected alice's rating to be aggregated, got %+v (%v)", ratings[1], err)
+				}
+			}},
+		{name: "save feedback unknown problem", route: "/problems/{id}/feedback", method: "PUT", path: "/problems/999/feedback", user: alice, status: 404, code: "not_found",
+			body: `{"difficulty_rating":4}`},
+		{name: "save feedback out of range", route: "/problems/{id}/feedback", method: "PUT", path: "/problems/1/feedback", user: alice, status: 422, code: "validation_failed",
+			body: `{"difficulty_rating":6,"interview_likelihood":0}`},
+		{name: "save feedback malformed", route: "/problems/{id}/feedback", method: "PUT", path: "/problems/1/feedback", user: alice, status: 400, code: "bad_request",
+			body: `{"difficulty_rating":`},
+		{name: "save feedback unauthenticated", route: "/problems/{id}/feedback", method: "PUT", path: "/problems/1/feedback", status: 401, code: "unauthorized",
+			body: `{"difficulty_rating":4}`},
+		{name: "delete feedback", route: "/problems/{id}/feedback", method: "DELETE", path: "/problems/1/feedback", user: bob, status: 200},
+		{name: "delete feedback never given", route: "/problems/{id}/feedback", method: "DELETE", path: "/problems/1/feedback", user: alice, status: 404, code: "not_found"},
+		{name: "delete feedback unauthenticated", route: "/problems/{id}/feedback", method: "DELETE", path: "/problems/1/feedback", status: 401, code: "unauthorized"},
+
+		{name: "add problems", route: "/lists/add-problem", method: "POST", path: "/lists/add-problem", user: alice, status: 200,
+			body: `{"list_id":{aliceList},"problem_ids":[2,3]}`},
+		{name: "add problems to another user's list", route: "/lists/add-problem", method: "POST", path: "/lists/add-problem", user: alice, status: 404, code: "not_found",
+			body: `{"list_id":{bobList},"problem_ids":[2]}`},
+		{name: "add unknown problem", route: "/lists/add-problem", method: "POST", path: "/lists/add-problem", user: alice, status: 422, code: "validation_failed",
+			body: `{"list_id":{aliceList},"problem_ids":[999]}`},
+		{name: "add problems malformed", route: "/lists/add-problem", method: "POST", path: "/lists/add-problem", user: alice, status: 400, code: "bad_request",
+			body: `not json`},
+		{name: "add problems unauthenticated", route: "/lists/add-problem", method: "POST", path: "/lists/add-problem", status: 401, code: "unauthorized",
+			body: `{"list_id":{aliceList},"problem_ids":[2]}`},
+
+		{name: "delete list", route: "/lists/{id}", method: "DELETE", path: "/lists/{aliceList}", user: alice, status: 200},
+		{name: "delete another user's list", route: "/lists/{id}", method: "DELETE", path: "/lists/{bobList}", user: alice, status: 404, code: "not_found"},
+		{name: "delete list bad id", route: "/lists/{id}", method: "DELETE", path: "/lists/abc", user: alice, status: 400, code: "bad_request"},
+		{name: "delete list unauthenticated", route: "/lists/{id}", method: "DELETE", path: "/lists/{aliceList}", status: 401, code: "unauthorized"},
+
+		{name: "remove problem", route: "/lists/remove-problem", method: "POST", path: "/lists/remove-problem", user: alice, status: 200,
+			body: `{"list_id":{aliceList},"problem_id":1}`},
+		{name: "remove problem from another user's list", route: "/lists/remove-problem", method: "POST", path: "/lists/remove-problem", user: alice, status: 404, code: "not_found",
+			body: `{"list_id":{bobList},"problem_id":1}`},
+		{name: "remove problem not in list", route: "/lists/remove-problem", method: "POST", path: "/lists/remove-problem", user: alice, status: 404, code: "not_found",
+			body: `{"list_id":{aliceList},"problem_id":2}`},
+		{name: "remove problem malformed", route: "/lists/remove-problem", method: "POST", path: "/lists/remove-problem", user: alice, status: 400, code: "bad_request",
+			body: `{"list_id":`},
+		{name: "remove problem unauthenticated", route: "/lists/remove-problem", method: "POST", path: "/lists/remove-problem", status: 401, code: "unauthorized",
+			body: `{"list_id":{aliceList},"problem_id":1}`},
+
+		{name: "complete item", route: "/list-items/{id}/completion", method: "PUT", path: "/list-items/{aliceItem}/completion", user: alice, status: 200,
+			body: `{"completed":true}`},
+		{name: "complete another user's item", route: "/list-items/{id}/completion", method: "PUT", path: "/list-items/{bobItem}/completion", user: alice, status: 403, code: "forbidden",
+			body: `{"completed":true}`},
+		{name: "complete item without flag", route: "/list-items/{id}/completion", method: "PUT", path: "/list-items/{aliceItem}/completion", user: alice, status: 422, code: "validation_failed",
+			body: `{}`},
+		{name: "complete item bad id", route: "/list-items/{id}/completion", method: "PUT", path: "/list-items/abc/completion", user: alice, status: 400, code: "bad_request",
+			body: `{"completed":true}`},
+		{name: "complete item unauthenticated", route: "/list-items/{id}/completion", method: "PUT", path: "/list-items/{aliceItem}/completion", status: 401, code: "unauthorized",
+			body: `{"completed":true}`},
+
+		{name: "leetcode stats", route: "/leetcode-stats", method: "POST", path: "/leetcode-stats", status: 200,
+			body: `{"username":"alice"}`,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var stats struct {
+					Username    string `json:"username"`
+					Ranking     int    `json:"ranking"`
+					TotalSolved int    `json:"totalSolved"`
+					HardSolved  int    `json:"hardSolved"`
+				}
+				decode(t, rec, &stats)
+				if stats.Username != "alice" || stats.Ranking != 1234 || stats.TotalSolved != 6 || stats.HardSolved != 1 {
+					t.Errorf("unexpected stats %+v", stats)
+				}
+				history, err := f.db.GetUserProgressHistory(context.Background(), "alice")
+				if err != nil || len(history) != 1 || history[0].TotalSolved != 6 {
+					t.Errorf("expected stats to be stored, got %+v (%v)", history, err)
+				}
+			}},
+		{name: "leetcode stats unknown user", route: "/leetcode-stats", method: "POST", path: "/leetcode-stats", status: 404, code: "not_found",
+			body: `{"username":"nobody"}`},
+		{name: "leetcode stats upstream failure", route: "/leetcode-stats", method: "POST", path: "/leetcode-stats", status: 502, code: "bad_gateway",
+			body: `{"username":"broken"}`},
+		{name: "leetcode stats rejects raw queries", route: "/leetcode-stats", method: "POST", path: "/leetcode-stats", status: 422, code: "validation_failed",
+			body: `{"query":"{ allQuestions { title } }"}`},
+		{name: "leetcode stats invalid username", route: "/leetcode-stats", method: "POST", path: "/leetcode-stats", status: 422, code: "validation_failed",
+			body: `{"username":"alice; drop"}`},
+		{name: "leetcode stats malformed", route: "/leetcode-stats", method: "POST", path: "/leetcode-stats", status: 400, code: "bad_request",
+			body: `{"username"`},
+
+		{name: "progress history for me", route: "/user-progress-history", method: "GET", path: "/user-progress-history", user: alice, status: 200,
+			check: expectHistory(1)},
+		{name: "progress history of my own username", route: "/user-progress-history", method: "GET", path: "/user-progress-history?username=alice", user: alice, status: 200,
+			check: expectHistory(1)},
+		{name: "progress history for me anonymously", route: "/user-progress-history", method: "GET", path: "/user-progress-history", status: 401, code: "unauthorized"},
+		{name: "progress history for me unlinked", route: "/user-progress-history", method: "GET", path: "/user-progress-history", user: admin, status: 404, code: "not_found"},
+		{name: "progress history private", route: "/user-progress-history", method: "GET", path: "/user-progress-history?username=alice", user: bob, status: 403, code: "forbidden"},
+		{name: "progress history team", route: "/user-progress-history", method: "GET", path: "/user-progress-history?username=bob", user: alice, status: 200,
+			check: expectHistory(1)},
+		{name: "progress history team anonymously", route: "/user-progress-history", method: "GET", path: "/user-progress-history?username=bob", status: 403, code: "forbidden"},
+		{name: "progress history public anonymously", route: "/user-progress-history", method: "GET", path: "/user-progress-history?username=alice", status: 200,
+			setup: func(f *fixture) {
+				account, err := f.db.GetAccount(context.Background(), alice)
+				if err == nil {
+					account.ProgressVisibility = database.VisibilityPublic
+					err = f.db.UpdateAccount(context.Background(), account)
+				}
+				if err != nil {
+					panic(err)
+				}
+			},
+			check: expectHistory(1)},
+		{name: "progress history by month", route: "/user-progress-history", method: "GET", path: "/user-progress-history?granularity=month&from=2020-01-01", user: alice, status: 200,
+			check: expectHistory(1)},
+		{name: "progress history before any snapshot", route: "/user-progress-history", method: "GET", path: "/user-progress-history?to=2020-01-31", user: alice, status: 200,
+			check: expectHistory(0)},
+		{name: "progress history unknown granularity", route: "/user-progress-history", method: "GET", path: "/user-progress-history?granularity=year", user: alice, status: 422, code: "validation_failed"},
+		{name: "progress history bad date", route: "/user-progress-history", method: "GET", path: "/user-progress-history?from=yesterday", user: alice, status: 422, code: "validation_failed"},
+		{name: "progress history inverted range", route: "/user-progress-history", method: "GET", path: "/user-progress-history?from=2024-02-01&to=2024-01-01", user: alice, status: 422, code: "validation_failed"},
+		{name: "compare progress", route: "/progress/compare", method: "GET", path: "/progress/compare?usernames=alice,bob&granularity=week", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var resp struct {
+					Granularity string      `json:"granularity"`
+					Dates       []time.Time `json:"dates"`
+					Users       []struct {
+						Username string             `json:"username"`
+						Summary  progress.Summary   `json:"summary"`
+						Series   []*progress.Bucket `json:"series"`
+					} `json:"users"`
+				}
+				decode(t, rec, &resp)
+				if resp.Granularity != "week" || len(resp.Dates) != 1 || len(resp.Users) != 2 {
+					t.Fatalf("expected two users over one week, got %+v", resp)
+				}
+				for _, u := range resp.Users {
+					if len(u.Series) != 1 || u.Series[0] == nil || u.Summary.TotalSolved != 3 {
+						t.Errorf("unexpected series for %s: %+v", u.Username, u)
+					}
+				}
+				if resp.Users[0].Username != "alice" || resp.Users[1].Username != "bob" {
+					t.Errorf("expected users in request order, got %+v", resp.Users)
+				}
+			}},
+		{name: "compare progress including a private user", route: "/progress/compare", method: "GET", path: "/progress/compare?usernames=bob,alice", user: bob, status: 403, code: "forbidden"},
+		{name: "compare progress anonymously", route: "/progress/compare", method: "GET", path: "/progress/compare?usernames=bob", status: 403, code: "forbidden"},
+		{name: "compare progress unlinked username", route: "/progress/compare", method: "GET", path: "/progress/compare?usernames=bob,carol", user: alice, status: 404, code: "not_found"},
+		{name: "compare progress duplicate usernames", route: "/progress/compare", method: "GET", path: "/progress/compare?usernames=bob,Bob", user: alice, status: 422, code: "validation_failed"},
+		{name: "compare progress without usernames", route: "/progress/compare", method: "GET", path: "/progress/compare?granularity=hour", user: alice, status: 422, code: "validation_failed"},
+
+		{name: "forecast reached goal", route: "/progress/forecast", method: "GET", path: "/progress/forecast?target=2&metric=easy", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var goal progress.Goal
+				decode(t, rec, &goal)
+				if !goal.Reached || goal.Current != 2 || goal.Metric != "easy" {
+					t.Errorf("expected alice's easy goal to be reached, got %+v", goal)
+				}
+			}},
+		{name: "forecast with one snapshot", route: "/progress/forecast", method: "GET", path: "/progress/forecast?username=bob&target=50", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var goal progress.Goal
+				decode(t, rec, &goal)
+				if goal.Reached || goal.WindowDays != 28 || len(goal.Forecasts) != 2 || goal.Forecasts[0].ProjectedDate != nil || goal.Forecasts[0].Reason == "" {
+					t.Errorf("expected unfit models with a reason, got %+v", goal)
+				}
+			}},
+		{name: "forecast without target", route: "/progress/forecast", method: "GET", path: "/progress/forecast?window=400", user: alice, status: 422, code: "validation_failed"},
+		{name: "forecast private", route: "/progress/forecast", method: "GET", path: "/progress/forecast?username=alice&target=10", user: bob, status: 403, code: "forbidden"},
+		{name: "forecast for me anonymously", route: "/progress/forecast", method: "GET", path: "/progress/forecast?target=10", status: 401, code: "unauthorized"},
+		{name: "progress history unlinked username", route: "/user-progress-history", method: "GET", path: "/user-progress-history?username=carol", user: alice, status: 404, code: "not_found"},
+
+		{name: "create group", route: "/groups", method: "POST", path: "/groups", user: alice, status: 200,
+			body: `{"name":"  Graph club ","description":"BFS and friends"}`,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var group struct {
+					database.Group
+					InviteLink string `json:"invite_link"`
+				}
+				decode(t, rec, &group)
+				if group.Name != "Graph club" || group.Role != database.RoleOwner || group.MemberCount != 1 {
+					t.Errorf("unexpected group %+v", group)
+				}
+				if len(group.InviteCode) != 10 || group.InviteLink != "http://localhost:5173/groups/join/"+group.InviteCode {
+					t.Errorf("expected an invite code and link, got %q and %q", group.InviteCode, group.InviteLink)
+				}
+			}},
+		{name: "create group invalid", route: "/groups", method: "POST", path: "/groups", user: alice, status: 422, code: "validation_failed",
+			body: `{"name":" "}`},
+		{name: "create group unauthenticated", route: "/groups", method: "POST", path: "/groups", status: 401, code: "unauthorized",
+			body: `{"name":"Graph club"}`},
+		{name: "get groups as member", route: "/groups", method: "GET", path: "/groups", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var groups []map[string]interface{}
+				decode(t, rec, &groups)
+				if len(groups) != 1 || groups[0]["name"] != "Weekly prep" || groups[0]["role"] != "member" {
+					t.Fatalf("unexpected groups %+v", groups)
+				}
+				if _, ok := groups[0]["invite_code"]; ok {
+					t.Errorf("expected the invite code to be hidden from members, got %+v", groups[0])
+				}
+			}},
+		{name: "get groups unauthenticated", route: "/groups", method: "GET", path: "/groups", status: 401, code: "unauthorized"},
+		{name: "join group", route: "/groups/join", method: "POST", path: "/groups/join", user: admin, status: 200,
+			body: `{"invite_code":"weeklyprep"}`,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var group database.Group
+				decode(t, rec, &group)
+				if group.Name != "Weekly prep" || group.Role != database.RoleMember || group.MemberCount != 3 {
+					t.Errorf("unexpected group %+v", group)
+				}
+			}},
+		{name: "join group unknown code", route: "/groups/join", method: "POST", path: "/groups/join", user: admin, status: 404, code: "not_found",
+			body: `{"invite_code":"NOPE"}`},
+		{name: "join group without code", route: "/groups/join", method: "POST", path: "/groups/join", user: admin, status: 422, code: "validation_failed",
+			body: `{}`},
+		{name: "get group as owner", route: "/groups/{id}", method: "GET", path: "/groups/{group}", user: bob, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var group struct {
+					InviteCode string                 `json:"invite_code"`
+					Members    []database.GroupMember `json:"members"`
+				}
+				decode(t, rec, &group)
+				if group.InviteCode != groupInviteCode {
+					t.Errorf("expected the owner to see the invite code, got %q", group.InviteCode)
+				}
+				if len(group.Members) != 2 || group.Members[0].UserID != bob || group.Members[1].UserID != alice {
+					t.Errorf("expected the owner first, got %+v", group.Members)
+				}
+			}},
+		{name: "get group as non-member", route: "/groups/{id}", method: "GET", path: "/groups/{group}", user: admin, status: 404, code: "not_found"},
+		{name: "get group bad id", route: "/groups/{id}", method: "GET", path: "/groups/abc", user: alice, status: 400, code: "bad_request"},
+		{name: "delete group", route: "/groups/{id}", method: "DELETE", path: "/groups/{group}", user: bob, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				lists, err := f.db.GetUserLists(context.Background(), alice)
+				must(t, err)
+				if len(lists) != 1 {
+					t.Errorf("expected the group list to be deleted, got %+v", lists)
+				}
+			}},
+		{name: "delete group as member", route: "/groups/{id}", method: "DELETE", path: "/groups/{group}", user: alice, status: 403, code: "forbidden"},
+		{name: "rotate invite code", route: "/groups/{id}/invite-code", method: "POST", path: "/groups/{group}/invite-code", user: bob, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var resp map[string]string
+				decode(t, rec, &resp)
+				if resp["invite_code"] == groupInviteCode || !strings.HasSuffix(resp["invite_link"], "/"+resp["invite_code"]) {
+					t.Errorf("expected a new code and link, got %+v", resp)
+				}
+				_, err := f.db.JoinGroup(context.Background(), groupInviteCode, admin)
+				if !errors.Is(err, database.ErrNotFound) {
+					t.Errorf("expected the old code to stop working, got %v", err)
+				}
+			}},
+		{name: "rotate invite code as member", route: "/groups/{id}/invite-code", method: "POST", path: "/groups/{group}/invite-code", user: alice, status: 403, code: "forbidden"},
+		{name: "leave group", route: "/groups/{id}/leave", method: "POST", path: "/groups/{group}/leave", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				groups, err := f.db.GetUserGroups(context.Background(), alice)
+				must(t, err)
+				if len(groups) != 0 {
+					t.Errorf("expected alice to have left, got %+v", groups)
+				}
+			}},
+		{name: "leave group as owner", route: "/groups/{id}/leave", method: "POST", path: "/groups/{group}/leave", user: bob, status: 403, code: "forbidden"},
+		{name: "promote member", route: "/groups/{id}/members/{userID}/role", method: "PUT", path: "/groups/{group}/members/" + alice + "/role", user: bob, status: 200,
+			body: `{"role":"Admin"}`,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				group, err := f.db.GetUserGroups(context.Background(), alice)
+				must(t, err)
+				if group[0].Role != database.RoleAdmin {
+					t.Errorf("expected alice to be an admin, got %+v", group[0])
+				}
+			}},
+		{name: "promote member to owner", route: "/groups/{id}/members/{userID}/role", method: "PUT", path: "/groups/{group}/members/" + alice + "/role", user: bob, status: 422, code: "validation_failed",
+			body: `{"role":"owner"}`},
+		{name: "change role as member", route: "/groups/{id}/members/{userID}/role", method: "PUT", path: "/groups/{group}/members/" + bob + "/role", user: alice, status: 403, code: "forbidden",
+			body: `{"role":"member"}`},
+		{name: "change role of non-member", route: "/groups/{id}/members/{userID}/role", method: "PUT", path: "/groups/{group}/members/" + admin + "/role", user: bob, status: 404, code: "not_found",
+			body: `{"role":"admin"}`},
+		{name: "remove member", route: "/groups/{id}/members/{userID}", method: "DELETE", path: "/groups/{group}/members/" + alice, user: bob, status: 200},
+		{name: "remove owner as admin", route: "/groups/{id}/members/{userID}", method: "DELETE", path: "/groups/{group}/members/" + bob, user: alice, status: 403, code: "forbidden",
+			setup: func(f *fixture) {
+				group, err := f.db.GetUserGroups(context.Background(), bob)
+				if err == nil {
+					err = f.db.SetGroupMemberRole(context.Background(), group[0].ID, alice, database.RoleAdmin)
+				}
+				if err != nil {
+					panic(err)
+				}
+			}},
+		{name: "remove non-member", route: "/groups/{id}/members/{userID}", method: "DELETE", path: "/groups/{group}/members/" + admin, user: bob, status: 404, code: "not_found"},
+		{name: "group lists", route: "/groups/{id}/lists", method: "GET", path: "/groups/{group}/lists", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var lists []database.List
+				decode(t, rec, &lists)
+				if len(lists) != 1 || lists[0].Name != "Weekly prep list" {
+					t.Errorf("unexpected group lists %+v", lists)
+				}
+			}},
+		{name: "group lists as non-member", route: "/groups/{id}/lists", method: "GET", path: "/groups/{group}/lists", user: admin, status: 404, code: "not_found"},
+		{name: "create group list", route: "/groups/{id}/lists", method: "POST", path: "/groups/{group}/lists", user: bob, status: 200,
+			body: `{"name":"Trees","difficulty":"easy"}`,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var resp map[string]int
+				decode(t, rec, &resp)
+				list, err := f.db.GetListByID(context.Background(), resp["list_id"], alice)
+				must(t, err)
+				if list.GroupID == nil || list.Name != "Trees" {
+					t.Errorf("expected a group list visible to members, got %+v", list)
+				}
+			}},
+		{name: "create group list as member", route: "/groups/{id}/lists", method: "POST", path: "/groups/{group}/lists", user: alice, status: 403, code: "forbidden",
+			body: `{"name":"Trees","difficulty":"easy"}`},
+		{name: "group progress", route: "/groups/{id}/progress", method: "GET", path: "/groups/{group}/progress", user: bob, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var resp struct {
+					Dates   []time.Time `json:"dates"`
+					Members []struct {
+						Username string           `json:"username"`
+						Summary  progress.Summary `json:"summary"`
+					} `json:"members"`
+					Hidden int `json:"hidden"`
+				}
+				decode(t, rec, &resp)
+				// alice's progress is private, so bob only sees his own.
+				if len(resp.Dates) != 1 || len(resp.Members) != 1 || resp.Members[0].Username != "bob" || resp.Hidden != 1 {
+					t.Errorf("unexpected group progress %+v", resp)
+				}
+			}},
+		{name: "group progress includes my private progress", route: "/groups/{id}/progress", method: "GET", path: "/groups/{group}/progress?granularity=week", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var resp struct {
+					Members []struct {
+						Username string `json:"username"`
+					} `json:"members"`
+					Hidden int `json:"hidden"`
+				}
+				decode(t, rec, &resp)
+				if len(resp.Members) != 2 || resp.Hidden != 0 {
+					t.Errorf("unexpected group progress %+v", resp)
+				}
+			}},
+		{name: "group progress bad range", route: "/groups/{id}/progress", method: "GET", path: "/groups/{group}/progress?from=soon", user: alice, status: 422, code: "validation_failed"},
+		{name: "group progress as non-member", route: "/groups/{id}/progress", method: "GET", path: "/groups/{group}/progress", user: admin, status: 404, code: "not_found"},
+		{name: "add problems to group list as member", route: "/lists/add-problem", method: "POST", path: "/lists/add-problem", user: alice, status: 403, code: "forbidden",
+			body: `{"list_id":{groupList},"problem_ids":[3]}`},
+		{name: "add problems to group list as owner", route: "/lists/add-problem", method: "POST", path: "/lists/add-problem", user: bob, status: 200,
+			body: `{"list_id":{groupList},"problem_ids":[3]}`},
+		{name: "remove problem from group list as member", route: "/lists/remove-problem", method: "POST", path: "/lists/remove-problem", user: alice, status: 403, code: "forbidden",
+			body: `{"list_id":{groupList},"problem_id":2}`},
+		{name: "delete group list as member", route: "/lists/{id}", method: "DELETE", path: "/lists/{groupList}", user: alice, status: 404, code: "not_found"},
+		{name: "complete group item as member", route: "/list-items/{id}/completion", method: "PUT", path: "/list-items/{groupItem}/completion", user: alice, status: 200,
+			body: `{"completed":true}`,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				group, err := f.db.GetGroupLists(context.Background(), groupID(t, f))
+				must(t, err)
+				items, err := f.db.GetListItems(context.Background(), group[0].ID, bob)
+				must(t, err)
+				if items[0].Completed || items[0].DoneCount != 1 || items[0].MemberCount != 2 {
+					t.Errorf("expected alice's tick to leave bob's state alone, got %+v", items[0])
+				}
+			}},
+
+		{name: "list members", route: "/lists/{id}/members", method: "GET", path: "/lists/{aliceList}/members", user: bob, status: 200,
+			setup: shareAliceList(bob, database.ListViewer),
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var members []database.ListMember
+				decode(t, rec, &members)
+				if len(members) != 2 || members[0].UserID != alice || members[0].Role != "owner" || members[1].Role != "viewer" {
+					t.Errorf("unexpected members %+v", members)
+				}
+			}},
+		{name: "list members of another user's list", route: "/lists/{id}/members", method: "GET", path: "/lists/{aliceList}/members", user: bob, status: 404, code: "not_found"},
+		{name: "list members of a group list", route: "/lists/{id}/members", method: "GET", path: "/lists/{groupList}/members", user: alice, status: 400, code: "bad_request"},
+		{name: "share list", route: "/lists/{id}/members/{userID}", method: "PUT", path: "/lists/{aliceList}/members/" + bob, user: alice, status: 200,
+			body: `{"role":"Editor"}`,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				lists, err := f.db.GetUserLists(context.Background(), bob)
+				must(t, err)
+				// bob's own list, then the shared one, then the group's.
+				if len(lists) != 3 || lists[1].UserID != alice {
+					t.Errorf("expected alice's list to be shared with bob, got %+v", lists)
+				}
+			}},
+		{name: "share list with unknown user", route: "/lists/{id}/members/{userID}", method: "PUT", path: "/lists/{aliceList}/members/auth0|nobody", user: alice, status: 404, code: "not_found",
+			body: `{"role":"viewer"}`},
+		{name: "share list with its owner", route: "/lists/{id}/members/{userID}", method: "PUT", path: "/lists/{aliceList}/members/" + alice, user: alice, status: 403, code: "forbidden",
+			body: `{"role":"viewer"}`},
+		{name: "share list with bad role", route: "/lists/{id}/members/{userID}", method: "PUT", path: "/lists/{aliceList}/members/" + bob, user: alice, status: 422, code: "validation_failed",
+			body: `{"role":"owner"}`},
+		{name: "share list as editor", route: "/lists/{id}/members/{userID}", method: "PUT", path: "/lists/{aliceList}/members/" + admin, user: bob, status: 403, code: "forbidden",
+			body: `{"role":"viewer"}`, setup: shareAliceList(bob, database.ListEditor)},
+		{name: "remove list member", route: "/lists/{id}/members/{userID}", method: "DELETE", path: "/lists/{aliceList}/members/" + bob, user: alice, status: 200,
+			setup: shareAliceList(bob, database.ListViewer)},
+		{name: "leave shared list", route: "/lists/{id}/members/{userID}", method: "DELETE", path: "/lists/{aliceList}/members/" + bob, user: bob, status: 200,
+			setup: shareAliceList(bob, database.ListViewer),
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				lists, err := f.db.GetUserLists(context.Background(), bob)
+				must(t, err)
+				if len(lists) != 2 {
+					t.Errorf("expected bob to have left alice's list, got %+v", lists)
+				}
+			}},
+		{name: "remove list owner", route: "/lists/{id}/members/{userID}", method: "DELETE", path: "/lists/{aliceList}/members/" + alice, user: bob, status: 403, code: "forbidden",
+			setup: shareAliceList(bob, database.ListEditor)},
+		{name: "remove missing list member", route: "/lists/{id}/members/{userID}", method: "DELETE", path: "/lists/{aliceList}/members/" + bob, user: alice, status: 404, code: "not_found"},
+		{name: "add problems as editor", route: "/lists/add-problem", method: "POST", path: "/lists/add-problem", user: bob, status: 200,
+			body: `{"list_id":{aliceList},"problem_ids":[2]}`, setup: shareAliceList(bob, database.ListEditor)},
+		{name: "add problems as viewer", route: "/lists/add-problem", method: "POST", path: "/lists/add-problem", user: bob, status: 403, code: "forbidden",
+			body: `{"list_id":{aliceList},"problem_ids":[2]}`, setup: shareAliceList(bob, database.ListViewer)},
+		{name: "complete shared item as viewer", route: "/list-items/{id}/completion", method: "PUT", path: "/list-items/{aliceItem}/completion", user: bob, status: 200,
+			body: `{"completed":true}`, setup: shareAliceList(bob, database.ListViewer),
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				if item := aliceItem(t, f); item.Completed || item.DoneCount != 1 || item.MemberCount != 2 {
+					t.Errorf("expected 1/2 members done and alice's item still open, got %+v", item)
+				}
+			}},
+		{name: "progress history team outside the group", route: "/user-progress-history", method: "GET", path: "/user-progress-history?username=bob", user: admin, status: 403, code: "forbidden"},
+
+		{name: "create leaderboard", route: "/leaderboards", method: "POST", path: "/leaderboards", user: alice, status: 200,
+			body: `{"name":"Rivals","usernames":["bob","neetcode"],"weights":{"easy":1,"medium":2,"hard":4},"tie_break":"total"}`,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var board database.Leaderboard
+				decode(t, rec, &board)
+				stored, err := f.db.GetLeaderboard(context.Background(), board.ID, alice)
+				must(t, err)
+				// Unlinked usernames take LeetCode's capitalisation.
+				if stored.Name != "Rivals" || stored.Weights.Hard != 4 || stored.TieBreak != "total" || strings.Join(stored.Participants, ",") != "NeetCode,bob" {
+					t.Errorf("unexpected leaderboard %+v", stored)
+				}
+			}},
+		{name: "create leaderboard with defaults", route: "/leaderboards", method: "POST", path: "/leaderboards", user: bob, status: 200,
+			body: `{"name":"Just me"}`,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var board database.Leaderboard
+				decode(t, rec, &board)
+				if board.Weights != (database.Weights{Easy: 1, Medium: 1, Hard: 1}) || board.TieBreak != "hardest" || len(board.Participants) != 0 {
+					t.Errorf("unexpected leaderboard %+v", board)
+				}
+			}},
+		{name: "create leaderboard invalid", route: "/leaderboards", method: "POST", path: "/leaderboards", user: alice, status: 422, code: "validation_failed",
+			body: `{"name":"Rivals","usernames":["bob","BOB"],"weights":{"easy":0,"medium":0,"hard":-1},"tie_break":"coin"}`},
+		{name: "create leaderboard with private account", route: "/leaderboards", method: "POST", path: "/leaderboards", user: bob, status: 403, code: "forbidden",
+			body: `{"name":"Rivals","usernames":["alice"]}`},
+		{name: "create leaderboard with unknown username", route: "/leaderboards", method: "POST", path: "/leaderboards", user: alice, status: 404, code: "not_found",
+			body: `{"name":"Rivals","usernames":["nobody"]}`},
+		{name: "list leaderboards", route: "/leaderboards", method: "GET", path: "/leaderboards", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var boards []database.Leaderboard
+				decode(t, rec, &boards)
+				if len(boards) != 1 || boards[0].Name != "Friends" {
+					t.Errorf("unexpected leaderboards %+v", boards)
+				}
+			}},
+		{name: "get leaderboard", route: "/leaderboards/{id}", method: "GET", path: "/leaderboards/{leaderboard}", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var resp struct {
+					Name      string              `json:"name"`
+					Window    string              `json:"window"`
+					From      string              `json:"from"`
+					To        string              `json:"to"`
+					Standings []database.Standing `json:"standings"`
+					Hidden    int                 `json:"hidden"`
+				}
+				decode(t, rec, &resp)
+				today := time.Now().UTC()
+				if resp.Window != "week" || resp.To != today.Format("2006-01-02") || resp.From > resp.To {
+					t.Errorf("unexpected window %s from %s to %s", resp.Window, resp.From, resp.To)
+				}
+				// Each user has a single snapshot, so nobody has gained
+				// anything yet and both share first place.
+				if len(resp.Standings) != 2 || resp.Hidden != 0 || resp.Standings[1].Rank != 1 || resp.Standings[1].TotalSolved != 3 {
+					t.Errorf("unexpected standings %+v", resp)
+				}
+			}},
+		{name: "get leaderboard hides private participants", route: "/leaderboards/{id}", method: "GET", path: "/leaderboards/{leaderboard}?window=custom&from=2024-01-01&to=2024-01-31", user: alice, status: 200,
+			setup: func(f *fixture) {
+				username := "bob"
+				if err := f.db.UpdateAccount(context.Background(), &database.Account{UserID: bob, LeetCodeUsername: &username, ProgressVisibility: database.VisibilityPrivate}); err != nil {
+					panic(err)
+				}
+			},
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var resp struct {
+					From      string              `json:"from"`
+					Standings []database.Standing `json:"standings"`
+					Hidden    int                 `json:"hidden"`
+				}
+				decode(t, rec, &resp)
+				if resp.From != "2024-01-01" || len(resp.Standings) != 1 || resp.Standings[0].Username != "alice" || resp.Hidden != 1 {
+					t.Errorf("unexpected standings %+v", resp)
+				}
+			}},
+		{name: "get leaderboard after a participant hides their progress", route: "/leaderboards/{id}", method: "GET", path: "/leaderboards/{leaderboard}", user: alice, status: 200,
+			setup: func(f *fixture) {
+				// Rank once so the standings are cached, then hide bob's
+				// progress through the API.
+				path := f.ids.Replace("/leaderboards/{leaderboard}")
+				if rec := serve(f, "GET", path, alice, ""); rec.Code != http.StatusOK {
+					panic(rec.Body.String())
+				}
+				if rec := serve(f, "PUT", "/me/account", bob, `{"leetcode_username":"bob","progress_visibility":"private"}`); rec.Code != http.StatusOK {
+					panic(rec.Body.String())
+				}
+			},
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var resp struct {
+					Standings []database.Standing `json:"standings"`
+					Hidden    int                 `json:"hidden"`
+				}
+				decode(t, rec, &resp)
+				if resp.Hidden != 1 || len(resp.Standings) != 1 || resp.Standings[0].Username != "alice" {
+					t.Errorf("expected bob to be hidden at once, got %+v", resp)
+				}
+			}},
+		{name: "get leaderboard custom window without dates", route: "/leaderboards/{id}", method: "GET", path: "/leaderboards/{leaderboard}?window=custom&from=2024-01-01", user: alice, status: 422, code: "validation_failed"},
+		{name: "get leaderboard dates outside custom window", route: "/leaderboards/{id}", method: "GET", path: "/leaderboards/{leaderboard}?window=month&to=2024-01-31", user: alice, status: 422, code: "validation_failed"},
+		{name: "get someone else's leaderboard", route: "/leaderboards/{id}", method: "GET", path: "/leaderboards/{leaderboard}", user: bob, status: 404, code: "not_found"},
+		{name: "get leaderboard invalid id", route: "/leaderboards/{id}", method: "GET", path: "/leaderboards/abc", user: alice, status: 400, code: "bad_request"},
+		{name: "update leaderboard", route: "/leaderboards/{id}", method: "PUT", path: "/leaderboards/{leaderboard}", user: alice, status: 200,
+			body: `{"name":"Close friends","weights":{"easy":0,"medium":1,"hard":3},"tie_break":"shared"}`,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var board database.Leaderboard
+				decode(t, rec, &board)
+				if board.Name != "Close friends" || board.Weights.Hard != 3 || board.TieBreak != "shared" || len(board.Participants) != 2 {
+					t.Errorf("unexpected leaderboard %+v", board)
+				}
+			}},
+		{name: "update leaderboard invalid", route: "/leaderboards/{id}", method: "PUT", path: "/leaderboards/{leaderboard}", user: alice, status: 422, code: "validation_failed",
+			body: `{"name":"","weights":{"easy":101,"medium":1,"hard":1}}`},
+		{name: "update someone else's leaderboard", route: "/leaderboards/{id}", method: "PUT", path: "/leaderboards/{leaderboard}", user: bob, status: 404, code: "not_found",
+			body: `{"name":"Mine now"}`},
+		{name: "delete leaderboard", route: "/leaderboards/{id}", method: "DELETE", path: "/leaderboards/{leaderboard}", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				boards, err := f.db.GetUserLeaderboards(context.Background(), alice)
+				must(t, err)
+				if len(boards) != 0 {
+					t.Errorf("expected no leaderboards, got %+v", boards)
+				}
+			}},
+		{name: "delete someone else's leaderboard", route: "/leaderboards/{id}", method: "DELETE", path: "/leaderboards/{leaderboard}", user: bob, status: 404, code: "not_found"},
+		{name: "add leaderboard participants", route: "/leaderboards/{id}/participants", method: "POST", path: "/leaderboards/{leaderboard}/participants", user: alice, status: 200,
+			body: `{"usernames":["NEETCODE","bob"]}`,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var board database.Leaderboard
+				decode(t, rec, &board)
+				if strings.Join(board.Participants, ",") != "NeetCode,alice,bob" {
+					t.Errorf("unexpected participants %v", board.Participants)
+				}
+				tracked, err := f.db.GetTrackedLeetCodeUsernames(context.Background())
+				must(t, err)
+				if strings.Join(tracked, ",") != "NeetCode,alice,bob" {
+					t.Errorf("expected the poller to track NeetCode, got %v", tracked)
+				}
+			}},
+		{name: "add leaderboard participants past the limit", route: "/leaderboards/{id}/participants", method: "POST", path: "/leaderboards/{leaderboard}/participants", user: alice, status: 422, code: "validation_failed",
+			body: `{"usernames":["neetcode"]}`,
+			setup: func(f *fixture) {
+				var usernames []string
+				for i := 0; i < 48; i++ {
+					usernames = append(usernames, fmt.Sprintf("user%d", i))
+				}
+				boards, err := f.db.GetUserLeaderboards(context.Background(), alice)
+				if err == nil {
+					err = f.db.AddLeaderboardParticipants(context.Background(), boards[0].ID, usernames)
+				}
+				if err != nil {
+					panic(err)
+				}
+			}},
+		{name: "add hidden leaderboard participant", route: "/leaderboards/{id}/participants", method: "POST", path: "/leaderboards/{leaderboard}/participants", user: alice, status: 403, code: "forbidden",
+			body: `{"usernames":["carol"]}`,
+			setup: func(f *fixture) {
+				username := "carol"
+				err := f.db.EnsureUserExists(context.Background(), admin)
+				if err == nil {
+					err = f.db.UpdateAccount(context.Background(), &database.Account{UserID: admin, LeetCodeUsername: &username, ProgressVisibility: database.VisibilityTeam})
+				}
+				if err != nil {
+					panic(err)
+				}
+			}},
+		{name: "add leaderboard participants leetcode down", route: "/leaderboards/{id}/participants", method: "POST", path: "/leaderboards/{leaderboard}/participants", user: alice, status: 502,
+			body: `{"usernames":["broken"]}`},
+		{name: "add no leaderboard participants", route: "/leaderboards/{id}/participants", method: "POST", path: "/leaderboards/{leaderboard}/participants", user: alice, status: 422, code: "validation_failed",
+			body: `{"usernames":[]}`},
+		{name: "remove leaderboard participant", route: "/leaderboards/{id}/participants/{username}", method: "DELETE", path: "/leaderboards/{leaderboard}/participants/bob", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var board database.Leaderboard
+				decode(t, rec, &board)
+				if strings.Join(board.Participants, ",") != "alice" {
+					t.Errorf("unexpected participants %v", board.Participants)
+				}
+			}},
+		{name: "remove missing leaderboard participant", route: "/leaderboards/{id}/participants/{username}", method: "DELETE", path: "/leaderboards/{leaderboard}/participants/carol", user: alice, status: 404, code: "not_found"},
+		{name: "create study plan", route: "/plans", method: "POST", path: "/plans", user: alice, status: 200,
+			body: `{"list_id":{groupList},"start_date":"2030-01-05","days_per_week":3,"problems_per_day":2}`,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var plan studyPlanResponse
+				decode(t, rec, &plan)
+				if plan.ListName != "Weekly prep list" || plan.DaysPerWeek != 3 || plan.ProblemsPerDay != 2 || plan.Status.Total != 1 {
+					t.Errorf("unexpected plan %+v", plan)
+				}
+				// The 5th is a Saturday; the first study day is Monday the 7th.
+				if len(plan.Schedule) != 1 || plan.Schedule[0].ProblemID != 2 || plan.Schedule[0].DueDate.Format("2006-01-02") != "2030-01-07" {
+					t.Errorf("unexpected schedule %+v", plan.Schedule)
+				}
+				plans, err := f.db.GetUserStudyPlans(context.Background(), alice)
+				must(t, err)
+				if len(plans) != 2 {
+					t.Errorf("expected the plan to be stored, got %+v", plans)
+				}
+			}},
+		{name: "create study plan with defaults", route: "/plans", method: "POST", path: "/plans", user: bob, status: 200,
+			body: `{"list_id":{bobList}}`,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var plan studyPlanResponse
+				decode(t, rec, &plan)
+				today := studyplan.Day(time.Now().UTC())
+				if plan.DaysPerWeek != 5 || plan.ProblemsPerDay != 1 || !plan.StartDate.Equal(today) || len(plan.Schedule) != 1 {
+					t.Errorf("unexpected plan %+v", plan)
+				}
+			}},
+		{name: "create study plan invalid", route: "/plans", method: "POST", path: "/plans", user: alice, status: 422, code: "validation_failed",
+			body: `{"list_id":{aliceList},"start_date":"tomorrow","days_per_week":8,"problems_per_day":21}`},
+		{name: "create study plan on someone else's list", route: "/plans", method: "POST", path: "/plans", user: alice, status: 404, code: "not_found",
+			body: `{"list_id":{bobList}}`},
+		{name: "create study plan on finished list", route: "/plans", method: "POST", path: "/plans", user: alice, status: 422, code: "validation_failed",
+			body: `{"list_id":{aliceList}}`,
+			setup: func(f *fixture) {
+				lists, err := f.db.GetUserLists(context.Background(), alice)
+				if err != nil {
+					panic(err)
+				}
+				items, err := f.db.GetListItems(context.Background(), lists[0].ID, alice)
+				if err == nil {
+					err = f.db.UpdateProblemCompletionStatus(context.Background(), items[0].ID, alice, true)
+				}
+				if err != nil {
+					panic(err)
+				}
+			}},
+		{name: "list study plans", route: "/plans", method: "GET", path: "/plans", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var plans []database.StudyPlan
+				decode(t, rec, &plans)
+				if len(plans) != 1 || plans[0].ListName != alice+"'s list" {
+					t.Errorf("unexpected plans %+v", plans)
+				}
+			}},
+		{name: "list study plans for new user", route: "/plans", method: "GET", path: "/plans", user: admin, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				if body := strings.TrimSpace(rec.Body.String()); body != "[]" {
+					t.Errorf("expected an empty array, got %s", body)
+				}
+			}},
+		{name: "today's problems", route: "/plans/today", method: "GET", path: "/plans/today", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var today struct {
+					Date  string `json:"date"`
+					Plans []struct {
+						database.StudyPlan
+						Due     []database.Assignment `json:"due"`
+						Overdue []database.Assignment `json:"overdue"`
+					} `json:"plans"`
+				}
+				decode(t, rec, &today)
+				if today.Date != time.Now().UTC().Format("2006-01-02") || len(today.Plans) != 1 {
+					t.Fatalf("unexpected response %+v", today)
+				}
+				if plan := today.Plans[0]; len(plan.Due) != 1 || plan.Due[0].ProblemTitle != "Two Sum" || len(plan.Overdue) != 0 {
+					t.Errorf("expected Two Sum due today, got %+v", plan)
+				}
+			}},
+		{name: "today's problems overdue", route: "/plans/today", method: "GET", path: "/plans/today", user: alice, status: 200,
+			setup: movePlanItem(-2),
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var today struct {
+					Plans []struct {
+						Due     []database.Assignment `json:"due"`
+						Overdue []database.Assignment `json:"overdue"`
+					} `json:"plans"`
+				}
+				decode(t, rec, &today)
+				if len(today.Plans) != 1 || len(today.Plans[0].Due) != 0 || len(today.Plans[0].Overdue) != 1 {
+					t.Errorf("expected Two Sum to be overdue, got %+v", today)
+				}
+			}},
+		{name: "today's problems without plans", route: "/plans/today", method: "GET", path: "/plans/today", user: bob, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var today struct {
+					Plans []json.RawMessage `json:"plans"`
+				}
+				decode(t, rec, &today)
+				if today.Plans == nil || len(today.Plans) != 0 {
+					t.Errorf("expected an empty plans array, got %s", rec.Body.String())
+				}
+			}},
+		{name: "get study plan", route: "/plans/{id}", method: "GET", path: "/plans/{plan}", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var plan studyPlanResponse
+				decode(t, rec, &plan)
+				if plan.Status.Total != 1 || plan.Status.DueToday != 1 || plan.Status.SlipDays != 0 || plan.Status.ProjectedEnd == nil {
+					t.Errorf("expected a plan on schedule, got %+v", plan.Status)
+				}
+			}},
+		{name: "get study plan behind schedule", route: "/plans/{id}", method: "GET", path: "/plans/{plan}", user: alice, status: 200,
+			setup: movePlanItem(-3),
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var plan studyPlanResponse
+				decode(t, rec, &plan)
+				if plan.Status.Overdue != 1 || plan.Status.MissedDays != 1 || plan.Status.SlipDays != 3 {
+					t.Errorf("expected the plan to have slipped three days, got %+v", plan.Status)
+				}
+			}},
+		{name: "get someone else's study plan", route: "/plans/{id}", method: "GET", path: "/plans/{plan}", user: bob, status: 404, code: "not_found"},
+		{name: "get study plan invalid id", route: "/plans/{id}", method: "GET", path: "/plans/abc", user: alice, status: 400, code: "bad_request"},
+		{name: "rebalance study plan", route: "/plans/{id}/rebalance", method: "POST", path: "/plans/{plan}/rebalance", user: alice, status: 200,
+			setup: func(f *fixture) {
+				movePlanItem(-3)(f)
+				lists, err := f.db.GetUserLists(context.Background(), alice)
+				if err == nil {
+					err = f.db.AddProblemsToList(context.Background(), lists[0].ID, []int{3})
+				}
+				if err != nil {
+					panic(err)
+				}
+			},
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var plan studyPlanResponse
+				decode(t, rec, &plan)
+				today := studyplan.Day(time.Now().UTC())
+				if len(plan.Schedule) != 2 || plan.Schedule[0].ProblemID != 1 || !plan.Schedule[0].DueDate.Equal(today) ||
+					plan.Schedule[1].ProblemID != 3 || !plan.Schedule[1].DueDate.Equal(today.AddDate(0, 0, 1)) {
+					t.Errorf("expected the missed problem today and the new one tomorrow, got %+v", plan.Schedule)
+				}
+				if plan.RebalancedAt == nil || plan.Status.Overdue != 0 || plan.Status.SlipDays != 0 {
+					t.Errorf("expected a rebalanced plan on schedule, got %+v", plan)
+				}
+			}},
+		{name: "rebalance someone else's study plan", route: "/plans/{id}/rebalance", method: "POST", path: "/plans/{plan}/rebalance", user: bob, status: 404, code: "not_found"},
+		{name: "delete study plan", route: "/plans/{id}", method: "DELETE", path: "/plans/{plan}", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				plans, err := f.db.GetUserStudyPlans(context.Background(), alice)
+				must(t, err)
+				if len(plans) != 0 {
+					t.Errorf("expected no study plans, got %+v", plans)
+				}
+			}},
+		{name: "delete someone else's study plan", route: "/plans/{id}", method: "DELETE", path: "/plans/{plan}", user: bob, status: 404, code: "not_found"},
+		{name: "start interview from list", route: "/interviews", method: "POST", path: "/interviews", user: bob, status: 200,
+			body: `{"list_id":{bobList},"count":1,"time_limit_minutes":30}`,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var session interviewResponse
+				decode(t, rec, &session)
+				if session.Status != "active" || session.RemainingSeconds != 30*60 || session.ListID == nil || len(session.Problems) != 1 || session.Problems[0].ProblemID != 1 {
+					t.Errorf("expected a running session on problem 1, got %+v", session)
+				}
+			}},
+		{name: "start interview from catalog filters", route: "/interviews", method: "POST", path: "/interviews", user: bob, status: 200,
+			body: `{"difficulties":["Medium"],"topics":["hash-table"],"count":1}`,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var session interviewResponse
+				decode(t, rec, &session)
+				if session.TimeLimitMinutes != 45 || len(session.Problems) != 1 || session.Problems[0].ProblemID != 3 || session.Score.Unanswered != 1 {
+					t.Errorf("expected a 45 minute session on problem 3, got %+v", session)
+				}
+			}},
+		{name: "start interview excluding solved", route: "/interviews", method: "POST", path: "/interviews", user: bob, status: 422, code: "validation_failed",
+			body: `{"difficulties":["easy"],"exclude_solved":true,"count":1}`,
+			setup: func(f *fixture) {
+				lists, err := f.db.GetUserLists(context.Background(), bob)
+				if err != nil {
+					panic(err)
+				}
+				items, err := f.db.GetListItems(context.Background(), lists[0].ID, bob)
+				if err == nil {
+					err = f.db.UpdateProblemCompletionStatus(context.Background(), items[0].ID, bob, true)
+				}
+				if err != nil {
+					panic(err)
+				}
+			}},
+		{name: "start interview with too few matches", route: "/interviews", method: "POST", path: "/interviews", user: bob, status: 422, code: "validation_failed",
+			body: `{"list_id":{bobList},"count":2}`},
+		{name: "start interview invalid", route: "/interviews", method: "POST", path: "/interviews", user: bob, status: 422, code: "validation_failed",
+			body: `{"difficulties":["trivial"],"count":11,"time_limit_minutes":1}`},
+		{name: "start interview on someone else's list", route: "/interviews", method: "POST", path: "/interviews", user: bob, status: 404, code: "not_found",
+			body: `{"list_id":{aliceList}}`},
+		{name: "start interview while one is running", route: "/interviews", method: "POST", path: "/interviews", user: alice, status: 409, code: "conflict",
+			body: `{"count":1}`},
+		{name: "start interview after the last one ended", route: "/interviews", method: "POST", path: "/interviews", user: alice, status: 200,
+			body: `{"count":3}`, setup: endAliceInterview},
+		{name: "list interviews", route: "/interviews", method: "GET", path: "/interviews", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var sessions []interviewResponse
+				decode(t, rec, &sessions)
+				if len(sessions) != 1 || sessions[0].Status != "active" || len(sessions[0].Problems) != 2 {
+					t.Errorf("expected alice's running session, got %+v", sessions)
+				}
+			}},
+		{name: "list interviews expires sessions that ran out", route: "/interviews", method: "GET", path: "/interviews", user: bob, status: 200,
+			setup: func(f *fixture) {
+				started := time.Now().UTC().Add(-2 * time.Hour)
+				err := f.db.CreateInterviewSession(context.Background(), &database.InterviewSession{UserID: bob, TimeLimitMinutes: 30,
+					StartedAt: started, ExpiresAt: started.Add(30 * time.Minute), Problems: []database.InterviewProblem{{ProblemID: 1}}})
+				if err != nil {
+					panic(err)
+				}
+			},
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var sessions []interviewResponse
+				decode(t, rec, &sessions)
+				if len(sessions) != 1 || sessions[0].Status != "expired" || sessions[0].EndedAt == nil || !sessions[0].EndedAt.Equal(sessions[0].ExpiresAt) {
+					t.Fatalf("expected an expired session, got %+v", sessions)
+				}
+				stored, err := f.db.GetInterviewSession(context.Background(), sessions[0].ID, bob)
+				must(t, err)
+				if stored.EndedAt == nil {
+					t.Errorf("expected the session to be ended in the database")
+				}
+			}},
+		{name: "list interviews for new user", route: "/interviews", method: "GET", path: "/interviews", user: admin, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				if body := strings.TrimSpace(rec.Body.String()); body != "[]" {
+					t.Errorf("expected an empty array, got %s", body)
+				}
+			}},
+		{name: "get interview", route: "/interviews/{id}", method: "GET", path: "/interviews/{interview}", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var session interviewResponse
+				decode(t, rec, &session)
+				if session.Status != "active" || session.RemainingSeconds <= 0 || session.Problems[0].ProblemTitle != "Add Two Numbers" || session.Score.Unanswered != 2 {
+					t.Errorf("unexpected session %+v", session)
+				}
+			}},
+		{name: "get someone else's interview", route: "/interviews/{id}", method: "GET", path: "/interviews/{interview}", user: bob, status: 404, code: "not_found"},
+		{name: "get interview invalid id", route: "/interviews/{id}", method: "GET", path: "/interviews/abc", user: alice, status: 400, code: "bad_request"},
+		{name: "record interview outcome", route: "/interviews/{id}/problems/{problemID}", method: "PUT", path: "/interviews/{interview}/problems/2", user: alice, status: 200,
+			body: `{"outcome":"Solved","elapsed_seconds":600}`,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var session interviewResponse
+				decode(t, rec, &session)
+				if p := session.Problems[0]; p.Outcome == nil || *p.Outcome != "solved" || *p.ElapsedSeconds != 600 || session.Score.Solved != 1 || session.Score.Percent != 50 {
+					t.Errorf("expected problem 2 solved and a score of 50%%, got %+v", session)
+				}
+			}},
+		{name: "record outcome for problem not in interview", route: "/interviews/{id}/problems/{problemID}", method: "PUT", path: "/interviews/{interview}/problems/1", user: alice, status: 404, code: "not_found",
+			body: `{"outcome":"solved","elapsed_seconds":60}`},
+		{name: "record interview outcome invalid", route: "/interviews/{id}/problems/{problemID}", method: "PUT", path: "/interviews/{interview}/problems/2", user: alice, status: 422, code: "validation_failed",
+			body: `{"outcome":"aced","elapsed_seconds":2701}`},
+		{name: "record outcome after interview ended", route: "/interviews/{id}/problems/{problemID}", method: "PUT", path: "/interviews/{interview}/problems/2", user: alice, status: 409, code: "conflict",
+			body: `{"outcome":"solved","elapsed_seconds":60}`, setup: endAliceInterview},
+		{name: "record outcome in someone else's interview", route: "/interviews/{id}/problems/{problemID}", method: "PUT", path: "/interviews/{interview}/problems/2", user: bob, status: 404, code: "not_found",
+			body: `{"outcome":"solved","elapsed_seconds":60}`},
+		{name: "end interview", route: "/interviews/{id}/end", method: "POST", path: "/interviews/{interview}/end", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var session interviewResponse
+				decode(t, rec, &session)
+				if session.Status != "completed" || session.EndedAt == nil || session.RemainingSeconds != 0 {
+					t.Errorf("expected a completed session, got %+v", session)
+				}
+			}},
+		{name: "end someone else's interview", route: "/interviews/{id}/end", method: "POST", path: "/interviews/{interview}/end", user: bob, status: 404, code: "not_found"},
+		{name: "problem of the day", route: "/lists/{id}/daily", method: "GET", path: "/lists/{groupList}/daily", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var pick database.DailyProblem
+				decode(t, rec, &pick)
+				// Problem 2 was picked yesterday, leaving problem 3.
+				if pick.ProblemID != 3 || pick.Done || !pick.Date.Equal(studyplan.Day(time.Now().UTC())) {
+					t.Fatalf("expected today's pick to be problem 3, got %+v", pick)
+				}
+				today := studyplan.Day(time.Now().UTC())
+				picks, err := f.db.GetDailyProblems(context.Background(), groupListID(f), bob, today, today)
+				must(t, err)
+				if len(picks) != 1 || picks[0].ProblemID != 3 {
+					t.Errorf("expected the pick to be stored for everyone, got %+v", picks)
+				}
+			}},
+		{name: "problem of the day without matching problems", route: "/lists/{id}/daily", method: "GET", path: "/lists/{groupList}/daily", user: alice, status: 404, code: "not_found",
+			setup: func(f *fixture) {
+				err := f.db.SetDailySettings(context.Background(), &database.DailySettings{ListID: groupListID(f), Source: database.DailySourceCatalog,
+					Difficulties: []string{"hard"}, Topics: []string{}})
+				if err != nil {
+					panic(err)
+				}
+			}},
+		{name: "problem of the day not turned on", route: "/lists/{id}/daily", method: "GET", path: "/lists/{aliceList}/daily", user: alice, status: 404, code: "not_found"},
+		{name: "problem of the day for someone else's list", route: "/lists/{id}/daily", method: "GET", path: "/lists/{groupList}/daily", user: admin, status: 404, code: "not_found"},
+		{name: "problem of the day history", route: "/lists/{id}/daily/history", method: "GET", path: "/lists/{groupList}/daily/history", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var picks []database.DailyProblem
+				decode(t, rec, &picks)
+				if len(picks) != 1 || picks[0].ProblemID != 2 || picks[0].Done {
+					t.Errorf("expected yesterday's pick, got %+v", picks)
+				}
+			}},
+		{name: "problem of the day history for today only", route: "/lists/{id}/daily/history", method: "GET", path: "/lists/{groupList}/daily/history?days=1", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var picks []database.DailyProblem
+				decode(t, rec, &picks)
+				if len(picks) != 0 {
+					t.Errorf("expected no picks yet today, got %+v", picks)
+				}
+			}},
+		{name: "problem of the day history invalid days", route: "/lists/{id}/daily/history", method: "GET", path: "/lists/{groupList}/daily/history?days=0", user: alice, status: 422, code: "validation_failed"},
+		{name: "get problem of the day settings", route: "/lists/{id}/daily/settings", method: "GET", path: "/lists/{groupList}/daily/settings", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var settings database.DailySettings
+				decode(t, rec, &settings)
+				if settings.Source != "catalog" || len(settings.Difficulties) != 1 || settings.Difficulties[0] != "medium" || settings.NoRepeatDays != 30 {
+					t.Errorf("unexpected settings %+v", settings)
+				}
+			}},
+		{name: "get problem of the day settings not turned on", route: "/lists/{id}/daily/settings", method: "GET", path: "/lists/{aliceList}/daily/settings", user: alice, status: 404, code: "not_found"},
+		{name: "turn on problem of the day", route: "/lists/{id}/daily/settings", method: "PUT", path: "/lists/{aliceList}/daily/settings", user: alice, status: 200,
+			body: `{"topics":[" Hash-Table "]}`,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var settings database.DailySettings
+				decode(t, rec, &settings)
+				if settings.Source != "list" || len(settings.Topics) != 1 || settings.Topics[0] != "hash-table" || settings.NoRepeatDays != 30 {
+					t.Errorf("expected list settings with the default no-repeat window, got %+v", settings)
+				}
+			}},
+		{name: "turn on problem of the day invalid", route: "/lists/{id}/daily/settings", method: "PUT", path: "/lists/{aliceList}/daily/settings", user: alice, status: 422, code: "validation_failed",
+			body: `{"source":"random","difficulties":["expert"],"topics":["a,b"],"no_repeat_days":-1}`,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var body struct {
+					Error struct {
+						Details []struct {
+							Field string `json:"field"`
+						} `json:"details"`
+					} `json:"error"`
+				}
+				decode(t, rec, &body)
+				if len(body.Error.Details) != 4 {
+					t.Errorf("expected four field errors, got %+v", body.Error.Details)
+				}
+			}},
+		{name: "group member cannot change problem of the day", route: "/lists/{id}/daily/settings", method: "PUT", path: "/lists/{groupList}/daily/settings", user: alice, status: 403, code: "forbidden",
+			body: `{"source":"list"}`},
+		{name: "turn off problem of the day", route: "/lists/{id}/daily/settings", method: "DELETE", path: "/lists/{groupList}/daily/settings", user: bob, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				if _, err := f.db.GetDailySettings(context.Background(), groupListID(f)); !errors.Is(err, database.ErrNotFound) {
+					t.Errorf("expected the settings to be gone, got %v", err)
+				}
+				picks, err := f.db.GetDailyProblems(context.Background(), groupListID(f), bob, time.Time{}, time.Now())
+				must(t, err)
+				if len(picks) != 1 {
+					t.Errorf("expected past picks to be kept, got %+v", picks)
+				}
+			}},
+		{name: "group member cannot turn off problem of the day", route: "/lists/{id}/daily/settings", method: "DELETE", path: "/lists/{groupList}/daily/settings", user: alice, status: 403, code: "forbidden"},
+		{name: "turn off problem of the day not turned on", route: "/lists/{id}/daily/settings", method: "DELETE", path: "/lists/{aliceList}/daily/settings", user: alice, status: 404, code: "not_found"},
+		{name: "mark problem of the day done", route: "/lists/{id}/daily/{date}/completion", method: "PUT", path: "/lists/{groupList}/daily/{yesterday}/completion", user: alice, status: 200,
+			body: `{"completed":true}`,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var pick database.DailyProblem
+				decode(t, rec, &pick)
+				if pick.ProblemID != 2 || !pick.Done || pick.DoneAt == nil {
+					t.Fatalf("expected yesterday's pick to be done, got %+v", pick)
+				}
+				picks, err := f.db.GetDailyProblems(context.Background(), groupListID(f), bob, pick.Date, pick.Date)
+				must(t, err)
+				if picks[0].Done {
+					t.Errorf("expected done to be tracked per user, got %+v", picks[0])
+				}
+			}},
+		{name: "mark problem of the day done before it is picked", route: "/lists/{id}/daily/{date}/completion", method: "PUT", path: "/lists/{groupList}/daily/{today}/completion", user: alice, status: 404, code: "not_found",
+			body: `{"completed":true}`},
+		{name: "mark problem of the day done invalid date", route: "/lists/{id}/daily/{date}/completion", method: "PUT", path: "/lists/{groupList}/daily/yesterday/completion", user: alice, status: 400, code: "bad_request",
+			body: `{"completed":true}`},
+		{name: "mark problem of the day done without completed", route: "/lists/{id}/daily/{date}/completion", method: "PUT", path: "/lists/{groupList}/daily/{yesterday}/completion", user: alice, status: 422, code: "validation_failed",
+			body: `{}`},
+		{name: "recommendations", route: "/recommendations", method: "GET", path: "/recommendations", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var result recommendations
+				decode(t, rec, &result)
+				if result.Level != "Easy" || len(result.Recommendations) != 3 || result.Recommendations[0].ProblemID != 1 {
+					t.Fatalf("expected Two Sum first, got %+v", result)
+				}
+				if r := result.Recommendations[0]; len(r.Reasons) != 3 || r.Reasons[0].Code != "on_your_list" || r.Score != 3.5 {
+					t.Errorf("expected Two Sum to be picked for being on alice's list, got %+v", r)
+				}
+			}},
+		{name: "recommendations leave out solved problems", route: "/recommendations", method: "GET", path: "/recommendations", user: alice, status: 200,
+			setup: func(f *fixture) {
+				lists, err := f.db.GetUserLists(context.Background(), alice)
+				if err != nil {
+					panic(err)
+				}
+				items, err := f.db.GetListItems(context.Background(), lists[0].ID, alice)
+				if err == nil {
+					err = f.db.UpdateProblemCompletionStatus(context.Background(), items[0].ID, alice, true)
+				}
+				if err != nil {
+					panic(err)
+				}
+			},
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var result recommendations
+				decode(t, rec, &result)
+				if len(result.Recommendations) != 2 || result.Recommendations[0].ProblemID != 2 || result.Recommendations[1].ProblemID != 3 {
+					t.Errorf("expected problems 2 and 3, got %+v", result.Recommendations)
+				}
+			}},
+		{name: "recommendations leave out premium problems", route: "/recommendations", method: "GET", path: "/recommendations", user: bob, status: 200,
+			setup: addPremiumProblem,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var result recommendations
+				decode(t, rec, &result)
+				for _, r := range result.Recommendations {
+					if r.ProblemID == 4 {
+						t.Errorf("expected no premium problems, got %+v", result.Recommendations)
+					}
+				}
+			}},
+		{name: "recommendations for premium users", route: "/recommendations", method: "GET", path: "/recommendations?limit=3", user: alice, status: 200,
+			setup: func(f *fixture) {
+				addPremiumProblem(f)
+				giveAlicePremium(f)
+			},
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var result recommendations
+				decode(t, rec, &result)
+				// Problem 2 is on the group list, which puts it ahead.
+				if len(result.Recommendations) != 3 || result.Recommendations[2].ProblemID != 4 {
+					t.Errorf("expected the premium problem third, got %+v", result.Recommendations)
+				}
+			}},
+		{name: "recommendations invalid limit", route: "/recommendations", method: "GET", path: "/recommendations?limit=0", user: alice, status: 422, code: "validation_failed"},
+		{name: "recommendations unauthenticated", route: "/recommendations", method: "GET", path: "/recommendations", status: 401, code: "unauthorized"},
+		{name: "get account", route: "/me/account", method: "GET", path: "/me/account", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var account struct {
+					LeetCodeUsername   *string `json:"leetcode_username"`
+					ProgressVisibility string  `json:"progress_visibility"`
+				}
+				decode(t, rec, &account)
+				if account.LeetCodeUsername == nil || *account.LeetCodeUsername != "alice" || account.ProgressVisibility != "private" {
+					t.Errorf("unexpected account %+v", account)
+				}
+			}},
+		{name: "get account for new user", route: "/me/account", method: "GET", path: "/me/account", user: admin, status: 200},
+		{name: "get account unauthenticated", route: "/me/account", method: "GET", path: "/me/account", status: 401, code: "unauthorized"},
+		{name: "link username claimed by another account", route: "/me/account", method: "PUT", path: "/me/account", user: admin, status: 409, code: "conflict",
+			body: `{"leetcode_username":"alice","progress_visibility":"public"}`},
+		{name: "relink with different case", route: "/me/account", method: "PUT", path: "/me/account", user: alice, status: 200,
+			body: `{"leetcode_username":"ALICE","progress_visibility":"Public"}`,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				account, err := f.db.GetAccountByLeetCodeUsername(context.Background(), "alice")
+				if err != nil || account.UserID != alice || account.ProgressVisibility != database.VisibilityPublic {
+					t.Errorf("expected LeetCode's capitalisation to be stored, got %+v (%v)", account, err)
+				}
+			}},
+		{name: "set leetcode premium", route: "/me/account", method: "PUT", path: "/me/account", user: alice, status: 200,
+			body: `{"leetcode_username":"alice","progress_visibility":"private","leetcode_premium":true}`,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				account, err := f.db.GetAccount(context.Background(), alice)
+				if err != nil || !account.LeetCodePremium {
+					t.Errorf("expected alice to have premium, got %+v (%v)", account, err)
+				}
+			}},
+		{name: "update account keeps leetcode premium", route: "/me/account", method: "PUT", path: "/me/account", user: alice, status: 200,
+			body: `{"leetcode_username":"alice","progress_visibility":"team"}`, setup: giveAlicePremium,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				account, err := f.db.GetAccount(context.Background(), alice)
+				if err != nil || !account.LeetCodePremium || account.ProgressVisibility != database.VisibilityTeam {
+					t.Errorf("expected alice to keep premium, got %+v (%v)", account, err)
+				}
+			}},
+		{name: "link unknown leetcode username", route: "/me/account", method: "PUT", path: "/me/account", user: admin, status: 404, code: "not_found",
+			body: `{"leetcode_username":"nobody","progress_visibility":"private"}`},
+		{name: "unlink leetcode username", route: "/me/account", method: "PUT", path: "/me/account", user: bob, status: 200,
+			body: `{"leetcode_username":"","progress_visibility":"private"}`,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				if _, err := f.db.GetAccountByLeetCodeUsername(context.Background(), "bob"); !errors.Is(err, database.ErrNotFound) {
+					t.Errorf("expected bob's username to be unlinked, got %v", err)
+				}
+			}},
+		{name: "update account invalid", route: "/me/account", method: "PUT", path: "/me/account", user: alice, status: 422, code: "validation_failed",
+			body: `{"leetcode_username":"not a name","progress_visibility":"friends"}`},
+		{name: "update account malformed", route: "/me/account", method: "PUT", path: "/me/account", user: alice, status: 400, code: "bad_request",
+			body: `{`},
+		{name: "update account unauthenticated", route: "/me/account", method: "PUT", path: "/me/account", status: 401, code: "unauthorized",
+			body: `{"progress_visibility":"public"}`},
+
+		{name: "refresh progress", route: "/me/progress/refresh", method: "POST", path: "/me/progress/refresh", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var counts leetcode.SolvedCounts
+				decode(t, rec, &counts)
+				history, err := f.db.GetUserProgressHistory(context.Background(), "alice")
+				if counts.Total != 6 || err != nil || len(history) != 1 || history[0].TotalSolved != 6 {
+					t.Errorf("expected a fresh snapshot, got %+v and %+v (%v)", counts, history, err)
+				}
+				runs, err := f.db.GetPollRuns(context.Background(), 1)
+				if err != nil || len(runs) != 1 || runs[0].Kind != database.PollManual || runs[0].Polled != 1 {
+					t.Errorf("expected the refresh in the run log, got %+v (%v)", runs, err)
+				}
+			}},
+		{name: "refresh progress unlinked", route: "/me/progress/refresh", method: "POST", path: "/me/progress/refresh", user: admin, status: 404, code: "not_found"},
+		{name: "refresh progress unknown to leetcode", route: "/me/progress/refresh", method: "POST", path: "/me/progress/refresh", user: bob, status: 404, code: "not_found"},
+		{name: "refresh progress unauthenticated", route: "/me/progress/refresh", method: "POST", path: "/me/progress/refresh", status: 401, code: "unauthorized"},
+
+		{name: "import submissions dry run", route: "/me/submissions/import", method: "POST", path: "/me/submissions/import?dry_run=true", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				expectImport(t, rec, 3, 2, 1)
+				if item := aliceItem(t, f); item.Completed {
+					t.Errorf("expected a dry run to leave the item alone, got %+v", item)
+				}
+			}},
+		{name: "import submissions", route: "/me/submissions/import", method: "POST", path: "/me/submissions/import", user: alice, status: 200,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				expectImport(t, rec, 3, 2, 1)
+				item := aliceItem(t, f)
+				if !item.Completed || item.CompletedAt == nil || !item.CompletedAt.Equal(time.Unix(1700000000, 0)) {
+					t.Errorf("expected Two Sum completed at the first solve, got %+v", item)
+				}
+			}},
+		{name: "import submissions bad dry run", route: "/me/submissions/import", method: "POST", path: "/me/submissions/import?dry_run=maybe", user: alice, status: 400, code: "bad_request"},
+		{name: "import submissions unlinked", route: "/me/submissions/import", method: "POST", path: "/me/submissions/import", user: admin, status: 404, code: "not_found"},
+		{name: "import submissions unknown to leetcode", route: "/me/submissions/import", method: "POST", path: "/me/submissions/import", user: bob, status: 404, code: "not_found"},
+		{name: "import submissions unauthenticated", route: "/me/submissions/import", method: "POST", path: "/me/submissions/import", status: 401, code: "unauthorized"},
+
+		{name: "poll runs", route: "/admin/poll-runs", method: "GET", path: "/admin/poll-runs", user: admin, status: 200},
+		{name: "poll runs non-admin", route: "/admin/poll-runs", method: "GET", path: "/admin/poll-runs", user: alice, status: 403, code: "forbidden"},
+		{name: "poll runs unauthenticated", route: "/admin/poll-runs", method: "GET", path: "/admin/poll-runs", status: 401, code: "unauthorized"},
+		{name: "trigger poll", route: "/admin/poll-runs", method: "POST", path: "/admin/poll-runs", user: admin, status: 202,
+			check: func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+				var started database.PollRun
+				decode(t, rec, &started)
+				run := waitForPollRun(t, f, started.ID)
+				// alice is known to the fake LeetCode; bob is not.
+				if run.Polled != 1 || run.Failed != 1 || !strings.Contains(run.Error, "bob") {
+					t.Errorf("unexpected run outcome %+v", run)
+				}
+			}},
+		{name: "trigger poll non-admin", route: "/admin/poll-runs", method: "POST", path: "/admin/poll-runs", user: alice, status: 403, code: "forbidden"},
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	for _, tc := range routeCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture(t)
+			if tc.setup != nil {
+				tc.setup(f)
+			}
+
+			req := httptest.NewRequest(tc.method, f.ids.Replace(tc.path), strings.NewReader(f.ids.Replace(tc.body)))
+			if tc.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			if tc.user != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.user)
+			}
+			rec := httptest.NewRecorder()
+			f.handler.ServeHTTP(rec, req)
+
+			if rec.Code != tc.status {
+				t.Fatalf("expected status %d; got %d: %s", tc.status, rec.Code, rec.Body.String())
+			}
+			if tc.code != "" {
+				var body struct {
+					Error struct {
+						Code string `json:"code"`
+					} `json:"error"`
+				}
+				decode(t, rec, &body)
+				if body.Error.Code != tc.code {
+					t.Errorf("expected error code %q; got %q", tc.code, body.Error.Code)
+				}
+			}
+			if tc.check != nil {
+				tc.check(t, f, rec)
+			}
+		})
+	}
+}
+
+// waitForPollRun waits for a run started in the background to finish.
+func waitForPollRun(t *testing.T, f *fixture, id int) database.PollRun {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		runs, err := f.db.GetPollRuns(context.Background(), 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, run := range runs {
+			if run.ID == id && run.FinishedAt != nil {
+				return run
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("poll run %d did not finish", id)
+	return database.PollRun{}
+}
+
+// expectHistory checks that a progress history response has n buckets.
+func expectHistory(n int) func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+	return func(t *testing.T, f *fixture, rec *httptest.ResponseRecorder) {
+		var history []progress.Bucket
+		decode(t, rec, &history)
+		if len(history) != n {
+			t.Errorf("expected %d history entries, got %+v", n, history)
+		}
+	}
+}
+
+// TestRoutesAreAllCovered fails when a route is registered without a case in
+// routeCases, so new endpoints cannot skip the suite.
+func TestRoutesAreAllCovered(t *testing.T) {
+	covered := make(map[string]bool)
+	for _, tc := range routeCases() {
+		covered[tc.method+" "+tc.route] = true
+	}
+
+	r := mux.NewRouter()
+	srv := server.New(testConfig(), server.Dependencies{
+		DB:            database.NewMemory(),
+		Problems:      &fakeProblems{},
+		Cache:         cache.NewMemory(),
+		Authenticator: headerAuthenticator{},
+	})
+	server.RegisterRoutes(r, srv)
+	err := r.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		tmpl, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			// Static file routes accept any method and are not part of the API.
+			return nil
+		}
+		for _, method := range methods {
+			if !covered[method+" "+tmpl] {
+				t.Errorf("route %s %s has no test case", method, tmpl)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder, dst interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(rec.Body.Bytes(), dst); err != nil {
+		t.Fatalf("error decoding response body %q. Err: %v", rec.Body.String(), err)
+	}
+}
@@ -151,6 +151,17 @@ const AddProblems = () => {
         header: 'Acceptance Rate',
         Cell: ({ cell }) => `${(cell.getValue()).toFixed(1)}%`,
       },
+      {
+        accessorFn: (row) => row.community?.avg_difficulty ?? null,
+        id: 'communityDifficulty',
+        header: 'Community Difficulty',
+        Cell: ({ row }) => {
+          const community = row.original.community;
+          if (!community) return '—';
+          return `${community.avg_difficulty.toFixed(1)} / 5 (${community.ratings})`;
+        },
+        enableColumnFilter: false,
+      },
       {
         accessorKey: 'url',
         header: 'LeetCode Link',
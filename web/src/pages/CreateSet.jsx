@@ -59,7 +59,14 @@ const CreateSet = ({ auth0 }) => {
           'Content-Type': 'application/json',
           'Authorization': `Bearer ${token}`,
         },
-        body: JSON.stringify(form.values),
+        body: JSON.stringify({
+          name: form.values.name,
+          description: form.values.description,
+          tags: form.values.tags,
+          difficulty: form.values.difficulty,
+          estimated_time: form.values.estimatedTime,
+          notes: form.values.notes,
+        }),
       });
       if (!response.ok) {
         throw new Error('Failed to create list');
@@ -1,28 +1,75 @@
-import React, { useState, useEffect } from 'react';
-import { TextInput, Button, Paper, Title, Text, Group, Stack, Grid, Loader } from '@mantine/core';
+import React, { useState, useEffect, useCallback } from 'react';
+import { useAuth0 } from "@auth0/auth0-react";
+import { TextInput, Button, Paper, Title, Text, Group, Stack, Grid, Loader, Select } from '@mantine/core';
 import { LineChart, Line, PieChart, Pie, ResponsiveContainer, XAxis, YAxis, Tooltip, Cell, Legend, CartesianGrid } from 'recharts';
 
 const BACKEND_API_ENDPOINT = 'http://localhost:8080/leetcode-stats';
 const HISTORY_API_ENDPOINT = 'http://localhost:8080/user-progress-history';
-const STORAGE_KEY = 'leetcode_username';
+const ACCOUNT_API_ENDPOINT = 'http://localhost:8080/me/account';
+const VISIBILITY_OPTIONS = [
+  { value: 'private', label: 'Only me' },
+  { value: 'team', label: 'Signed-in users' },
+  { value: 'public', label: 'Everyone' },
+];
+const GRANULARITY_OPTIONS = [
+  { value: 'day', label: 'Daily' },
+  { value: 'week', label: 'Weekly' },
+  { value: 'month', label: 'Monthly' },
+];
 const COLORS = ['#8ce99a', '#ffe066', '#ffa8a8'];
 
 const Progress = () => {
+  const { getAccessTokenSilently } = useAuth0();
   const [username, setUsername] = useState('');
   const [savedUsername, setSavedUsername] = useState('');
+  const [visibility, setVisibility] = useState('private');
   const [stats, setStats] = useState(null);
   const [progressHistory, setProgressHistory] = useState([]);
+  const [granularity, setGranularity] = useState('day');
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState('');
 
-  useEffect(() => {
-    const storedUsername = localStorage.getItem(STORAGE_KEY);
-    if (storedUsername) {
-      setSavedUsername(storedUsername);
-      setUsername(storedUsername);
-      fetchLeetCodeStats(storedUsername);
+  const saveAccount = useCallback(async (leetcodeUsername, progressVisibility) => {
+    const token = await getAccessTokenSilently();
+    const response = await fetch(ACCOUNT_API_ENDPOINT, {
+      method: 'PUT',
+      headers: {
+        'Content-Type': 'application/json',
+        Authorization: `Bearer ${token}`,
+      },
+      body: JSON.stringify({ leetcode_username: leetcodeUsername, progress_visibility: progressVisibility }),
+    });
+    const data = await response.json();
+    if (!response.ok) {
+      throw new Error(data.error?.message || `HTTP error! status: ${response.status}`);
     }
-  }, []);
+    return data;
+  }, [getAccessTokenSilently]);
+
+  useEffect(() => {
+    const loadAccount = async () => {
+      try {
+        const token = await getAccessTokenSilently();
+        const response = await fetch(ACCOUNT_API_ENDPOINT, {
+          headers: { Authorization: `Bearer ${token}` },
+        });
+        if (!response.ok) {
+          throw new Error(`HTTP error! status: ${response.status}`);
+        }
+        const account = await response.json();
+        setVisibility(account.progress_visibility);
+        if (account.leetcode_username) {
+          setSavedUsername(account.leetcode_username);
+          setUsername(account.leetcode_username);
+          fetchLeetCodeStats(account.leetcode_username);
+        }
+      } catch (error) {
+        console.error('Error loading account:', error);
+        setError(`Failed to load account: ${error.message}`);
+      }
+    };
+    loadAccount();
+  }, [getAccessTokenSilently]);
 
   const fetchLeetCodeStats = async (usernameToFetch) => {
     if (!usernameToFetch) {
@@ -33,67 +80,33 @@ const Progress = () => {
     setLoading(true);
     setError('');
     try {
-      const query = `
-        query getUserProfile($username: String!) {
-          matchedUser(username: $username) {
-            username
-            submitStats: submitStatsGlobal {
-              acSubmissionNum {
-                difficulty
-                count
-                submissions
-              }
-            }
-            profile {
-              ranking
-            }
-          }
-        }
-      `;
-
       const response = await fetch(BACKEND_API_ENDPOINT, {
         method: 'POST',
         headers: {
           'Content-Type': 'application/json',
         },
-        body: JSON.stringify({
-          query,
-          variables: { username: usernameToFetch },
-        }),
+        body: JSON.stringify({ username: usernameToFetch }),
       });
 
+      if (response.status === 404) {
+        throw new Error('User not found');
+      }
       if (!response.ok) {
         throw new Error(`HTTP error! status: ${response.status}`);
       }
 
       const data = await response.json();
 
-      if (data.errors) {
-        throw new Error(data.errors[0].message);
-      }
-
-      if (!data.data || !data.data.matchedUser) {
-        throw new Error('User not found or invalid response structure');
-      }
-
-      const userData = data.data.matchedUser;
-      const submitStats = userData.submitStats.acSubmissionNum;
-
       setStats({
-        totalSolved: submitStats.find(stat => stat.difficulty === 'All').count,
-        easySolved: submitStats.find(stat => stat.difficulty === 'Easy').count,
-        mediumSolved: submitStats.find(stat => stat.difficulty === 'Medium').count,
-        hardSolved: submitStats.find(stat => stat.difficulty === 'Hard').count,
-        acceptanceRate: ((submitStats.find(stat => stat.difficulty === 'All').count / submitStats.find(stat => stat.difficulty === 'All').submissions) * 100).toFixed(2),
-        ranking: userData.profile.ranking,
+        totalSolved: data.totalSolved,
+        easySolved: data.easySolved,
+        mediumSolved: data.mediumSolved,
+        hardSolved: data.hardSolved,
+        acceptanceRate: data.totalSubmissions ? ((data.totalSolved / data.totalSubmissions) * 100).toFixed(2) : '0.00',
+        ranking: data.ranking,
       });
 
-      const historyResponse = await fetch(`${HISTORY_API_ENDPOINT}?username=${usernameToFetch}`);
-      if (!historyResponse.ok) {
-        throw new Error(`HTTP error! status: ${historyResponse.status}`);
-      }
-      const historyData = await historyResponse.json();
-      setProgressHistory(historyData || []);
+      await fetchHistory(granularity);
 
     } catch (error) {
       console.error('Error fetching LeetCode stats:', error);
@@ -104,19 +117,62 @@ const Progress = () => {
     }
   };
 
-  const handleSaveUsername = () => {
-    if (username) {
-      localStorage.setItem(STORAGE_KEY, username);
-      setSavedUsername(username);
-      fetchLeetCodeStats(username);
+  const fetchHistory = useCallback(async (bucketSize) => {
+    const token = await getAccessTokenSilently();
+    const historyResponse = await fetch(`${HISTORY_API_ENDPOINT}?granularity=${bucketSize}`, {
+      headers: { Authorization: `Bearer ${token}` },
+    });
+    if (!historyResponse.ok) {
+      throw new Error(`HTTP error! status: ${historyResponse.status}`);
+    }
+    const historyData = await historyResponse.json();
+    setProgressHistory(historyData || []);
+  }, [getAccessTokenSilently]);
+
+  const handleGranularityChange = async (value) => {
+    setGranularity(value);
+    try {
+      await fetchHistory(value);
+    } catch (error) {
+      console.error('Error fetching progress history:', error);
+      setError(`Failed to fetch progress history: ${error.message}`);
+    }
+  };
+
+  const handleSaveUsername = async () => {
+    if (!username) {
+      return;
+    }
+    setError('');
+    try {
+      const account = await saveAccount(username, visibility);
+      setSavedUsername(account.leetcode_username);
+      fetchLeetCodeStats(account.leetcode_username);
+    } catch (error) {
+      setError(`Failed to link username: ${error.message}`);
     }
   };
 
-  const handleChangeUsername = () => {
-    setSavedUsername('');
-    localStorage.removeItem(STORAGE_KEY);
-    setStats(null);
-    setProgressHistory([]);
+  const handleChangeUsername = async () => {
+    setError('');
+    try {
+      await saveAccount('', visibility);
+      setSavedUsername('');
+      setStats(null);
+      setProgressHistory([]);
+    } catch (error) {
+      setError(`Failed to unlink username: ${error.message}`);
+    }
+  };
+
+  const handleVisibilityChange = async (value) => {
+    setError('');
+    try {
+      const account = await saveAccount(savedUsername, value);
+      setVisibility(account.progress_visibility);
+    } catch (error) {
+      setError(`Failed to update visibility: ${error.message}`);
+    }
   };
 
   const difficultyData = stats ? [
@@ -145,9 +201,18 @@ const Progress = () => {
         ) : (
           <Group position="apart">
             <Text>Tracking progress for: <strong>{savedUsername}</strong></Text>
-            <Button onClick={handleChangeUsername} variant="subtle">
-              Change Username
-            </Button>
+            <Group>
+              <Select
+                aria-label="Who can see my progress"
+                data={VISIBILITY_OPTIONS}
+                value={visibility}
+                onChange={handleVisibilityChange}
+                allowDeselect={false}
+              />
+              <Button onClick={handleChangeUsername} variant="subtle">
+                Change Username
+              </Button>
+            </Group>
           </Group>
         )}
       </Paper>
@@ -206,7 +271,15 @@ const Progress = () => {
 
           {progressHistory && progressHistory.length > 0 && (
             <Paper shadow="xs" p="md">
-              <Title order={3} mb="md">Problem Solving Progress</Title>
+              <Group justify="space-between" mb="md">
+                <Title order={3}>Problem Solving Progress</Title>
+                <Select
+                  data={GRANULARITY_OPTIONS}
+                  value={granularity}
+                  onChange={handleGranularityChange}
+                  allowDeselect={false}
+                />
+              </Group>
               <ResponsiveContainer width="100%" height={300}>
                 <LineChart data={progressHistory}>
                   <XAxis dataKey="date" />
@@ -77,6 +77,25 @@ const SetProblems = () => {
     }
   };
 
+  const handleImportSolved = async () => {
+    try {
+      const token = await getAccessTokenSilently();
+      const response = await fetch(`http://localhost:8080/me/submissions/import`, {
+        method: 'POST',
+        headers: {
+          Authorization: `Bearer ${token}`,
+        },
+      });
+      if (!response.ok) {
+        throw new Error('Failed to import solved problems');
+      }
+      fetchProblems();
+    } catch (error) {
+      console.error('Error importing solved problems:', error);
+      setError('Failed to import solved problems. Link your LeetCode username on the Progress page and try again.');
+    }
+  };
+
   const handleDeleteProblem = async (problemId) => {
     try {
       const token = await getAccessTokenSilently();
@@ -216,6 +235,10 @@ const SetProblems = () => {
             >
               Back to Sets
             </Button>
+          <Group>
+            <Button onClick={handleImportSolved} variant="light" color="green">
+              Import solved from LeetCode
+            </Button>
           <UnstyledButton
             onClick={() => navigate(`/sets/${setId}/add-problems`)}
             mt="sm"
@@ -227,6 +250,7 @@ const SetProblems = () => {
             </ThemeIcon>
           </Group>
           </UnstyledButton>
+          </Group>
           </Flex>
           <Table
             captionSide="top"